@@ -246,13 +246,15 @@ func (c *ApiController) Signup() {
 // @Tag Login API
 // @Description logout the current user
 // @Param   id_token_hint   query        string  false        "id_token_hint"
-// @Param   post_logout_redirect_uri    query    string  false     "post_logout_redirect_uri"
+// @Param   client_id   query        string  false        "client_id, required when post_logout_redirect_uri is used without id_token_hint"
+// @Param   post_logout_redirect_uri    query    string  false     "post_logout_redirect_uri, required when id_token_hint or client_id is used"
 // @Param   state     query    string  false     "state"
 // @Success 200 {object} controllers.Response The Response object
 // @router /logout [post]
 func (c *ApiController) Logout() {
 	// https://openid.net/specs/openid-connect-rpinitiated-1_0-final.html
 	accessToken := c.Input().Get("id_token_hint")
+	clientId := c.Input().Get("client_id")
 	redirectUri := c.Input().Get("post_logout_redirect_uri")
 	state := c.Input().Get("state")
 
@@ -265,9 +267,7 @@ func (c *ApiController) Logout() {
 			return
 		}
 
-		c.ClearUserSession()
-		owner, username := util.GetOwnerAndNameFromId(user)
-		_, err := object.DeleteSessionId(util.GetSessionId(owner, username, object.CasdoorApplication), c.Ctx.Input.CruSession.SessionID())
+		frontchannelUrls, err := c.logoutUser(user)
 		if err != nil {
 			c.ResponseError(err.Error())
 			return
@@ -275,73 +275,160 @@ func (c *ApiController) Logout() {
 
 		util.LogInfo(c.Ctx, "API: [%s] logged out", user)
 
+		redirectUrl := ""
 		application := c.GetSessionApplication()
-		if application == nil || application.Name == "app-built-in" || application.HomepageUrl == "" {
+		if application != nil && application.Name != "app-built-in" {
+			redirectUrl = application.HomepageUrl
+		}
+
+		// the front-channel logout urls are loaded by a page of the backend, which then redirects to the home page
+		if len(frontchannelUrls) != 0 {
+			redirectUrl = object.GetFrontchannelLogoutPageUrl(frontchannelUrls, redirectUrl, c.Ctx.Request.Host)
+		}
+
+		if redirectUrl == "" {
 			c.ResponseOk(user)
 			return
 		}
-		c.ResponseOk(user, application.HomepageUrl)
+		c.ResponseOk(user, redirectUrl)
 		return
 	} else {
-		// "post_logout_redirect_uri" has been made optional, see: https://github.com/casdoor/casdoor/issues/2151
-		// if redirectUri == "" {
-		// 	c.ResponseError(c.T("general:Missing parameter") + ": post_logout_redirect_uri")
-		// 	return
-		// }
-		if accessToken == "" {
-			c.ResponseError(c.T("general:Missing parameter") + ": id_token_hint")
-			return
-		}
+		var application *object.Application
+		if accessToken != "" {
+			token, err := object.GetTokenByAccessToken(accessToken)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+			if token == nil {
+				c.ResponseError(c.T("token:Token not found, invalid accessToken"))
+				return
+			}
 
-		_, application, token, err := object.ExpireTokenByAccessToken(accessToken)
-		if err != nil {
-			c.ResponseError(err.Error())
-			return
+			application, err = object.GetApplication(util.GetId(token.Owner, token.Application))
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+			if application == nil {
+				c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist"), token.Application))
+				return
+			}
+
+			if clientId != "" && clientId != application.ClientId {
+				c.ResponseError(c.T("token:Invalid client_id"))
+				return
+			}
+
+			// only the signed-in user of the hint is logged out, the hint of another user just expires the token
+			if user != util.GetId(token.Organization, token.User) {
+				user = ""
+			}
+		} else {
+			// without "id_token_hint", the application of "post_logout_redirect_uri" is identified by "client_id"
+			if clientId == "" {
+				c.ResponseError(c.T("general:Missing parameter") + ": id_token_hint")
+				return
+			}
+
+			var err error
+			application, err = object.GetApplicationByClientId(clientId)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+			if application == nil {
+				c.ResponseError(c.T("token:Invalid client_id"))
+				return
+			}
 		}
-		if token == nil {
-			c.ResponseError(c.T("token:Token not found, invalid accessToken"))
+
+		// "post_logout_redirect_uri" is optional, and is validated before the user is logged out,
+		// so that an invalid request has no side effect
+		if redirectUri != "" && !application.IsRedirectUriValid(redirectUri) {
+			c.ResponseError(fmt.Sprintf(c.T("token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), redirectUri))
 			return
 		}
-		if application == nil {
-			c.ResponseError(fmt.Sprintf(c.T("auth:The application: %s does not exist")), token.Application)
-			return
+
+		if accessToken != "" {
+			_, _, _, err := object.ExpireTokenByAccessToken(accessToken)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
 		}
 
-		if user == "" {
-			user = util.GetId(token.Organization, token.User)
+		var frontchannelUrls []string
+		if user != "" {
+			var err error
+			frontchannelUrls, err = c.logoutUser(user)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+
+			util.LogInfo(c.Ctx, "API: [%s] logged out", user)
 		}
 
-		c.ClearUserSession()
-		// TODO https://github.com/casdoor/casdoor/pull/1494#discussion_r1095675265
-		owner, username := util.GetOwnerAndNameFromId(user)
+		redirectUrl := redirectUri
+		if redirectUri != "" && state != "" {
+			if strings.Contains(redirectUri, "?") {
+				redirectUrl = fmt.Sprintf("%s&state=%s", strings.TrimSuffix(redirectUri, "/"), state)
+			} else {
+				redirectUrl = fmt.Sprintf("%s?state=%s", strings.TrimSuffix(redirectUri, "/"), state)
+			}
+		}
 
-		_, err = object.DeleteSessionId(util.GetSessionId(owner, username, object.CasdoorApplication), c.Ctx.Input.CruSession.SessionID())
-		if err != nil {
-			c.ResponseError(err.Error())
+		if len(frontchannelUrls) != 0 {
+			c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+			err := c.Ctx.Output.Body([]byte(object.GetFrontchannelLogoutHtml(frontchannelUrls, redirectUrl)))
+			if err != nil {
+				c.ResponseError(err.Error())
+			}
 			return
 		}
 
-		util.LogInfo(c.Ctx, "API: [%s] logged out", user)
-
-		if redirectUri == "" {
+		if redirectUrl == "" {
 			c.ResponseOk()
 			return
-		} else {
-			if application.IsRedirectUriValid(redirectUri) {
-				redirectUrl := redirectUri
-				if state != "" {
-					if strings.Contains(redirectUri, "?") {
-						redirectUrl = fmt.Sprintf("%s&state=%s", strings.TrimSuffix(redirectUri, "/"), state)
-					} else {
-						redirectUrl = fmt.Sprintf("%s?state=%s", strings.TrimSuffix(redirectUri, "/"), state)
-					}
-				}
-				c.Ctx.Redirect(http.StatusFound, redirectUrl)
-			} else {
-				c.ResponseError(fmt.Sprintf(c.T("token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), redirectUri))
-				return
-			}
 		}
+		c.Ctx.Redirect(http.StatusFound, redirectUrl)
+	}
+}
+
+// logoutUser clears the Casdoor session of the user, and logs the user out of the other applications
+// by back-channel logout. The front-channel logout urls are returned to be loaded by the browser.
+func (c *ApiController) logoutUser(user string) ([]string, error) {
+	c.ClearUserSession()
+	owner, username := util.GetOwnerAndNameFromId(user)
+	_, err := object.DeleteSessionId(util.GetSessionId(owner, username, object.CasdoorApplication), c.Ctx.Input.CruSession.SessionID())
+	if err != nil {
+		return nil, err
+	}
+
+	return object.LogoutApplicationSessions(user, c.Ctx.Input.CruSession.SessionID(), c.Ctx.Request.Host)
+}
+
+// FrontchannelLogout
+// @Title FrontchannelLogout
+// @Tag Login API
+// @Description load the front-channel logout urls of the applications and redirect
+// @Param   id     query    string  true        "The id of the front-channel logout"
+// @Success 200 {string} string The html page
+// @router /frontchannel-logout [get]
+func (c *ApiController) FrontchannelLogout() {
+	id := c.Input().Get("id")
+
+	frontchannelLogout := object.GetFrontchannelLogout(id)
+	if frontchannelLogout == nil {
+		c.ResponseError(c.T("general:Missing parameter") + ": id")
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+	err := c.Ctx.Output.Body([]byte(object.GetFrontchannelLogoutHtml(frontchannelLogout.Urls, frontchannelLogout.RedirectUrl)))
+	if err != nil {
+		c.ResponseError(err.Error())
 	}
 }
 
@@ -120,30 +120,53 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		}
 	}
 
+	// the user signed in with an expired password is not signed in, but is allowed to change the password
+	// with the old password, and then signs in again with the new password. The password is not sent again
+	// in the MFA step, so the pending MFA session is checked as well
+	if form.Password != "" || c.getMfaUserSession() != "" {
+		isPasswordExpired, err := object.IsPasswordExpired(user)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if isPasswordExpired {
+			c.SetSession("passwordExpiredUserId", userId)
+			resp = &Response{Status: "ok", Msg: "", Data: "UpdatePassword", Data2: userId}
+			return
+		}
+	}
+
 	if form.Type == ResponseTypeLogin {
 		c.SetSessionUsername(userId)
 		util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
 		resp = &Response{Status: "ok", Msg: "", Data: userId}
 	} else if form.Type == ResponseTypeCode {
-		clientId := c.Input().Get("clientId")
-		responseType := c.Input().Get("responseType")
-		redirectUri := c.Input().Get("redirectUri")
-		scope := c.Input().Get("scope")
-		state := c.Input().Get("state")
-		nonce := c.Input().Get("nonce")
-		challengeMethod := c.Input().Get("code_challenge_method")
-		codeChallenge := c.Input().Get("code_challenge")
+		requestUri := c.Input().Get("request_uri")
+		oAuthRequest, msg, err := c.getOAuthRequest()
+		if err != nil {
+			c.ResponseError(err.Error(), nil)
+			return
+		}
+		if msg != "" {
+			c.ResponseError(msg)
+			return
+		}
 
+		challengeMethod := oAuthRequest.CodeChallengeMethod
 		if challengeMethod != "S256" && challengeMethod != "null" && challengeMethod != "" {
 			c.ResponseError(c.T("auth:Challenge method should be S256"))
 			return
 		}
-		code, err := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, c.Ctx.Request.Host, c.GetAcceptLanguage())
+		code, err := object.GetOAuthCode(userId, oAuthRequest.ClientId, oAuthRequest.ResponseType, oAuthRequest.RedirectUri, oAuthRequest.Scope, oAuthRequest.State, oAuthRequest.Nonce, oAuthRequest.CodeChallenge, requestUri, c.Ctx.Request.Host, c.GetAcceptLanguage())
 		if err != nil {
 			c.ResponseError(err.Error(), nil)
 			return
 		}
 
+		if requestUri != "" && code.Message == "" {
+			object.DeleteOAuthRequest(requestUri)
+		}
+
 		resp = codeToResponse(code)
 
 		if application.EnableSigninSession || application.HasPromptPage() {
@@ -154,9 +177,27 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		if !object.IsGrantTypeValid(form.Type, application.GrantTypes) {
 			resp = &Response{Status: "error", Msg: fmt.Sprintf("error: grant_type: %s is not supported in this application", form.Type), Data: ""}
 		} else {
-			scope := c.Input().Get("scope")
-			nonce := c.Input().Get("nonce")
-			token, _ := object.GetTokenByUser(application, user, scope, nonce, c.Ctx.Request.Host)
+			requestUri := c.Input().Get("request_uri")
+			if application.RequirePushedAuthorizationRequests && requestUri == "" {
+				c.ResponseError(c.T("token:The application requires pushed authorization requests"))
+				return
+			}
+
+			oAuthRequest, msg, err := c.getOAuthRequest()
+			if err != nil {
+				c.ResponseError(err.Error(), nil)
+				return
+			}
+			if msg != "" {
+				c.ResponseError(msg)
+				return
+			}
+
+			token, _ := object.GetTokenByUser(application, user, oAuthRequest.Scope, oAuthRequest.Nonce, c.Ctx.Request.Host)
+			if requestUri != "" && token != nil {
+				object.DeleteOAuthRequest(requestUri)
+			}
+
 			resp = tokenToResponse(token)
 		}
 	} else if form.Type == ResponseTypeSaml { // saml flow
@@ -213,6 +254,28 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 	return resp
 }
 
+// getOAuthRequest returns the parameters of the authorization request, which are resolved from
+// the pushed authorization request or the request object if "request_uri" or "request" is given
+func (c *ApiController) getOAuthRequest() (*object.OAuthRequest, string, error) {
+	clientId := c.Input().Get("clientId")
+	requestUri := c.Input().Get("request_uri")
+	request := c.Input().Get("request")
+	if requestUri == "" && request == "" {
+		return &object.OAuthRequest{
+			ClientId:            clientId,
+			ResponseType:        c.Input().Get("responseType"),
+			RedirectUri:         c.Input().Get("redirectUri"),
+			Scope:               c.Input().Get("scope"),
+			State:               c.Input().Get("state"),
+			Nonce:               c.Input().Get("nonce"),
+			CodeChallengeMethod: c.Input().Get("code_challenge_method"),
+			CodeChallenge:       c.Input().Get("code_challenge"),
+		}, "", nil
+	}
+
+	return object.GetOAuthRequest(clientId, requestUri, request, c.Ctx.Request.Host, c.GetAcceptLanguage())
+}
+
 // GetApplicationLogin ...
 // @Title GetApplicationLogin
 // @Tag Login API
@@ -222,22 +285,33 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 // @Param   redirectUri    query    string  true        "redirect uri"
 // @Param   scope    query    string  true        "scope"
 // @Param   state    query    string  true        "state"
+// @Param   request_uri    query    string  false        "the request uri returned by the PAR endpoint"
+// @Param   request    query    string  false        "the request object signed by the client"
 // @Success 200 {object} controllers.Response The Response object
 // @router /get-app-login [get]
 func (c *ApiController) GetApplicationLogin() {
-	clientId := c.Input().Get("clientId")
-	responseType := c.Input().Get("responseType")
 	redirectUri := c.Input().Get("redirectUri")
-	scope := c.Input().Get("scope")
-	state := c.Input().Get("state")
+	requestUri := c.Input().Get("request_uri")
+	request := c.Input().Get("request")
 	id := c.Input().Get("id")
 	loginType := c.Input().Get("type")
 
 	var application *object.Application
+	var oAuthRequest *object.OAuthRequest
 	var msg string
 	var err error
 	if loginType == "code" {
-		msg, application, err = object.CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, c.GetAcceptLanguage())
+		oAuthRequest, msg, err = c.getOAuthRequest()
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if msg != "" {
+			c.ResponseError(msg)
+			return
+		}
+
+		msg, application, err = object.CheckOAuthLogin(oAuthRequest.ClientId, oAuthRequest.ResponseType, oAuthRequest.RedirectUri, oAuthRequest.Scope, oAuthRequest.State, requestUri, c.GetAcceptLanguage())
 		if err != nil {
 			c.ResponseError(err.Error())
 			return
@@ -263,6 +337,9 @@ func (c *ApiController) GetApplicationLogin() {
 	application = object.GetMaskedApplication(application, "")
 	if msg != "" {
 		c.ResponseError(msg, application)
+	} else if requestUri != "" || request != "" {
+		// the login page needs the resolved parameters to redirect back to the client
+		c.ResponseOk(application, oAuthRequest)
 	} else {
 		c.ResponseOk(application)
 	}
@@ -382,8 +459,9 @@ func (c *ApiController) Login() {
 				c.ResponseError(c.T("auth:The login method: login with password is not enabled for the application"))
 				return
 			}
+			clientIp := c.getClientIp()
 			var enableCaptcha bool
-			if enableCaptcha, err = object.CheckToEnableCaptcha(application, authForm.Organization, authForm.Username); err != nil {
+			if enableCaptcha, err = object.CheckToEnableCaptcha(application, authForm.Organization, authForm.Username, clientIp); err != nil {
 				c.ResponseError(err.Error())
 				return
 			} else if enableCaptcha {
@@ -410,8 +488,15 @@ func (c *ApiController) Login() {
 				}
 			}
 
+			err = object.CheckSigninThrottle(clientIp, authForm.Organization, authForm.Username, c.GetAcceptLanguage(), enableCaptcha)
+			if err != nil {
+				c.ResponseError(err.Error())
+				return
+			}
+
 			password := authForm.Password
 			user, err = object.CheckUserPassword(authForm.Organization, authForm.Username, password, c.GetAcceptLanguage(), enableCaptcha)
+			object.RecordSigninThrottle(clientIp, authForm.Organization, authForm.Username, err == nil)
 		}
 
 		if err != nil {
@@ -957,6 +1042,8 @@ func (c *ApiController) GetCaptchaStatus() {
 	var captchaEnabled bool
 	if user != nil && user.SigninWrongTimes >= failedSigninLimit {
 		captchaEnabled = true
+	} else if object.IsSigninCaptchaRequired(c.getClientIp(), organization, userId) {
+		captchaEnabled = true
 	}
 	c.ResponseOk(captchaEnabled)
 }
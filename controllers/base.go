@@ -20,6 +20,7 @@ import (
 
 	"github.com/beego/beego"
 	"github.com/beego/beego/logs"
+	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/util"
 )
@@ -198,6 +199,12 @@ func (c *ApiController) setMfaUserSession(userId string) {
 	c.SetSession(object.MfaSessionUserId, userId)
 }
 
+// getClientIp returns the IP of the client, the x-forwarded-for header is only honored for the proxies
+// listed in "trustedProxies"
+func (c *ApiController) getClientIp() string {
+	return util.GetClientIpFromRequest(c.Ctx.Request, conf.GetConfigString("trustedProxies"))
+}
+
 func (c *ApiController) getMfaUserSession() string {
 	userId := c.Ctx.Input.CruSession.Get(object.MfaSessionUserId)
 	if userId == nil {
@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -183,3 +184,35 @@ func (c *ApiController) DeleteCert() {
 	c.Data["json"] = wrapActionResponse(object.DeleteCert(&cert))
 	c.ServeJSON()
 }
+
+// RotateCert
+// @Title RotateCert
+// @Tag Cert API
+// @Description generate the successor of the cert for key rotation
+// @Param   id     query    string  true        "The id ( owner/name ) of the cert"
+// @Param   activate     query    string  false        "Whether to activate the successor immediately instead of waiting for the rotation job"
+// @Success 200 {object} object.Cert The Response object
+// @router /rotate-cert [post]
+func (c *ApiController) RotateCert() {
+	id := c.Input().Get("id")
+	activate := c.Input().Get("activate") == "true"
+
+	cert, err := object.GetCert(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if cert == nil {
+		c.ResponseError(fmt.Sprintf(c.T("cert:The cert: %s does not exist"), id))
+		return
+	}
+
+	successor, err := object.RotateCert(cert, activate)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.GetMaskedCert(successor))
+}
@@ -211,3 +211,33 @@ func (c *ApiController) GetOrganizationNames() {
 
 	c.ResponseOk(organizationNames)
 }
+
+// GetPasswordTypeCounts
+// @Title GetPasswordTypeCounts
+// @Tag Organization API
+// @Description get how many users of the organization have their passwords hashed with each password type
+// @Param   owner     query    string    true   "The name of the organization"
+// @Success 200 {array} object.PasswordTypeCount The Response object
+// @router /get-password-type-counts [get]
+func (c *ApiController) GetPasswordTypeCounts() {
+	owner := c.Input().Get("owner")
+
+	organization, err := object.GetOrganization(util.GetId("admin", owner))
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if organization == nil {
+		c.ResponseError(c.T("check:Organization does not exist"))
+		return
+	}
+
+	counts, err := object.GetPasswordTypeCounts(organization)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(counts)
+}
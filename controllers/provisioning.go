@@ -0,0 +1,97 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetProvisioningRecords
+// @Title GetProvisioningRecords
+// @Tag Provider API
+// @Description get the sync status of the users and groups provisioned by the SCIM provider
+// @Param   owner     query    string  true        "The owner of the provider"
+// @Param   provider  query    string  true        "The name of the provider"
+// @Success 200 {array} object.ProvisioningRecord The Response object
+// @router /get-provisioning-records [get]
+func (c *ApiController) GetProvisioningRecords() {
+	owner := c.Input().Get("owner")
+	provider := c.Input().Get("provider")
+	limit := c.Input().Get("pageSize")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	count, err := object.GetProvisioningRecordCount(owner, provider, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	records, err := object.GetPaginationProvisioningRecords(owner, provider, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(records, paginator.Nums())
+}
+
+// ReconcileProvider
+// @Title ReconcileProvider
+// @Tag Provider API
+// @Description provision the users and groups that are missing or have drifted in the downstream application of the SCIM provider
+// @Param   body    body   object.Provider  true        "The provider"
+// @Success 200 {object} controllers.Response The Response object
+// @router /reconcile-provider [post]
+func (c *ApiController) ReconcileProvider() {
+	var provider object.Provider
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &provider)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	// the provider is read again, as the client secret is masked in the request
+	oldProvider, err := object.GetProvider(provider.GetId())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if oldProvider == nil {
+		c.ResponseError(fmt.Sprintf(c.T("provider:the provider: %s does not exist"), provider.GetId()))
+		return
+	}
+
+	err = object.ReconcileProvider(oldProvider)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
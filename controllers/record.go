@@ -0,0 +1,136 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/beego/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+func (c *ApiController) getRecordFilter() *object.RecordFilter {
+	return &object.RecordFilter{
+		Organization: c.Input().Get("owner"),
+		User:         c.Input().Get("user"),
+		Action:       c.Input().Get("action"),
+		ClientIp:     c.Input().Get("clientIp"),
+		StartTime:    c.Input().Get("startTime"),
+		EndTime:      c.Input().Get("endTime"),
+	}
+}
+
+// GetRecords
+// @Title GetRecords
+// @Tag Record API
+// @Description get all records
+// @Param   owner     query    string  false       "The organization of the records"
+// @Param   user      query    string  false       "The user of the records"
+// @Param   action    query    string  false       "The action of the records"
+// @Param   clientIp  query    string  false       "The client IP of the records"
+// @Param   startTime query    string  false       "The start time of the records, in RFC 3339"
+// @Param   endTime   query    string  false       "The end time of the records, in RFC 3339"
+// @Success 200 {array} object.Record The Response object
+// @router /get-records [get]
+func (c *ApiController) GetRecords() {
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	filter := c.getRecordFilter()
+
+	if limit == "" || page == "" {
+		records, err := object.GetRecords(filter)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(records)
+	} else {
+		limit := util.ParseInt(limit)
+		count, err := object.GetRecordCount(filter, field, value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		paginator := pagination.SetPaginator(c.Ctx, limit, count)
+		records, err := object.GetPaginationRecords(filter, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(records, paginator.Nums())
+	}
+}
+
+// ExportRecords
+// @Title ExportRecords
+// @Tag Record API
+// @Description export the records as a file
+// @Param   format    query    string  false       "The format of the file: jsonl (default) or csv"
+// @Param   owner     query    string  false       "The organization of the records"
+// @Param   user      query    string  false       "The user of the records"
+// @Param   action    query    string  false       "The action of the records"
+// @Param   clientIp  query    string  false       "The client IP of the records"
+// @Param   startTime query    string  false       "The start time of the records, in RFC 3339"
+// @Param   endTime   query    string  false       "The end time of the records, in RFC 3339"
+// @Success 200 {string} string The exported file
+// @router /export-records [get]
+func (c *ApiController) ExportRecords() {
+	format := c.Input().Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+
+	if format != "jsonl" && format != "csv" {
+		c.ResponseError(fmt.Sprintf(c.T("record:The format: %s is not supported"), format))
+		return
+	}
+
+	if format == "csv" {
+		c.Ctx.Output.Header("Content-Type", "text/csv")
+	} else {
+		c.Ctx.Output.Header("Content-Type", "application/jsonl")
+	}
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=records.%s", format))
+
+	// the records are streamed to the response, so an error in the middle can only be logged
+	err := object.WriteRecords(c.Ctx.ResponseWriter, c.getRecordFilter(), format)
+	if err != nil {
+		util.LogWarning(c.Ctx, "ExportRecords() error: %s", err.Error())
+	}
+}
+
+// VerifyRecords
+// @Title VerifyRecords
+// @Tag Record API
+// @Description verify the hash chain of the records to detect tampering
+// @Success 200 {object} object.RecordVerification The Response object
+// @router /verify-records [get]
+func (c *ApiController) VerifyRecords() {
+	verification, err := object.VerifyRecords()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(verification)
+}
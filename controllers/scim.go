@@ -17,11 +17,49 @@ package controllers
 import (
 	"strings"
 
+	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/scim"
 )
 
 func (c *RootController) HandleScim() {
-	path := c.Ctx.Request.URL.Path
-	c.Ctx.Request.URL.Path = strings.TrimPrefix(path, "/scim")
-	scim.Server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
+	organization, ok, err := c.getScimOrganization()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if !ok {
+		scim.HandleUnauthorized(c.Ctx.ResponseWriter, c.T("auth:Unauthorized operation"))
+		return
+	}
+
+	r := scim.WithOrganization(c.Ctx.Request, organization)
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, scim.ScimPathPrefix)
+	scim.HandleRequest(c.Ctx.ResponseWriter, r)
+}
+
+// getScimOrganization returns the organization that the SCIM request is scoped to. The external IdPs
+// authenticate with a bearer token bound to an application of the organization, and the admins signed in
+// are scoped to their organizations, except the global admins whose requests aren't scoped.
+func (c *RootController) getScimOrganization() (string, bool, error) {
+	header := c.Ctx.Request.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") || strings.HasPrefix(header, object.DpopTokenType+" ") {
+		bearerToken := strings.TrimPrefix(strings.TrimPrefix(header, "Bearer "), object.DpopTokenType+" ")
+		application, err := object.GetApplicationByScimToken(bearerToken, c.Ctx.Request)
+		if err != nil {
+			return "", false, err
+		}
+		if application == nil {
+			return "", false, nil
+		}
+		return application.Organization, true, nil
+	}
+
+	isGlobalAdmin, user := c.isGlobalAdmin()
+	if isGlobalAdmin {
+		return "", true, nil
+	}
+	if user != nil && user.IsAdmin {
+		return user.Owner, true, nil
+	}
+	return "", false, nil
 }
@@ -0,0 +1,59 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// GetSigninLockouts
+// @Title GetSigninLockouts
+// @Tag Login API
+// @Description get the sign-ins throttled by the failures from the IPs or for the users
+// @Param   owner     query    string  false        "The organization of the users, all lockouts including the IPs are returned if empty"
+// @Success 200 {array} object.SigninLockout The Response object
+// @router /get-signin-lockouts [get]
+func (c *ApiController) GetSigninLockouts() {
+	owner := c.Input().Get("owner")
+
+	lockouts, err := object.GetSigninLockouts(owner)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(lockouts)
+}
+
+// ClearSigninLockout
+// @Title ClearSigninLockout
+// @Tag Login API
+// @Description clear the failures of the throttled sign-in
+// @Param   body    body   object.SigninLockout  true        "The lockout, with the owner and the name"
+// @Success 200 {object} controllers.Response The Response object
+// @router /clear-signin-lockout [post]
+func (c *ApiController) ClearSigninLockout() {
+	var lockout object.SigninLockout
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &lockout)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.ClearSigninLockout(&lockout))
+	c.ServeJSON()
+}
@@ -169,6 +169,14 @@ func (c *ApiController) GetOAuthToken() {
 	tag := c.Input().Get("tag")
 	avatar := c.Input().Get("avatar")
 	refreshToken := c.Input().Get("refresh_token")
+	deviceCode := c.Input().Get("device_code")
+	subjectToken := c.Input().Get("subject_token")
+	subjectTokenType := c.Input().Get("subject_token_type")
+	actorToken := c.Input().Get("actor_token")
+	actorTokenType := c.Input().Get("actor_token_type")
+	audience := c.Input().Get("audience")
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
 
 	if clientId == "" && clientSecret == "" {
 		clientId, clientSecret, _ = c.Ctx.Request.BasicAuth()
@@ -212,11 +220,72 @@ func (c *ApiController) GetOAuthToken() {
 			if refreshToken == "" {
 				refreshToken = tokenRequest.RefreshToken
 			}
+			if deviceCode == "" {
+				deviceCode = tokenRequest.DeviceCode
+			}
+			if subjectToken == "" {
+				subjectToken = tokenRequest.SubjectToken
+			}
+			if subjectTokenType == "" {
+				subjectTokenType = tokenRequest.SubjectTokenType
+			}
+			if actorToken == "" {
+				actorToken = tokenRequest.ActorToken
+			}
+			if actorTokenType == "" {
+				actorTokenType = tokenRequest.ActorTokenType
+			}
+			if audience == "" {
+				audience = tokenRequest.Audience
+			}
+			if clientAssertionType == "" {
+				clientAssertionType = tokenRequest.ClientAssertionType
+			}
+			if clientAssertion == "" {
+				clientAssertion = tokenRequest.ClientAssertion
+			}
 		}
 	}
 
-	host := c.Ctx.Request.Host
-	token, err := object.GetOAuthToken(grantType, clientId, clientSecret, code, verifier, scope, username, password, host, refreshToken, tag, avatar, c.GetAcceptLanguage())
+	clientCert, err := object.GetClientCertificate(c.Ctx.Request)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	dpopJkt, tokenError := object.GetDpopJkt(c.Ctx.Request)
+	if tokenError != nil {
+		c.Data["json"] = tokenError
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	token, err := object.GetOAuthToken(&object.OAuthTokenRequest{
+		GrantType:           grantType,
+		ClientId:            clientId,
+		ClientSecret:        clientSecret,
+		Code:                code,
+		Verifier:            verifier,
+		Scope:               scope,
+		Username:            username,
+		Password:            password,
+		Host:                c.Ctx.Request.Host,
+		RefreshToken:        refreshToken,
+		DeviceCode:          deviceCode,
+		SubjectToken:        subjectToken,
+		SubjectTokenType:    subjectTokenType,
+		ActorToken:          actorToken,
+		ActorTokenType:      actorTokenType,
+		Audience:            audience,
+		ClientAssertionType: clientAssertionType,
+		ClientAssertion:     clientAssertion,
+		ClientCert:          clientCert,
+		DpopJkt:             dpopJkt,
+		Tag:                 tag,
+		Avatar:              avatar,
+		Lang:                c.GetAcceptLanguage(),
+	})
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
@@ -236,6 +305,8 @@ func (c *ApiController) GetOAuthToken() {
 // @Param   scope     query    string  true        "OAuth scope"
 // @Param   client_id     query    string  true        "OAuth client id"
 // @Param   client_secret     query    string  false        "OAuth client secret"
+// @Param   client_assertion_type     query    string  false        "OAuth client assertion type"
+// @Param   client_assertion     query    string  false        "OAuth client assertion"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @Success 400 {object} object.TokenError The Response object
 // @Success 401 {object} object.TokenError The Response object
@@ -246,9 +317,14 @@ func (c *ApiController) RefreshToken() {
 	scope := c.Input().Get("scope")
 	clientId := c.Input().Get("client_id")
 	clientSecret := c.Input().Get("client_secret")
-	host := c.Ctx.Request.Host
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
 
-	if clientId == "" {
+	if clientId == "" && clientSecret == "" {
+		clientId, clientSecret, _ = c.Ctx.Request.BasicAuth()
+	}
+
+	if clientId == "" && clientAssertion == "" {
 		// If clientID is empty, try to read data from RequestBody
 		var tokenRequest TokenRequest
 		if err := json.Unmarshal(c.Ctx.Input.RequestBody, &tokenRequest); err == nil {
@@ -257,10 +333,38 @@ func (c *ApiController) RefreshToken() {
 			grantType = tokenRequest.GrantType
 			scope = tokenRequest.Scope
 			refreshToken = tokenRequest.RefreshToken
+			clientAssertionType = tokenRequest.ClientAssertionType
+			clientAssertion = tokenRequest.ClientAssertion
 		}
 	}
 
-	refreshToken2, err := object.RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host)
+	clientCert, err := object.GetClientCertificate(c.Ctx.Request)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	dpopJkt, tokenError := object.GetDpopJkt(c.Ctx.Request)
+	if tokenError != nil {
+		c.Data["json"] = tokenError
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	refreshToken2, err := object.RefreshOAuthToken(&object.OAuthTokenRequest{
+		GrantType:           grantType,
+		ClientId:            clientId,
+		ClientSecret:        clientSecret,
+		Scope:               scope,
+		Host:                c.Ctx.Request.Host,
+		RefreshToken:        refreshToken,
+		ClientAssertionType: clientAssertionType,
+		ClientAssertion:     clientAssertion,
+		ClientCert:          clientCert,
+		DpopJkt:             dpopJkt,
+		Lang:                c.GetAcceptLanguage(),
+	})
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
@@ -277,69 +381,64 @@ func (c *ApiController) RefreshToken() {
 // parameter representing an OAuth 2.0 token and returns a JSON document
 // representing the meta information surrounding the
 // token, including whether this token is currently active.
-// This endpoint only support Basic Authorization.
+// The client is authenticated in the same way as the token endpoint.
 //
 // @Param token formData string true "access_token's value or refresh_token's value"
 // @Param token_type_hint formData string true "the token type access_token or refresh_token"
+// @Param dpop_proof formData string false "the DPoP proof presented to the resource server together with the token"
+// @Param htm formData string false "the http method of the request to the resource server, required by dpop_proof"
+// @Param htu formData string false "the http url of the request to the resource server, required by dpop_proof"
 // @Success 200 {object} object.IntrospectionResponse The Response object
 // @Success 400 {object} object.TokenError The Response object
 // @Success 401 {object} object.TokenError The Response object
 // @router /login/oauth/introspect [post]
 func (c *ApiController) IntrospectToken() {
 	tokenValue := c.Input().Get("token")
-	clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
-	if !ok {
-		clientId = c.Input().Get("client_id")
-		clientSecret = c.Input().Get("client_secret")
-		if clientId == "" || clientSecret == "" {
-			c.ResponseError(c.T("token:Empty clientId or clientSecret"))
-			c.Data["json"] = &object.TokenError{
-				Error: object.InvalidRequest,
-			}
-			c.SetTokenErrorHttpStatus()
-			c.ServeJSON()
-			return
-		}
-	}
-	application, err := object.GetApplicationByClientId(clientId)
+	application, tokenError, err := c.getAuthenticatedClient()
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
 	}
 
-	if application == nil || application.ClientSecret != clientSecret {
-		c.ResponseError(c.T("token:Invalid application or wrong clientSecret"))
-		c.Data["json"] = &object.TokenError{
-			Error: object.InvalidClient,
-		}
+	if tokenError != nil {
+		c.Data["json"] = tokenError
 		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
 		return
 	}
+
 	token, err := object.GetTokenByTokenAndApplication(tokenValue, application.Name)
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
 	}
 
-	if token == nil {
+	if token == nil || token.IsRevoked {
 		c.Data["json"] = &object.IntrospectionResponse{Active: false}
 		c.ServeJSON()
 		return
 	}
 	jwtToken, err := object.ParseJwtTokenByApplication(tokenValue, application)
 	if err != nil || jwtToken.Valid() != nil {
-		// and token revoked case. but we not implement
-		// TODO: 2022-03-03 add token revoked check, when we implemented the Token Revocation(rfc7009) Specs.
-		// refs: https://tools.ietf.org/html/rfc7009
 		c.Data["json"] = &object.IntrospectionResponse{Active: false}
 		c.ServeJSON()
 		return
 	}
 
+	// the resource server can forward the DPoP proof it received, so that the proof is checked against the bound key
+	if dpopProof := c.Input().Get("dpop_proof"); dpopProof != "" {
+		err = object.VerifyDpopProofForToken(token, dpopProof, c.Input().Get("htm"), c.Input().Get("htu"))
+		if err != nil {
+			c.Data["json"] = &object.IntrospectionResponse{Active: false}
+			c.ServeJSON()
+			return
+		}
+	}
+
 	c.Data["json"] = &object.IntrospectionResponse{
 		Active:    true,
 		Scope:     jwtToken.Scope,
-		ClientId:  clientId,
+		ClientId:  application.ClientId,
 		Username:  token.User,
 		TokenType: token.TokenType,
 		Exp:       jwtToken.ExpiresAt.Unix(),
@@ -349,6 +448,242 @@ func (c *ApiController) IntrospectToken() {
 		Aud:       jwtToken.Audience,
 		Iss:       jwtToken.Issuer,
 		Jti:       jwtToken.ID,
+		Cnf:       token.Cnf,
+	}
+	c.ServeJSON()
+}
+
+// RevokeToken
+// @Title RevokeToken
+// @Tag Token API
+// @Description revoke an access token or a refresh token (rfc 7009). This endpoint supports Basic Authorization
+// or client_id and client_secret in the form data.
+// @Param token formData string true "access_token's value or refresh_token's value"
+// @Param token_type_hint formData string false "the token type access_token or refresh_token"
+// @Success 200 {string} string The token is revoked or is invalid
+// @Success 400 {object} object.TokenError The Response object
+// @Success 401 {object} object.TokenError The Response object
+// @router /login/oauth/revoke [post]
+func (c *ApiController) RevokeToken() {
+	tokenValue := c.Input().Get("token")
+	tokenTypeHint := c.Input().Get("token_type_hint")
+	application, tokenError, err := c.getAuthenticatedClient()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if tokenError != nil {
+		c.Data["json"] = tokenError
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	if tokenValue == "" {
+		c.Data["json"] = &object.TokenError{
+			Error:            object.InvalidRequest,
+			ErrorDescription: "token should not be empty",
+		}
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	_, err = object.RevokeToken(application, tokenValue, tokenTypeHint)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	// the response is the same for revoked and invalid tokens, per rfc 7009 section 2.2
+	c.Ctx.Output.SetStatus(200)
+	c.Ctx.Output.Body([]byte(""))
+}
+
+// DeviceAuthorization
+// @Title DeviceAuthorization
+// @Tag Token API
+// @Description issue a device_code and user_code pair for the OAuth 2.0 Device Authorization Grant (rfc 8628)
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Param   scope     query    string  false        "OAuth scope"
+// @Success 200 {object} object.DeviceAuthResponse The Response object
+// @Success 400 {object} object.TokenError The Response object
+// @Success 401 {object} object.TokenError The Response object
+// @router /login/oauth/device_authorization [post]
+func (c *ApiController) DeviceAuthorization() {
+	clientId := c.Input().Get("client_id")
+	scope := c.Input().Get("scope")
+
+	if clientId == "" {
+		clientId, _, _ = c.Ctx.Request.BasicAuth()
+	}
+
+	res, err := object.GetDeviceAuthorization(clientId, scope, c.Ctx.Request.Host)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = res
+	c.SetTokenErrorHttpStatus()
+	c.ServeJSON()
+}
+
+// GetDeviceAuthApplication
+// @Title GetDeviceAuthApplication
+// @Tag Token API
+// @Description get the application that is requesting access with the user code
+// @Param   user_code     query    string  true        "The user code shown on the device"
+// @Success 200 {object} object.Application The Response object
+// @router /login/oauth/device [get]
+func (c *ApiController) GetDeviceAuthApplication() {
+	userCode := c.Input().Get("user_code")
+
+	application, err := object.GetDeviceAuthApplication(userCode)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if application == nil {
+		c.ResponseError(c.T("token:The user code is invalid or has expired"))
+		return
+	}
+
+	c.ResponseOk(application)
+}
+
+// VerifyDeviceCode
+// @Title VerifyDeviceCode
+// @Tag Token API
+// @Description approve or deny the device authorization request with the signed-in user
+// @Param   user_code     query    string  true        "The user code shown on the device"
+// @Param   approved     query    string  false        "Whether the user approves the request, default is true"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/device [post]
+func (c *ApiController) VerifyDeviceCode() {
+	user, ok := c.RequireSignedInUser()
+	if !ok {
+		return
+	}
+
+	userCode := c.Input().Get("user_code")
+	approved := c.Input().Get("approved") != "false"
+
+	affected, err := object.VerifyDeviceCode(userCode, user, approved)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if !affected {
+		c.ResponseError(c.T("token:The user code is invalid or has expired"))
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// PushAuthorizationRequest
+// @Title PushAuthorizationRequest
+// @Tag Token API
+// @Description push the authorization request and get a request_uri for it, per rfc 9126
+// @Param   client_id     formData    string  true        "OAuth client id"
+// @Param   client_secret     formData    string  true        "OAuth client secret"
+// @Param   response_type     formData    string  false        "OAuth response type"
+// @Param   redirect_uri     formData    string  false        "OAuth redirect uri"
+// @Param   scope     formData    string  false        "OAuth scope"
+// @Param   state     formData    string  false        "OAuth state"
+// @Param   request     formData    string  false        "the request object signed by the client, per rfc 9101"
+// @Success 201 {object} object.ParResponse The Response object
+// @Success 400 {object} object.TokenError The Response object
+// @Success 401 {object} object.TokenError The Response object
+// @router /login/oauth/par [post]
+func (c *ApiController) PushAuthorizationRequest() {
+	application, tokenError, err := c.getAuthenticatedClient()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if tokenError != nil {
+		c.Data["json"] = tokenError
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	// the request_uri parameter must not be pushed, per rfc 9126 section 2.1
+	if c.Input().Get("request_uri") != "" {
+		c.Data["json"] = &object.TokenError{
+			Error:            object.InvalidRequest,
+			ErrorDescription: "request_uri is not allowed in the pushed authorization request",
+		}
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
+	}
+
+	oAuthRequest := &object.OAuthRequest{
+		ResponseType:        c.Input().Get("response_type"),
+		RedirectUri:         c.Input().Get("redirect_uri"),
+		Scope:               c.Input().Get("scope"),
+		State:               c.Input().Get("state"),
+		Nonce:               c.Input().Get("nonce"),
+		CodeChallengeMethod: c.Input().Get("code_challenge_method"),
+		CodeChallenge:       c.Input().Get("code_challenge"),
+	}
+	request := c.Input().Get("request")
+
+	res, tokenError, err := object.PushAuthorizationRequest(application, oAuthRequest, request, c.Ctx.Request.Host)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if tokenError != nil {
+		c.Data["json"] = tokenError
+		c.SetTokenErrorHttpStatus()
+		c.ServeJSON()
+		return
 	}
+
+	c.Data["json"] = res
+	c.Ctx.Output.SetStatus(201)
 	c.ServeJSON()
 }
+
+// getAuthenticatedClient returns the application of the client authenticated by the client secret
+// in the basic auth or the form, or by the client assertion or the TLS client certificate
+func (c *ApiController) getAuthenticatedClient() (*object.Application, *object.TokenError, error) {
+	clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
+	if !ok {
+		clientId = c.Input().Get("client_id")
+		clientSecret = c.Input().Get("client_secret")
+	}
+
+	application, err := object.GetApplicationByClientId(clientId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if application == nil {
+		return nil, &object.TokenError{
+			Error:            object.InvalidClient,
+			ErrorDescription: "client_id is invalid",
+		}, nil
+	}
+
+	clientCert, err := object.GetClientCertificate(c.Ctx.Request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenError := object.CheckClientAuthentication(application, clientSecret, c.Input().Get("client_assertion_type"), c.Input().Get("client_assertion"), clientCert, c.Ctx.Request.Host)
+	if tokenError != nil {
+		return nil, tokenError, nil
+	}
+
+	return application, nil, nil
+}
@@ -446,7 +446,10 @@ func (c *ApiController) SetPassword() {
 	userId := util.GetId(userOwner, userName)
 
 	requestUserId := c.GetSessionUsername()
-	if requestUserId == "" && code == "" {
+	isPasswordExpired := requestUserId == "" && code == "" && c.GetSession("passwordExpiredUserId") == userId
+	if isPasswordExpired {
+		// the user whose password has expired is not signed in, and is checked by the old password below
+	} else if requestUserId == "" && code == "" {
 		c.ResponseError(c.T("general:Please login first"), "Please login first")
 		return
 	} else if code == "" {
@@ -503,6 +506,10 @@ func (c *ApiController) SetPassword() {
 		return
 	}
 
+	if isPasswordExpired {
+		c.SetSession("passwordExpiredUserId", "")
+	}
+
 	c.ResponseOk()
 }
 
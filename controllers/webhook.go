@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/beego/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -47,7 +48,7 @@ func (c *ApiController) GetWebhooks() {
 			return
 		}
 
-		c.ResponseOk(webhooks)
+		c.ResponseOk(object.GetMaskedWebhooks(webhooks))
 	} else {
 		limit := util.ParseInt(limit)
 		count, err := object.GetWebhookCount(owner, organization, field, value)
@@ -64,7 +65,7 @@ func (c *ApiController) GetWebhooks() {
 			return
 		}
 
-		c.ResponseOk(webhooks, paginator.Nums())
+		c.ResponseOk(object.GetMaskedWebhooks(webhooks), paginator.Nums())
 	}
 }
 
@@ -84,7 +85,7 @@ func (c *ApiController) GetWebhook() {
 		return
 	}
 
-	c.ResponseOk(webhook)
+	c.ResponseOk(object.GetMaskedWebhook(webhook))
 }
 
 // UpdateWebhook
@@ -146,3 +147,75 @@ func (c *ApiController) DeleteWebhook() {
 	c.Data["json"] = wrapActionResponse(object.DeleteWebhook(&webhook))
 	c.ServeJSON()
 }
+
+// GetWebhookDeliveries
+// @Title GetWebhookDeliveries
+// @Tag Webhook API
+// @Description get the deliveries of the webhook
+// @Param   owner     query    string  built-in/admin	true        "The owner of the webhook"
+// @Param   webhook   query    string  true        "The name of the webhook"
+// @Success 200 {array} object.WebhookDelivery The Response object
+// @router /get-webhook-deliveries [get]
+func (c *ApiController) GetWebhookDeliveries() {
+	owner := c.Input().Get("owner")
+	webhook := c.Input().Get("webhook")
+	limit := c.Input().Get("pageSize")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	count, err := object.GetWebhookDeliveryCount(owner, webhook, field, value)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	paginator := pagination.SetPaginator(c.Ctx, util.ParseInt(limit), count)
+	deliveries, err := object.GetPaginationWebhookDeliveries(owner, webhook, paginator.Offset(), util.ParseInt(limit), field, value, sortField, sortOrder)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(deliveries, paginator.Nums())
+}
+
+// RedeliverWebhookDelivery
+// @Title RedeliverWebhookDelivery
+// @Tag Webhook API
+// @Description send the payload of the webhook delivery again
+// @Param   body    body   object.WebhookDelivery  true        "The webhook delivery"
+// @Success 200 {object} controllers.Response The Response object
+// @router /redeliver-webhook-delivery [post]
+func (c *ApiController) RedeliverWebhookDelivery() {
+	var delivery object.WebhookDelivery
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &delivery)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	oldDelivery, err := object.GetWebhookDelivery(delivery.GetId())
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	if oldDelivery == nil {
+		c.ResponseError(fmt.Sprintf(c.T("webhook:The webhook delivery: %s does not exist"), delivery.GetId()))
+		return
+	}
+
+	newDelivery, err := object.RedeliverWebhookDelivery(oldDelivery)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(newDelivery)
+}
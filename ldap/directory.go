@@ -0,0 +1,304 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/forestmgy/ldapserver"
+)
+
+var (
+	userObjectClasses  = []string{"top", "person", "organizationalPerson", "inetOrgPerson", "posixAccount"}
+	groupObjectClasses = []string{"top", "posixGroup", "groupOfNames"}
+)
+
+// ldapDirectory builds the entries of an organization, the users are at "uid=<id>,cn=<name>,ou=<org>,<dc>",
+// the groups are at "cn=<name>,ou=groups,ou=<org>,<dc>" and the roles are at "cn=<name>,ou=roles,ou=<org>,<dc>"
+type ldapDirectory struct {
+	owner  string
+	suffix string
+	groups []*object.Group
+	roles  []*object.Role
+}
+
+// getDnSuffix returns the "dc=" part of the DN, which is kept as is in the DNs of the entries
+func getDnSuffix(dn string) string {
+	res := []string{}
+	for _, rdn := range strings.Split(dn, ",") {
+		rdn = strings.TrimSpace(rdn)
+		if strings.HasPrefix(strings.ToLower(rdn), "dc=") {
+			res = append(res, rdn)
+		}
+	}
+	return strings.Join(res, ",")
+}
+
+// isGroupContainerDn returns true if the DN is in "ou=groups" or "ou=roles" of an organization, where there are no users
+func isGroupContainerDn(dn string) bool {
+	ous := []string{}
+	for _, rdn := range splitDn(dn) {
+		if strings.HasPrefix(rdn, "ou=") {
+			ous = append(ous, rdn)
+		}
+	}
+	return len(ous) >= 2 && (ous[len(ous)-2] == "ou=groups" || ous[len(ous)-2] == "ou=roles")
+}
+
+func newLdapDirectory(org string, suffix string) (*ldapDirectory, error) {
+	// the global admin can search all the organizations by "ou=*"
+	owner := org
+	if org == "*" {
+		owner = ""
+	}
+
+	groups, err := object.GetGroups(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := object.GetRoles(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ldapDirectory{owner: owner, suffix: suffix, groups: groups, roles: roles}, nil
+}
+
+func (d *ldapDirectory) getOrgDn(org string) string {
+	if d.suffix == "" {
+		return fmt.Sprintf("ou=%s", org)
+	}
+	return fmt.Sprintf("ou=%s,%s", org, d.suffix)
+}
+
+func (d *ldapDirectory) getUserDn(user *object.User) string {
+	return fmt.Sprintf("uid=%s,cn=%s,%s", user.Id, user.Name, d.getOrgDn(user.Owner))
+}
+
+func (d *ldapDirectory) getGroupDn(group *object.Group) string {
+	return fmt.Sprintf("cn=%s,ou=groups,%s", group.Name, d.getOrgDn(group.Owner))
+}
+
+func (d *ldapDirectory) getRoleDn(role *object.Role) string {
+	return fmt.Sprintf("cn=%s,ou=roles,%s", role.Name, d.getOrgDn(role.Owner))
+}
+
+func isUserInGroup(user *object.User, group *object.Group) bool {
+	if user.Owner != group.Owner {
+		return false
+	}
+	return util.InSlice(user.Groups, group.GetId()) || util.InSlice(user.Groups, group.Name)
+}
+
+func isUserInRole(user *object.User, role *object.Role) bool {
+	return util.InSlice(role.Users, user.GetId()) || util.HaveIntersection(role.Groups, user.Groups)
+}
+
+func (d *ldapDirectory) getMemberOf(user *object.User) []string {
+	res := []string{}
+	for _, group := range d.groups {
+		if isUserInGroup(user, group) {
+			res = append(res, d.getGroupDn(group))
+		}
+	}
+	for _, role := range d.roles {
+		if isUserInRole(user, role) {
+			res = append(res, d.getRoleDn(role))
+		}
+	}
+	return res
+}
+
+func (d *ldapDirectory) getUserEntry(user *object.User, withPassword bool) *ldapEntry {
+	entry := newLdapEntry(d.getUserDn(user), d.getOrgDn(user.Owner))
+	idNumber := fmt.Sprintf("%v", hash(user.Name))
+	entry.addAttribute("objectClass", userObjectClasses...)
+	entry.addAttribute("uidNumber", idNumber)
+	entry.addAttribute("gidNumber", idNumber)
+	entry.addAttribute("homeDirectory", "/home/"+user.Name)
+	entry.addAttribute("cn", user.Name)
+	entry.addAttribute("uid", user.Name)
+
+	attributes := []string{}
+	for attribute := range ldapAttributesMapping {
+		if attribute != "cn" && attribute != "uid" && attribute != "userPassword" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	sort.Strings(attributes)
+	for _, attribute := range attributes {
+		entry.addAttribute(attribute, string(getAttribute(attribute, user)))
+	}
+
+	if withPassword {
+		entry.addAttribute("userPassword", string(getAttribute("userPassword", user)))
+	}
+
+	entry.addAttribute("entryUUID", user.Id)
+	entry.addAttribute("memberOf", d.getMemberOf(user)...)
+	return entry
+}
+
+// getGroupEntry returns the entry of a group or a role, the gidNumber is hashed from the key so that it
+// doesn't depend on the DN of the search base
+func (d *ldapDirectory) getGroupEntry(dn string, parentDn string, key string, name string, description string, members []*object.User) *ldapEntry {
+	entry := newLdapEntry(dn, parentDn)
+	entry.addAttribute("objectClass", groupObjectClasses...)
+	entry.addAttribute("cn", name)
+	entry.addAttribute("description", description)
+	entry.addAttribute("gidNumber", fmt.Sprintf("%v", hash(key)))
+	for _, user := range members {
+		entry.addAttribute("memberUid", user.Name)
+		entry.addAttribute("member", d.getUserDn(user))
+	}
+	return entry
+}
+
+func (d *ldapDirectory) getContainerEntries() []*ldapEntry {
+	if d.owner == "" {
+		return []*ldapEntry{}
+	}
+
+	orgDn := d.getOrgDn(d.owner)
+	orgEntry := newLdapEntry(orgDn, d.suffix)
+	orgEntry.addAttribute("objectClass", "top", "organizationalUnit")
+	orgEntry.addAttribute("ou", d.owner)
+
+	res := []*ldapEntry{orgEntry}
+	for _, name := range []string{"groups", "roles"} {
+		entry := newLdapEntry(fmt.Sprintf("ou=%s,%s", name, orgDn), orgDn)
+		entry.addAttribute("objectClass", "top", "organizationalUnit")
+		entry.addAttribute("ou", name)
+		res = append(res, entry)
+	}
+	return res
+}
+
+// getGroupEntries returns the entries of the groups and the roles, the members are queried for each of them
+func (d *ldapDirectory) getGroupEntries() ([]*ldapEntry, error) {
+	res := []*ldapEntry{}
+	for _, group := range d.groups {
+		members, err := object.GetGroupUsers(group.GetId())
+		if err != nil {
+			return nil, err
+		}
+
+		dn := d.getGroupDn(group)
+		res = append(res, d.getGroupEntry(dn, fmt.Sprintf("ou=groups,%s", d.getOrgDn(group.Owner)), "group:"+group.GetId(), group.Name, group.DisplayName, members))
+	}
+
+	for _, role := range d.roles {
+		members, err := object.GetRoleUsers(role)
+		if err != nil {
+			return nil, err
+		}
+
+		dn := d.getRoleDn(role)
+		res = append(res, d.getGroupEntry(dn, fmt.Sprintf("ou=roles,%s", d.getOrgDn(role.Owner)), "role:"+role.GetId(), role.Name, role.DisplayName, members))
+	}
+	return res, nil
+}
+
+func getRootDseEntry(m *ldap.Message) *ldapEntry {
+	entry := newLdapEntry("", "")
+	entry.addAttribute("objectClass", "top")
+	entry.addAttribute("supportedLDAPVersion", "3")
+	entry.addAttribute("vendorName", "Casdoor")
+	entry.addAttribute("supportedExtension", passwordModifyOid)
+	entry.addAttribute("supportedControl", pagedResultsOid)
+	if !m.Client.IsAuthenticated {
+		return entry
+	}
+
+	if m.Client.IsGlobalAdmin {
+		organizations, err := object.GetOrganizations("admin")
+		if err != nil {
+			log.Printf("getRootDseEntry() error: %s", err.Error())
+			return entry
+		}
+
+		for _, organization := range organizations {
+			entry.addAttribute("namingContexts", fmt.Sprintf("ou=%s", organization.Name))
+		}
+	} else {
+		entry.addAttribute("namingContexts", fmt.Sprintf("ou=%s", m.Client.OrgName))
+	}
+	return entry
+}
+
+// getSearchEntries returns the candidate entries of the search request, the scope and the filter are
+// evaluated by the caller
+func getSearchEntries(m *ldap.Message, attributes []string) ([]*ldapEntry, int) {
+	r := m.GetSearchRequest()
+	baseDn := string(r.BaseObject())
+
+	_, org, code := getNameAndOrgFromFilter(baseDn, r.Filter())
+	if code != ldap.LDAPResultSuccess {
+		return nil, code
+	}
+
+	directory, err := newLdapDirectory(org, getDnSuffix(baseDn))
+	if err != nil {
+		log.Printf("newLdapDirectory() error: %s", err.Error())
+		return nil, ldap.LDAPResultOperationsError
+	}
+
+	// the groups and the roles of the organization are readable by all of its bound clients, like the
+	// group entries of the other directories, while the users are filtered by the permissions below
+	entries := []*ldapEntry{}
+	if m.Client.IsGlobalAdmin || org == m.Client.OrgName {
+		entries = append(entries, directory.getContainerEntries()...)
+
+		if mayMatchObjectClasses(r.Filter(), groupObjectClasses) {
+			groupEntries, err := directory.getGroupEntries()
+			if err != nil {
+				log.Printf("getGroupEntries() error: %s", err.Error())
+				return nil, ldap.LDAPResultOperationsError
+			}
+
+			entries = append(entries, groupEntries...)
+		}
+	}
+
+	if !isGroupContainerDn(baseDn) && mayMatchObjectClasses(r.Filter(), userObjectClasses) {
+		users, code := GetFilteredUsers(m)
+		if code != ldap.LDAPResultSuccess {
+			return nil, code
+		}
+
+		// the password hashes are only returned to the admins of the organization, and only if they are
+		// requested by name, so that "*" doesn't leak them to the clients that only read the profiles
+		withPassword := false
+		if m.Client.IsGlobalAdmin || (m.Client.IsOrgAdmin && org == m.Client.OrgName) {
+			for _, attribute := range attributes {
+				if strings.EqualFold(attribute, "userPassword") {
+					withPassword = true
+				}
+			}
+		}
+
+		for _, user := range users {
+			entries = append(entries, directory.getUserEntry(user, withPassword))
+		}
+	}
+
+	return entries, ldap.LDAPResultSuccess
+}
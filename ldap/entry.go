@@ -0,0 +1,286 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"strconv"
+	"strings"
+
+	ldap "github.com/forestmgy/ldapserver"
+	"github.com/lor00x/goldap/message"
+)
+
+// the scopes of the search request, see RFC 4511 section 4.5.1.2
+const (
+	scopeBaseObject   = 0
+	scopeSingleLevel  = 1
+	scopeWholeSubtree = 2
+)
+
+// the operational attributes are only returned when they are requested explicitly or by "+"
+var operationalAttributes = map[string]bool{
+	"memberof":  true,
+	"entryuuid": true,
+}
+
+type ldapEntry struct {
+	dn       string
+	parentDn string
+
+	// the attribute names in the order they are added, and the values keyed by the lowercase names
+	names  []string
+	values map[string][]string
+}
+
+func newLdapEntry(dn string, parentDn string) *ldapEntry {
+	return &ldapEntry{
+		dn:       dn,
+		parentDn: parentDn,
+		names:    []string{},
+		values:   map[string][]string{},
+	}
+}
+
+// addAttribute adds the non-empty values to the attribute
+func (entry *ldapEntry) addAttribute(name string, values ...string) {
+	key := strings.ToLower(name)
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		if _, ok := entry.values[key]; !ok {
+			entry.names = append(entry.names, name)
+		}
+		entry.values[key] = append(entry.values[key], value)
+	}
+}
+
+func (entry *ldapEntry) getValues(name string) []string {
+	return entry.values[strings.ToLower(name)]
+}
+
+// splitDn returns the normalized RDNs of the DN, e.g. "cn=Alice, ou=built-in" -> ["cn=alice", "ou=built-in"]
+func splitDn(dn string) []string {
+	res := []string{}
+	for _, rdn := range strings.Split(dn, ",") {
+		rdn = strings.ToLower(strings.TrimSpace(rdn))
+		if rdn != "" {
+			res = append(res, rdn)
+		}
+	}
+	return res
+}
+
+// isRdnMatched compares the RDN of the entry with the RDN of the base, "ou=*" in the base matches any organization
+func isRdnMatched(rdn string, baseRdn string) bool {
+	if rdn == baseRdn {
+		return true
+	}
+
+	index := strings.Index(baseRdn, "=")
+	if index == -1 || baseRdn[index+1:] != "*" {
+		return false
+	}
+	return strings.HasPrefix(rdn, baseRdn[:index+1])
+}
+
+func isDnMatched(rdns []string, baseRdns []string) bool {
+	if len(rdns) != len(baseRdns) {
+		return false
+	}
+
+	for i := range rdns {
+		if !isRdnMatched(rdns[i], baseRdns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (entry *ldapEntry) isInScope(baseDn string, scope int) bool {
+	baseRdns := splitDn(baseDn)
+	switch scope {
+	case scopeBaseObject:
+		return isDnMatched(splitDn(entry.dn), baseRdns)
+	case scopeSingleLevel:
+		return isDnMatched(splitDn(entry.parentDn), baseRdns)
+	default:
+		rdns := splitDn(entry.dn)
+		if len(rdns) < len(baseRdns) {
+			return false
+		}
+		return isDnMatched(rdns[len(rdns)-len(baseRdns):], baseRdns)
+	}
+}
+
+func compareValues(value string, assertion string) int {
+	valueInt, err1 := strconv.ParseInt(value, 10, 64)
+	assertionInt, err2 := strconv.ParseInt(assertion, 10, 64)
+	if err1 == nil && err2 == nil {
+		if valueInt < assertionInt {
+			return -1
+		} else if valueInt > assertionInt {
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(strings.ToLower(value), strings.ToLower(assertion))
+}
+
+func matchSubstrings(value string, filter message.FilterSubstrings) bool {
+	value = strings.ToLower(value)
+	for _, substring := range filter.Substrings() {
+		switch s := substring.(type) {
+		case message.SubstringInitial:
+			prefix := strings.ToLower(string(s))
+			if !strings.HasPrefix(value, prefix) {
+				return false
+			}
+			value = value[len(prefix):]
+		case message.SubstringAny:
+			part := strings.ToLower(string(s))
+			index := strings.Index(value, part)
+			if index == -1 {
+				return false
+			}
+			value = value[index+len(part):]
+		case message.SubstringFinal:
+			if !strings.HasSuffix(value, strings.ToLower(string(s))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchFilter evaluates the search filter against the entry, the values are compared case-insensitively
+func (entry *ldapEntry) matchFilter(filter interface{}) bool {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		for _, v := range f {
+			if !entry.matchFilter(v) {
+				return false
+			}
+		}
+		return true
+	case message.FilterOr:
+		for _, v := range f {
+			if entry.matchFilter(v) {
+				return true
+			}
+		}
+		return false
+	case message.FilterNot:
+		return !entry.matchFilter(f.Filter)
+	case message.FilterEqualityMatch:
+		for _, value := range entry.getValues(string(f.AttributeDesc())) {
+			if strings.EqualFold(value, string(f.AssertionValue())) {
+				return true
+			}
+		}
+		return false
+	case message.FilterPresent:
+		return len(entry.getValues(string(f))) != 0
+	case message.FilterGreaterOrEqual:
+		for _, value := range entry.getValues(string(f.AttributeDesc())) {
+			if compareValues(value, string(f.AssertionValue())) >= 0 {
+				return true
+			}
+		}
+		return false
+	case message.FilterLessOrEqual:
+		for _, value := range entry.getValues(string(f.AttributeDesc())) {
+			if compareValues(value, string(f.AssertionValue())) <= 0 {
+				return true
+			}
+		}
+		return false
+	case message.FilterSubstrings:
+		for _, value := range entry.getValues(string(f.Type_())) {
+			if matchSubstrings(value, f) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// mayMatchObjectClasses returns false when the filter requires an object class that is not in the object classes,
+// so that the entries of these object classes don't need to be loaded
+func mayMatchObjectClasses(filter interface{}, objectClasses []string) bool {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		for _, v := range f {
+			if !mayMatchObjectClasses(v, objectClasses) {
+				return false
+			}
+		}
+		return true
+	case message.FilterOr:
+		for _, v := range f {
+			if mayMatchObjectClasses(v, objectClasses) {
+				return true
+			}
+		}
+		return false
+	case message.FilterEqualityMatch:
+		if !strings.EqualFold(string(f.AttributeDesc()), "objectClass") {
+			return true
+		}
+
+		for _, objectClass := range objectClasses {
+			if strings.EqualFold(objectClass, string(f.AssertionValue())) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func isAttributeSelected(name string, attributes []string) bool {
+	isOperational := operationalAttributes[strings.ToLower(name)]
+	if len(attributes) == 0 {
+		return !isOperational
+	}
+
+	for _, attribute := range attributes {
+		if strings.EqualFold(attribute, name) || (attribute == "*" && !isOperational) || (attribute == "+" && isOperational) {
+			return true
+		}
+	}
+	return false
+}
+
+func (entry *ldapEntry) getSearchResultEntry(attributes []string) message.SearchResultEntry {
+	e := ldap.NewSearchResultEntry(entry.dn)
+	for _, name := range entry.names {
+		if !isAttributeSelected(name, attributes) {
+			continue
+		}
+
+		values := []message.AttributeValue{}
+		for _, value := range entry.getValues(name) {
+			values = append(values, message.AttributeValue(value))
+		}
+		e.AddAttribute(message.AttributeDescription(name), values...)
+	}
+	return e
+}
@@ -0,0 +1,75 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/lor00x/goldap/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLdapEntryMatchFilter(t *testing.T) {
+	entry := newLdapEntry("cn=admins,ou=groups,ou=built-in,dc=example,dc=com", "ou=groups,ou=built-in,dc=example,dc=com")
+	entry.addAttribute("objectClass", groupObjectClasses...)
+	entry.addAttribute("cn", "admins")
+	entry.addAttribute("gidNumber", "1000")
+	entry.addAttribute("memberUid", "alice", "bob")
+
+	scenarios := []struct {
+		description string
+		input       string
+		expected    bool
+	}{
+		{"Should match FilterEqualityMatch case-insensitively", "(objectclass=PosixGroup)", true},
+		{"Should match FilterAnd", "(&(objectClass=posixGroup)(memberUid=bob))", true},
+		{"Should not match FilterAnd", "(&(objectClass=posixGroup)(memberUid=carol))", false},
+		{"Should match FilterOr", "(|(cn=users)(cn=admins))", true},
+		{"Should match FilterNot", "(!(objectClass=person))", true},
+		{"Should match FilterPresent", "(memberUid=*)", true},
+		{"Should not match FilterPresent", "(mail=*)", false},
+		{"Should match FilterSubstrings", "(cn=ad*in*)", true},
+		{"Should not match FilterSubstrings", "(cn=*users)", false},
+		{"Should match FilterGreaterOrEqual numerically", "(gidNumber>=999)", true},
+		{"Should not match FilterLessOrEqual numerically", "(gidNumber<=999)", false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			searchRequest, err := buildLdapSearchRequest(scenery.input)
+			if err != nil {
+				assert.FailNow(t, "Unable to create searchRequest", err)
+			}
+			m, err := message.ReadLDAPMessage(message.NewBytes(0, searchRequest.Bytes()))
+			if err != nil {
+				assert.FailNow(t, "Unable to create searchRequest", err)
+			}
+			req := m.ProtocolOp().(message.SearchRequest)
+
+			assert.Equal(t, scenery.expected, entry.matchFilter(req.Filter()))
+		})
+	}
+}
+
+func TestLdapEntryIsInScope(t *testing.T) {
+	entry := newLdapEntry("uid=1234,cn=alice,ou=built-in,dc=example,dc=com", "ou=built-in,dc=example,dc=com")
+
+	scenarios := []struct {
+		description string
+		baseDn      string
+		scope       int
+		expected    bool
+	}{
+		{"Should be in base object scope", "uid=1234, cn=Alice, ou=built-in, dc=example, dc=com", scopeBaseObject, true},
+		{"Should not be in base object scope", "ou=built-in,dc=example,dc=com", scopeBaseObject, false},
+		{"Should be in single level scope of the organization", "ou=built-in,dc=example,dc=com", scopeSingleLevel, true},
+		{"Should not be in single level scope of the groups", "ou=groups,ou=built-in,dc=example,dc=com", scopeSingleLevel, false},
+		{"Should be in subtree scope", "dc=example,dc=com", scopeWholeSubtree, true},
+		{"Should be in subtree scope of all organizations", "ou=*,dc=example,dc=com", scopeWholeSubtree, true},
+		{"Should not be in subtree scope of another organization", "ou=casbin,dc=example,dc=com", scopeWholeSubtree, false},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			assert.Equal(t, scenery.expected, entry.isInScope(scenery.baseDn, scenery.scope))
+		})
+	}
+}
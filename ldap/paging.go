@@ -0,0 +1,216 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/forestmgy/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// the OID of the Simple Paged Results control, see RFC 2696
+const pagedResultsOid = "1.2.840.113556.1.4.319"
+
+// the cursor of a paged search expires if the next page is not requested in time
+const pagedSearchTimeout = 10 * time.Minute
+
+type pagedResultsControl struct {
+	size   int
+	cookie string
+}
+
+// pagedSearch is the cursor of a paged search, the entries matched for the first page are kept so that the
+// next pages are read from the cursor instead of searching the directory again. The cursor belongs to the
+// connection of the search, and is referred by the cookie of the paged results control.
+type pagedSearch struct {
+	conn      net.Conn
+	entries   []*ldapEntry
+	code      int
+	offset    int
+	expiresAt time.Time
+}
+
+var pagedSearches sync.Map
+
+// getPagedResultsControl returns the paged results control of the search request, or nil if it is not requested.
+func getPagedResultsControl(m *ldap.Message) (*pagedResultsControl, error) {
+	controls := m.Controls()
+	if controls == nil {
+		return nil, nil
+	}
+
+	for _, control := range *controls {
+		if string(control.ControlType()) != pagedResultsOid {
+			continue
+		}
+
+		if control.ControlValue() == nil {
+			return nil, fmt.Errorf("the value of the paged results control is missing")
+		}
+
+		// realSearchControlValue ::= SEQUENCE { size INTEGER, cookie OCTET STRING }
+		packet, err := ber.DecodePacketErr([]byte(*control.ControlValue()))
+		if err != nil {
+			return nil, err
+		}
+		if len(packet.Children) != 2 {
+			return nil, fmt.Errorf("the value of the paged results control is invalid")
+		}
+
+		size, ok := packet.Children[0].Value.(int64)
+		if !ok || size < 0 {
+			return nil, fmt.Errorf("the size of the paged results control is invalid")
+		}
+
+		return &pagedResultsControl{size: int(size), cookie: packet.Children[1].Data.String()}, nil
+	}
+	return nil, nil
+}
+
+func encodeLdapMessage(messageId int64, protocolOp *ber.Packet, controls *ber.Packet) []byte {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageId, "MessageID"))
+	packet.AppendChild(protocolOp)
+	if controls != nil {
+		packet.AppendChild(controls)
+	}
+	return packet.Bytes()
+}
+
+func encodeSearchResultEntry(entry *ldapEntry, attributes []string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "Object Name"))
+
+	attributesPacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for _, name := range entry.names {
+		if !isAttributeSelected(name, attributes) {
+			continue
+		}
+
+		attributePacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attributePacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		valuesPacket := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range entry.getValues(name) {
+			valuesPacket.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attributePacket.AppendChild(valuesPacket)
+		attributesPacket.AppendChild(attributePacket)
+	}
+	packet.AppendChild(attributesPacket)
+	return packet
+}
+
+func encodeSearchResultDone(code int) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 5, nil, "Search Result Done")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return packet
+}
+
+func encodePagedResultsControl(total int, cookie string) *ber.Packet {
+	value := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Paged Results")
+	value.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, int64(total), "Size"))
+	value.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, cookie, "Cookie"))
+
+	control := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Control")
+	control.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, pagedResultsOid, "Control Type"))
+	control.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, string(value.Bytes()), "Control Value"))
+
+	controls := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "Controls")
+	controls.AppendChild(control)
+	return controls
+}
+
+func newPagedSearch(m *ldap.Message, entries []*ldapEntry, code int) *pagedSearch {
+	return &pagedSearch{conn: m.Client.GetConn(), entries: entries, code: code}
+}
+
+// takePagedSearch removes the cursor of the cookie, so that the concurrent requests of the same page don't
+// share the cursor. It returns nil if the cursor doesn't exist, has expired or belongs to another connection.
+func takePagedSearch(m *ldap.Message, cookie string) *pagedSearch {
+	value, ok := pagedSearches.LoadAndDelete(cookie)
+	if !ok {
+		return nil
+	}
+
+	search := value.(*pagedSearch)
+	if search.conn != m.Client.GetConn() || time.Now().After(search.expiresAt) {
+		return nil
+	}
+	return search
+}
+
+// sweepPagedSearches removes the expired cursors of the paged searches abandoned by the clients
+func sweepPagedSearches() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		pagedSearches.Range(func(key, value interface{}) bool {
+			if now.After(value.(*pagedSearch).expiresAt) {
+				pagedSearches.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// writePagedSearchResults writes the next page of the cursor with the paged results control in the search
+// result done, and keeps the cursor under a new cookie if there are more pages. The response writer of the
+// LDAP server can't carry the response controls, so the messages of the page are written to the connection
+// in a single write, which can't be interleaved with the responses of the other operations.
+func writePagedSearchResults(m *ldap.Message, search *pagedSearch, attributes []string, size int) error {
+	messageId := int64(m.MessageID())
+
+	start := search.offset
+	if start > len(search.entries) {
+		start = len(search.entries)
+	}
+	end := start + size
+	if end > len(search.entries) {
+		end = len(search.entries)
+	}
+
+	// the size of 0 abandons the paged search
+	cookie := ""
+	if size != 0 && end < len(search.entries) {
+		cookie = util.GenerateId()
+		search.offset = end
+		search.expiresAt = time.Now().Add(pagedSearchTimeout)
+		pagedSearches.Store(cookie, search)
+	}
+
+	var buffer bytes.Buffer
+	for _, entry := range search.entries[start:end] {
+		buffer.Write(encodeLdapMessage(messageId, encodeSearchResultEntry(entry, attributes), nil))
+	}
+
+	// the size limit is only exceeded when the last page is reached
+	code := search.code
+	if cookie != "" {
+		code = ldap.LDAPResultSuccess
+	}
+	buffer.Write(encodeLdapMessage(messageId, encodeSearchResultDone(code), encodePagedResultsControl(len(search.entries), cookie)))
+
+	_, err := m.Client.GetConn().Write(buffer.Bytes())
+	return err
+}
@@ -15,6 +15,7 @@
 package ldap
 
 import (
+	"crypto/tls"
 	"fmt"
 	"hash/fnv"
 	"log"
@@ -22,23 +23,58 @@ import (
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/object"
 	ldap "github.com/forestmgy/ldapserver"
-	"github.com/lor00x/goldap/message"
 )
 
+// refuse the simple binds over the unencrypted connections, so that the passwords are not sent in plaintext
+var requireTls bool
+
+func init() {
+	requireTls = conf.GetConfigBool("ldapRequireTls")
+}
+
 func StartLdapServer() {
 	ldapServerPort := conf.GetConfigString("ldapServerPort")
-	if ldapServerPort == "" || ldapServerPort == "0" {
-		return
+	ldapsServerPort := conf.GetConfigString("ldapsServerPort")
+
+	// LDAPS and StartTLS are disabled if the certificate can't be loaded, the plaintext LDAP is still served
+	tlsConfig, err := getTlsConfig()
+	if err != nil {
+		log.Printf("StartLdapServer() failed to load the TLS certificate, LDAPS and StartTLS are disabled, err = %s", err.Error())
+		tlsConfig = nil
 	}
 
-	server := ldap.NewServer()
-	routes := ldap.NewRouteMux()
+	go sweepPagedSearches()
 
+	routes := ldap.NewRouteMux()
 	routes.Bind(handleBind)
 	routes.Search(handleSearch).Label(" SEARCH****")
+	routes.Modify(handleModify).Label(" MODIFY****")
+	routes.Add(handleAdd).Label(" ADD****")
+	routes.Extended(handlePasswordModify).RequestName(passwordModifyOid).Label(" PASSWORD MODIFY****")
+	if tlsConfig != nil {
+		routes.Extended(handleStartTls(tlsConfig)).RequestName(ldap.NoticeOfStartTLS).Label("StartTLS")
+	}
+
+	if tlsConfig != nil && ldapsServerPort != "" && ldapsServerPort != "0" {
+		go func() {
+			server := ldap.NewServer()
+			server.Handle(routes)
+			err := server.ListenAndServe("0.0.0.0:"+ldapsServerPort, func(s *ldap.Server) {
+				s.Listener = tls.NewListener(s.Listener, tlsConfig)
+			})
+			if err != nil {
+				log.Printf("StartLdapServer() failed to start LDAPS, err = %s", err.Error())
+			}
+		}()
+	}
 
+	if ldapServerPort == "" || ldapServerPort == "0" {
+		return
+	}
+
+	server := ldap.NewServer()
 	server.Handle(routes)
-	err := server.ListenAndServe("0.0.0.0:" + ldapServerPort)
+	err = server.ListenAndServe("0.0.0.0:" + ldapServerPort)
 	if err != nil {
 		log.Printf("StartLdapServer() failed, err = %s", err.Error())
 	}
@@ -49,6 +85,13 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 	res := ldap.NewBindResponse(ldap.LDAPResultSuccess)
 
 	if r.AuthenticationChoice() == "simple" {
+		if requireTls && !isTlsConnection(m) {
+			res.SetResultCode(ldap.LDAPResultConfidentialityRequired)
+			res.SetDiagnosticMessage("Simple Authentication over an unencrypted connection is not allowed, please use LDAPS or StartTLS")
+			w.Write(res)
+			return
+		}
+
 		bindUsername, bindOrg, err := getNameAndOrgFromDN(string(r.Name()))
 		if err != nil {
 			log.Printf("getNameAndOrgFromDN() error: %s", err.Error())
@@ -58,8 +101,18 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 			return
 		}
 
+		clientIp := getClientIp(m)
+		err = object.CheckSigninThrottle(clientIp, bindOrg, bindUsername, "en")
+		if err != nil {
+			res.SetResultCode(ldap.LDAPResultInvalidCredentials)
+			res.SetDiagnosticMessage(err.Error())
+			w.Write(res)
+			return
+		}
+
 		bindPassword := string(r.AuthenticationSimple())
 		bindUser, err := object.CheckUserPassword(bindOrg, bindUsername, bindPassword, "en")
+		object.RecordSigninThrottle(clientIp, bindOrg, bindUsername, err == nil)
 		if err != nil {
 			log.Printf("Bind failed User=%s, Pass=%#v, ErrMsg=%s", string(r.Name()), r.Authentication(), err)
 			res.SetResultCode(ldap.LDAPResultInvalidCredentials)
@@ -86,14 +139,25 @@ func handleBind(w ldap.ResponseWriter, m *ldap.Message) {
 
 func handleSearch(w ldap.ResponseWriter, m *ldap.Message) {
 	res := ldap.NewSearchResultDoneResponse(ldap.LDAPResultSuccess)
-	if !m.Client.IsAuthenticated {
-		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+	r := m.GetSearchRequest()
+
+	attributes := []string{}
+	for _, attribute := range r.Attributes() {
+		attributes = append(attributes, string(attribute))
+	}
+
+	// the root DSE can be read before binding, so that the clients can discover the server
+	if string(r.BaseObject()) == "" && int(r.Scope()) == scopeBaseObject {
+		entry := getRootDseEntry(m)
+		if entry.matchFilter(r.Filter()) {
+			w.Write(entry.getSearchResultEntry(attributes))
+		}
 		w.Write(res)
 		return
 	}
 
-	r := m.GetSearchRequest()
-	if r.FilterString() == "(objectClass=*)" {
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
 		w.Write(res)
 		return
 	}
@@ -106,38 +170,64 @@ func handleSearch(w ldap.ResponseWriter, m *ldap.Message) {
 	default:
 	}
 
-	users, code := GetFilteredUsers(m)
+	paging, err := getPagedResultsControl(m)
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultProtocolError)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+
+	// the next pages of a paged search are read from the cursor of the first page
+	if paging != nil && paging.cookie != "" {
+		search := takePagedSearch(m, paging.cookie)
+		if search == nil {
+			res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+			res.SetDiagnosticMessage("the cookie of the paged results control is invalid or expired")
+			w.Write(res)
+			return
+		}
+
+		err = writePagedSearchResults(m, search, attributes, paging.size)
+		if err != nil {
+			log.Printf("writePagedSearchResults() error: %s", err.Error())
+		}
+		return
+	}
+
+	entries, code := getSearchEntries(m, attributes)
 	if code != ldap.LDAPResultSuccess {
 		res.SetResultCode(code)
 		w.Write(res)
 		return
 	}
 
-	for _, user := range users {
-		dn := fmt.Sprintf("uid=%s,cn=%s,%s", user.Id, user.Name, string(r.BaseObject()))
-		e := ldap.NewSearchResultEntry(dn)
-		uidNumberStr := fmt.Sprintf("%v", hash(user.Name))
-		e.AddAttribute("uidNumber", message.AttributeValue(uidNumberStr))
-		e.AddAttribute("gidNumber", message.AttributeValue(uidNumberStr))
-		e.AddAttribute("homeDirectory", message.AttributeValue("/home/"+user.Name))
-		e.AddAttribute("cn", message.AttributeValue(user.Name))
-		e.AddAttribute("uid", message.AttributeValue(user.Id))
-		attrs := r.Attributes()
-		for _, attr := range attrs {
-			if string(attr) == "*" {
-				attrs = AdditionalLdapAttributes
-				break
-			}
+	matchedEntries := []*ldapEntry{}
+	for _, entry := range entries {
+		if entry.isInScope(string(r.BaseObject()), int(r.Scope())) && entry.matchFilter(r.Filter()) {
+			matchedEntries = append(matchedEntries, entry)
 		}
-		for _, attr := range attrs {
-			e.AddAttribute(message.AttributeDescription(attr), getAttribute(string(attr), user))
-			if string(attr) == "cn" {
-				e.AddAttribute(message.AttributeDescription(attr), getAttribute("title", user))
-			}
+	}
+
+	code = ldap.LDAPResultSuccess
+	sizeLimit := int(r.SizeLimit())
+	if sizeLimit > 0 && len(matchedEntries) > sizeLimit {
+		matchedEntries = matchedEntries[:sizeLimit]
+		code = ldap.LDAPResultSizeLimitExceeded
+	}
+
+	if paging != nil {
+		err = writePagedSearchResults(m, newPagedSearch(m, matchedEntries, code), attributes, paging.size)
+		if err != nil {
+			log.Printf("writePagedSearchResults() error: %s", err.Error())
 		}
+		return
+	}
 
-		w.Write(e)
+	for _, entry := range matchedEntries {
+		w.Write(entry.getSearchResultEntry(attributes))
 	}
+	res.SetResultCode(code)
 	w.Write(res)
 }
 
@@ -0,0 +1,103 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+	ldap "github.com/forestmgy/ldapserver"
+)
+
+// getTlsConfig returns the TLS config of LDAPS and StartTLS, the certificate is read from the cert
+// "ldapCertId" in the cert table, or from the files "ldapCertFile" and "ldapKeyFile". It returns nil
+// if no certificate is configured.
+func getTlsConfig() (*tls.Config, error) {
+	certId := conf.GetConfigString("ldapCertId")
+	certFile := conf.GetConfigString("ldapCertFile")
+	keyFile := conf.GetConfigString("ldapKeyFile")
+
+	if certId != "" {
+		// the cert is read in each handshake, so that the updated or rotated cert takes effect without restarting
+		return &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				cert, err := object.GetActiveCert(certId)
+				if err != nil {
+					return nil, err
+				}
+				if cert == nil {
+					return nil, fmt.Errorf("the cert: %s does not exist", certId)
+				}
+
+				certificate, err := tls.X509KeyPair([]byte(cert.Certificate), []byte(cert.PrivateKey))
+				if err != nil {
+					return nil, err
+				}
+				return &certificate, nil
+			},
+		}, nil
+	}
+
+	if certFile != "" && keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{certificate},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func isTlsConnection(m *ldap.Message) bool {
+	_, ok := m.Client.GetConn().(*tls.Conn)
+	return ok
+}
+
+// handleStartTls upgrades the connection to TLS by the StartTLS extended operation, see RFC 4511 section 4.14
+func handleStartTls(tlsConfig *tls.Config) ldap.HandlerFunc {
+	return func(w ldap.ResponseWriter, m *ldap.Message) {
+		res := ldap.NewExtendedResponse(ldap.LDAPResultSuccess)
+		res.SetResponseName(ldap.NoticeOfStartTLS)
+
+		if isTlsConnection(m) {
+			res.SetResultCode(ldap.LDAPResultOperationsError)
+			res.SetDiagnosticMessage("TLS is already established")
+			w.Write(res)
+			return
+		}
+
+		// the response is sent in plaintext before the handshake
+		w.Write(res)
+
+		tlsConn := tls.Server(m.Client.GetConn(), tlsConfig)
+		err := tlsConn.Handshake()
+		if err != nil {
+			log.Printf("handleStartTls() error: %s", err.Error())
+			m.Client.GetConn().Close()
+			return
+		}
+
+		m.Client.SetConn(tlsConn)
+	}
+}
@@ -17,6 +17,7 @@ package ldap
 import (
 	"fmt"
 	"log"
+	"net"
 	"strings"
 
 	"github.com/casdoor/casdoor/object"
@@ -109,7 +110,7 @@ func getNameAndOrgFromDN(DN string) (string, string, error) {
 	return params["cn"], params["ou"], nil
 }
 
-func getNameAndOrgFromFilter(baseDN, filter string) (string, string, int) {
+func getNameAndOrgFromFilter(baseDN string, filter interface{}) (string, string, int) {
 	if !strings.Contains(baseDN, "ou=") {
 		return "", "", ldap.LDAPResultInvalidDNSyntax
 	}
@@ -122,24 +123,23 @@ func getNameAndOrgFromFilter(baseDN, filter string) (string, string, int) {
 	return name, org, ldap.LDAPResultSuccess
 }
 
-func getUsername(filter string) string {
-	nameIndex := strings.Index(filter, "cn=")
-	if nameIndex == -1 {
-		nameIndex = strings.Index(filter, "uid=")
-		if nameIndex == -1 {
-			return "*"
-		} else {
-			nameIndex += 4
+// getUsername returns the username that the filter requires by "cn" or "uid", e.g. "(&(objectClass=person)(uid=alice))",
+// it returns "*" if the filter can match more than one user
+func getUsername(filter interface{}) string {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		for _, v := range f {
+			if name := getUsername(v); name != "*" {
+				return name
+			}
+		}
+	case message.FilterEqualityMatch:
+		attribute := strings.ToLower(string(f.AttributeDesc()))
+		if (attribute == "cn" || attribute == "uid") && string(f.AssertionValue()) != "" {
+			return string(f.AssertionValue())
 		}
-	} else {
-		nameIndex += 3
-	}
-
-	var name string
-	for i := nameIndex; filter[i] != ')'; i++ {
-		name = name + string(filter[i])
 	}
-	return name
+	return "*"
 }
 
 func stringInSlice(value string, list []string) bool {
@@ -208,32 +208,85 @@ func buildUserFilterCondition(filter interface{}) (builder.Cond, error) {
 		if err != nil {
 			return nil, err
 		}
+		// "*" of the substrings is "%" of LIKE, e.g. (mail=*@corp.com) => email LIKE "%@corp.com"
 		var expr string
+		hasFinal := false
 		for _, substring := range f.Substrings() {
 			switch s := substring.(type) {
 			case message.SubstringInitial:
-				expr += string(s) + "%"
-				continue
+				expr += util.EscapeLikePattern(string(s))
 			case message.SubstringAny:
-				expr += string(s) + "%"
-				continue
+				expr += "%" + util.EscapeLikePattern(string(s))
 			case message.SubstringFinal:
-				expr += string(s)
-				continue
+				expr += "%" + util.EscapeLikePattern(string(s))
+				hasFinal = true
 			}
 		}
-		return builder.Expr(field+" LIKE ?", expr), nil
+		if !hasFinal {
+			expr += "%"
+		}
+		return builder.Expr(field+" LIKE ? ESCAPE '!'", expr), nil
 	default:
 		return nil, fmt.Errorf("LDAP filter operation %#v not supported", f)
 	}
 }
 
-func buildSafeCondition(filter interface{}) builder.Cond {
-	condition, err := buildUserFilterCondition(filter)
-	if err != nil {
-		log.Printf("err = %v", err.Error())
-		return nil
+// buildPushdownCondition builds the SQL condition of the parts of the filter that are supported by
+// buildUserFilterCondition. The condition may match more users than the filter when some parts are not
+// supported, so the filter is still evaluated against the entries. It returns false if the condition is not exact.
+func buildPushdownCondition(filter interface{}) (builder.Cond, bool) {
+	switch f := filter.(type) {
+	case message.FilterAnd:
+		conditions := []builder.Cond{}
+		isExact := true
+		for _, v := range f {
+			cond, exact := buildPushdownCondition(v)
+			if cond != nil {
+				conditions = append(conditions, cond)
+			}
+			isExact = isExact && exact
+		}
+		if len(conditions) == 0 {
+			return nil, false
+		}
+		return builder.And(conditions...), isExact
+	case message.FilterOr:
+		conditions := []builder.Cond{}
+		isExact := true
+		for _, v := range f {
+			cond, exact := buildPushdownCondition(v)
+			if cond == nil {
+				return nil, false
+			}
+			conditions = append(conditions, cond)
+			isExact = isExact && exact
+		}
+		return builder.Or(conditions...), isExact
+	case message.FilterNot:
+		// the negation of a condition that matches more users would match fewer users
+		cond, exact := buildPushdownCondition(f.Filter)
+		if cond == nil || !exact {
+			return nil, false
+		}
+		return builder.Not{cond}, true
+	case message.FilterPresent:
+		// the empty columns are not null, but the attributes of them are not present in the entries
+		cond, err := buildUserFilterCondition(filter)
+		if err != nil {
+			return nil, false
+		}
+		return cond, false
+	default:
+		cond, err := buildUserFilterCondition(filter)
+		if err != nil {
+			return nil, false
+		}
+		return cond, true
 	}
+}
+
+func buildSafeCondition(filter interface{}) builder.Cond {
+	condition, _ := buildPushdownCondition(filter)
 	return condition
 }
 
@@ -241,7 +294,7 @@ func GetFilteredUsers(m *ldap.Message) (filteredUsers []*object.User, code int)
 	var err error
 	r := m.GetSearchRequest()
 
-	name, org, code := getNameAndOrgFromFilter(string(r.BaseObject()), r.FilterString())
+	name, org, code := getNameAndOrgFromFilter(string(r.BaseObject()), r.Filter())
 	if code != ldap.LDAPResultSuccess {
 		return nil, code
 	}
@@ -345,3 +398,13 @@ func getUserFieldFromAttribute(attributeName string) (string, error) {
 	}
 	return v.GetField()
 }
+
+// getClientIp returns the IP of the client for the sign-in throttle
+func getClientIp(m *ldap.Message) string {
+	remoteAddr := m.Client.GetConn().RemoteAddr().String()
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
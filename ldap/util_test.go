@@ -29,7 +29,9 @@ func TestLdapFilterAsQuery(t *testing.T) {
 		{"Should be SQL for FilterPresent", "(mail=*)", "email IS NOT NULL", nil},
 		{"Should be SQL for FilterGreaterOrEqual", "(mail>=admin)", "email>=?", args("admin")},
 		{"Should be SQL for FilterLessOrEqual", "(mail<=admin)", "email<=?", args("admin")},
-		{"Should be SQL for FilterSubstrings", "(mail=admin*ex*c*m)", "email LIKE ?", args("admin%ex%c%m")},
+		{"Should be SQL for FilterSubstrings", "(mail=admin*ex*c*m)", "email LIKE ? ESCAPE '!'", args("admin%ex%c%m")},
+		{"Should be SQL for FilterSubstrings without the initial", "(mail=*@corp.com)", "email LIKE ? ESCAPE '!'", args("%@corp.com")},
+		{"Should escape the wildcards of FilterSubstrings", "(cn=100%_*!)", "name LIKE ? ESCAPE '!'", args("100!%!_%!!")},
 	}
 
 	for _, scenery := range scenarios {
@@ -59,6 +61,58 @@ func TestLdapFilterAsQuery(t *testing.T) {
 	}
 }
 
+func TestLdapFilterPushdown(t *testing.T) {
+	scenarios := []struct {
+		description   string
+		input         string
+		expectedExpr  string
+		expectedArgs  []interface{}
+		expectedExact bool
+		expectedName  string
+	}{
+		{"Should push down the supported parts of FilterAnd", "(&(objectClass=person)(mail=admin@corp.com)(!(title=contractor)))", "email=? AND NOT tag=?", args("admin@corp.com", "contractor"), false, "*"},
+		{"Should not push down FilterOr with unsupported parts", "(|(objectClass=person)(mail=admin))", "", nil, false, "*"},
+		{"Should not push down FilterNot with unsupported parts", "(!(&(objectClass=person)(mail=admin)))", "", nil, false, "*"},
+		{"Should push down FilterOr exactly", "(|(uid=alice)(mail=alice@corp.com))", "name=? OR email=?", args("alice", "alice@corp.com"), true, "*"},
+		{"Should get the username of FilterAnd", "(&(objectClass=posixAccount)(uid=alice))", "name=?", args("alice"), false, "alice"},
+		{"Should not get the username of FilterNot", "(!(cn=alice))", "NOT name=?", args("alice"), true, "*"},
+		{"Should push down FilterSubstrings exactly", "(mail=*@corp.com)", "email LIKE ? ESCAPE '!'", args("%@corp.com"), true, "*"},
+		{"Should not push down FilterPresent exactly", "(&(uid=alice)(mail=*))", "name=? AND email IS NOT NULL", args("alice"), false, "alice"},
+		{"Should not push down FilterNot of FilterPresent", "(!(mail=*))", "", nil, false, "*"},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			searchRequest, err := buildLdapSearchRequest(scenery.input)
+			if err != nil {
+				assert.FailNow(t, "Unable to create searchRequest", err)
+			}
+			m, err := message.ReadLDAPMessage(message.NewBytes(0, searchRequest.Bytes()))
+			if err != nil {
+				assert.FailNow(t, "Unable to create searchRequest", err)
+			}
+			req := m.ProtocolOp().(message.SearchRequest)
+
+			assert.Equal(t, scenery.expectedName, getUsername(req.Filter()))
+
+			cond, exact := buildPushdownCondition(req.Filter())
+			assert.Equal(t, scenery.expectedExact, exact)
+			if scenery.expectedExpr == "" {
+				assert.Nil(t, cond)
+				return
+			}
+
+			expr, args, err := builder.ToSQL(cond)
+			if err != nil {
+				assert.FailNow(t, "Unable to build sql", err)
+			}
+
+			assert.Equal(t, scenery.expectedExpr, expr)
+			assert.Equal(t, scenery.expectedArgs, args)
+		})
+	}
+}
+
 func buildLdapSearchRequest(filter string) (*ber.Packet, error) {
 	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Request")
 	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, 1, "MessageID"))
@@ -0,0 +1,450 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	ldap "github.com/forestmgy/ldapserver"
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// the OID of the Password Modify extended operation, see RFC 3062
+const passwordModifyOid = "1.3.6.1.4.1.4203.1.11.1"
+
+type userFieldSetter func(user *object.User, value string)
+
+// ldapModifiableAttributes maps the attributes that can be written by LDAP to the user columns
+var ldapModifiableAttributes = map[string]struct {
+	column string
+	setter userFieldSetter
+}{
+	"mail":            {"email", func(user *object.User, value string) { user.Email = value }},
+	"email":           {"email", func(user *object.User, value string) { user.Email = value }},
+	"mobile":          {"phone", func(user *object.User, value string) { user.Phone = value }},
+	"telephonenumber": {"phone", func(user *object.User, value string) { user.Phone = value }},
+	"displayname":     {"display_name", func(user *object.User, value string) { user.DisplayName = value }},
+	"givenname":       {"first_name", func(user *object.User, value string) { user.FirstName = value }},
+	"sn":              {"last_name", func(user *object.User, value string) { user.LastName = value }},
+	"title":           {"tag", func(user *object.User, value string) { user.Tag = value }},
+}
+
+// getTargetUserId returns the ID of the user identified by the DN, it returns the bound user if the DN is empty
+func getTargetUserId(m *ldap.Message, dn string) (string, int) {
+	if dn == "" {
+		return util.GetId(m.Client.OrgName, m.Client.UserName), ldap.LDAPResultSuccess
+	}
+
+	if isGroupContainerDn(dn) {
+		return "", ldap.LDAPResultUnwillingToPerform
+	}
+
+	name, org, err := getNameAndOrgFromDN(dn)
+	if err != nil {
+		return "", ldap.LDAPResultInvalidDNSyntax
+	}
+	return util.GetId(org, name), ldap.LDAPResultSuccess
+}
+
+// checkUserPassword checks whether the client can change the password of the user to the new password,
+// the user is returned to set the password after the check
+func checkUserPassword(m *ldap.Message, userId string, oldPassword string, newPassword string) (*object.User, int, string) {
+	requestUserId := util.GetId(m.Client.OrgName, m.Client.UserName)
+	hasPermission, err := object.CheckUserPermission(requestUserId, userId, true, "en")
+	if !hasPermission {
+		return nil, ldap.LDAPResultInsufficientAccessRights, err.Error()
+	}
+
+	user, err := object.GetUser(userId)
+	if err != nil {
+		return nil, ldap.LDAPResultOperationsError, err.Error()
+	}
+	if user == nil {
+		return nil, ldap.LDAPResultNoSuchObject, fmt.Sprintf("the user: %s doesn't exist", userId)
+	}
+
+	if newPassword == "" || strings.Contains(newPassword, " ") {
+		return nil, ldap.LDAPResultUnwillingToPerform, "the new password cannot be empty or contain blank space"
+	}
+
+	if oldPassword != "" || !m.Client.IsOrgAdmin {
+		err = object.CheckPassword(user, oldPassword, "en")
+		if err != nil {
+			return nil, ldap.LDAPResultInvalidCredentials, err.Error()
+		}
+	}
+
+	msg := object.CheckPasswordComplexity(user, newPassword)
+	if msg != "" {
+		return nil, ldap.LDAPResultConstraintViolation, msg
+	}
+
+	return user, ldap.LDAPResultSuccess, ""
+}
+
+func setUserPassword(m *ldap.Message, userId string, oldPassword string, newPassword string) (int, string) {
+	user, code, msg := checkUserPassword(m, userId, oldPassword, newPassword)
+	if code != ldap.LDAPResultSuccess {
+		return code, msg
+	}
+
+	user.Password = newPassword
+	_, err := object.SetUserField(user, "password", user.Password)
+	if err != nil {
+		return ldap.LDAPResultOperationsError, err.Error()
+	}
+
+	return ldap.LDAPResultSuccess, ""
+}
+
+// parsePasswordModifyRequest parses the PasswdModifyRequestValue of RFC 3062:
+// SEQUENCE { userIdentity [0] OPTIONAL, oldPasswd [1] OPTIONAL, newPasswd [2] OPTIONAL }
+func parsePasswordModifyRequest(value []byte) (string, string, string, error) {
+	if len(value) == 0 {
+		return "", "", "", nil
+	}
+
+	packet, err := ber.DecodePacketErr(value)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var userIdentity, oldPassword, newPassword string
+	for _, child := range packet.Children {
+		if child.ClassType != ber.ClassContext {
+			continue
+		}
+
+		switch child.Tag {
+		case 0:
+			userIdentity = child.Data.String()
+		case 1:
+			oldPassword = child.Data.String()
+		case 2:
+			newPassword = child.Data.String()
+		}
+	}
+	return userIdentity, oldPassword, newPassword, nil
+}
+
+func handlePasswordModify(w ldap.ResponseWriter, m *ldap.Message) {
+	r := m.GetExtendedRequest()
+	res := ldap.NewExtendedResponse(ldap.LDAPResultSuccess)
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		w.Write(res)
+		return
+	}
+
+	if requireTls && !isTlsConnection(m) {
+		res.SetResultCode(ldap.LDAPResultConfidentialityRequired)
+		w.Write(res)
+		return
+	}
+
+	var value []byte
+	if r.RequestValue() != nil {
+		value = []byte(*r.RequestValue())
+	}
+
+	userIdentity, oldPassword, newPassword, err := parsePasswordModifyRequest(value)
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultProtocolError)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+
+	userId, code := getTargetUserId(m, userIdentity)
+	if code != ldap.LDAPResultSuccess {
+		res.SetResultCode(code)
+		w.Write(res)
+		return
+	}
+
+	code, msg := setUserPassword(m, userId, oldPassword, newPassword)
+	if code != ldap.LDAPResultSuccess {
+		log.Printf("handlePasswordModify() failed for user: %s, %s", userId, msg)
+		res.SetResultCode(code)
+		res.SetDiagnosticMessage(msg)
+	}
+	w.Write(res)
+}
+
+func handleModify(w ldap.ResponseWriter, m *ldap.Message) {
+	r := m.GetModifyRequest()
+	res := ldap.NewModifyResponse(ldap.LDAPResultSuccess)
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		w.Write(res)
+		return
+	}
+
+	userId, code := getTargetUserId(m, string(r.Object()))
+	if code != ldap.LDAPResultSuccess {
+		res.SetResultCode(code)
+		w.Write(res)
+		return
+	}
+
+	requestUserId := util.GetId(m.Client.OrgName, m.Client.UserName)
+	hasPermission, err := object.CheckUserPermission(requestUserId, userId, true, "en")
+	if !hasPermission {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		res.SetDiagnosticMessage(err.Error())
+		w.Write(res)
+		return
+	}
+
+	oldUser, err := object.GetUser(userId)
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultOperationsError)
+		w.Write(res)
+		return
+	}
+	if oldUser == nil {
+		res.SetResultCode(ldap.LDAPResultNoSuchObject)
+		w.Write(res)
+		return
+	}
+
+	user := *oldUser
+	columns := []string{}
+	isPasswordModified := false
+	oldPassword, newPassword := "", ""
+	for _, change := range r.Changes() {
+		modification := change.Modification()
+		attribute := strings.ToLower(string(modification.Type_()))
+
+		value := ""
+		if len(modification.Vals()) != 0 {
+			value = string(modification.Vals()[0])
+		}
+
+		// the password is changed by "replace", or by "delete" with the old value and "add" with the new value
+		if attribute == "userpassword" {
+			isPasswordModified = true
+			if change.Operation() == ldap.ModifyRequestChangeOperationDelete {
+				oldPassword = value
+			} else {
+				newPassword = value
+			}
+			continue
+		}
+
+		modifiable, ok := ldapModifiableAttributes[attribute]
+		if !ok {
+			res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+			res.SetDiagnosticMessage(fmt.Sprintf("attribute %s cannot be modified", modification.Type_()))
+			w.Write(res)
+			return
+		}
+
+		if change.Operation() == ldap.ModifyRequestChangeOperationDelete {
+			value = ""
+		}
+		modifiable.setter(&user, value)
+		if !util.InSlice(columns, modifiable.column) {
+			columns = append(columns, modifiable.column)
+		}
+	}
+
+	// the password can only be changed, a user can't be left without a password
+	if isPasswordModified && newPassword == "" {
+		res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+		res.SetDiagnosticMessage("userPassword cannot be deleted without adding or replacing it with a new password")
+		w.Write(res)
+		return
+	}
+
+	// all the changes are checked before any of them is applied
+	if len(columns) != 0 {
+		if msg := object.CheckUpdateUser(oldUser, &user, "en"); msg != "" {
+			res.SetResultCode(ldap.LDAPResultConstraintViolation)
+			res.SetDiagnosticMessage(msg)
+			w.Write(res)
+			return
+		}
+
+		if pass, msg := object.CheckPermissionForUpdateUser(oldUser, &user, m.Client.IsOrgAdmin, "en"); !pass {
+			res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+			res.SetDiagnosticMessage(msg)
+			w.Write(res)
+			return
+		}
+	}
+
+	// the password is checked with the other changes, but set after the attributes are updated, so that
+	// a failed attribute update doesn't leave the password changed
+	var passwordUser *object.User
+	if newPassword != "" {
+		if requireTls && !isTlsConnection(m) {
+			res.SetResultCode(ldap.LDAPResultConfidentialityRequired)
+			w.Write(res)
+			return
+		}
+
+		var code int
+		var msg string
+		passwordUser, code, msg = checkUserPassword(m, userId, oldPassword, newPassword)
+		if code != ldap.LDAPResultSuccess {
+			res.SetResultCode(code)
+			res.SetDiagnosticMessage(msg)
+			w.Write(res)
+			return
+		}
+	}
+
+	if len(columns) != 0 {
+		affected, err := object.UpdateUser(userId, &user, columns, m.Client.IsOrgAdmin)
+		if err != nil {
+			res.SetResultCode(ldap.LDAPResultOperationsError)
+			res.SetDiagnosticMessage(err.Error())
+			w.Write(res)
+			return
+		}
+
+		if affected {
+			err = object.UpdateUserToOriginalDatabase(&user)
+			if err != nil {
+				log.Printf("handleModify() error: %s", err.Error())
+			}
+		}
+	}
+
+	if passwordUser != nil {
+		passwordUser.Password = newPassword
+		_, err = object.SetUserField(passwordUser, "password", passwordUser.Password)
+		if err != nil {
+			res.SetResultCode(ldap.LDAPResultOperationsError)
+			res.SetDiagnosticMessage(err.Error())
+			w.Write(res)
+			return
+		}
+	}
+
+	w.Write(res)
+}
+
+// handleAdd creates a user in the organization of the DN, only the admins of the organization can add users
+func handleAdd(w ldap.ResponseWriter, m *ldap.Message) {
+	r := m.GetAddRequest()
+	res := ldap.NewAddResponse(ldap.LDAPResultSuccess)
+	if !m.Client.IsAuthenticated {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		w.Write(res)
+		return
+	}
+
+	userId, code := getTargetUserId(m, string(r.Entry()))
+	if code != ldap.LDAPResultSuccess {
+		res.SetResultCode(code)
+		w.Write(res)
+		return
+	}
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+	if !m.Client.IsGlobalAdmin && !(m.Client.IsOrgAdmin && owner == m.Client.OrgName) {
+		res.SetResultCode(ldap.LDAPResultInsufficientAccessRights)
+		w.Write(res)
+		return
+	}
+
+	if msg := object.CheckUsername(name, "en"); msg != "" {
+		res.SetResultCode(ldap.LDAPResultConstraintViolation)
+		res.SetDiagnosticMessage(msg)
+		w.Write(res)
+		return
+	}
+
+	oldUser, err := object.GetUser(userId)
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultOperationsError)
+		w.Write(res)
+		return
+	}
+	if oldUser != nil {
+		res.SetResultCode(ldap.LDAPResultEntryAlreadyExists)
+		w.Write(res)
+		return
+	}
+
+	user := &object.User{
+		Owner:       owner,
+		Name:        name,
+		CreatedTime: util.GetCurrentTime(),
+		Type:        "normal-user",
+		DisplayName: name,
+		Properties:  map[string]string{},
+	}
+	password := ""
+	for _, attribute := range r.Attributes() {
+		if len(attribute.Vals()) == 0 {
+			continue
+		}
+
+		value := string(attribute.Vals()[0])
+		key := strings.ToLower(string(attribute.Type_()))
+		switch key {
+		case "objectclass":
+			// the object classes of the users are fixed
+		case "cn", "uid":
+			if value != name {
+				res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+				res.SetDiagnosticMessage(fmt.Sprintf("attribute %s should be the same as the name in the DN", attribute.Type_()))
+				w.Write(res)
+				return
+			}
+		case "userpassword":
+			password = value
+		default:
+			modifiable, ok := ldapModifiableAttributes[key]
+			if !ok {
+				res.SetResultCode(ldap.LDAPResultUnwillingToPerform)
+				res.SetDiagnosticMessage(fmt.Sprintf("attribute %s cannot be added", attribute.Type_()))
+				w.Write(res)
+				return
+			}
+			modifiable.setter(user, value)
+		}
+	}
+
+	if password != "" {
+		if requireTls && !isTlsConnection(m) {
+			res.SetResultCode(ldap.LDAPResultConfidentialityRequired)
+			w.Write(res)
+			return
+		}
+
+		if msg := object.CheckPasswordComplexity(user, password); msg != "" {
+			res.SetResultCode(ldap.LDAPResultConstraintViolation)
+			res.SetDiagnosticMessage(msg)
+			w.Write(res)
+			return
+		}
+		user.Password = password
+	}
+
+	_, err = object.AddUser(user)
+	if err != nil {
+		res.SetResultCode(ldap.LDAPResultOperationsError)
+		res.SetDiagnosticMessage(err.Error())
+	}
+	w.Write(res)
+}
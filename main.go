@@ -45,6 +45,10 @@ func main() {
 	object.InitCasvisorConfig()
 
 	util.SafeGoroutine(func() { object.RunSyncUsersJob() })
+	util.SafeGoroutine(func() { object.RunCertRotationJob() })
+	util.SafeGoroutine(func() { object.RunRecordRetentionJob() })
+	util.SafeGoroutine(func() { object.RunWebhookDeliveryJob() })
+	util.SafeGoroutine(func() { object.RunProvisioningReconcileJob() })
 
 	// beego.DelStaticPath("/static")
 	// beego.SetStaticPath("/static", "web/build/static")
@@ -16,6 +16,7 @@ package object
 
 import (
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -91,6 +92,24 @@ type Application struct {
 	FormSideHtml         string     `xorm:"mediumtext" json:"formSideHtml"`
 	FormBackgroundUrl    string     `xorm:"varchar(200)" json:"formBackgroundUrl"`
 
+	TokenExchangeAudiences []string `xorm:"varchar(1000)" json:"tokenExchangeAudiences"`
+
+	FrontchannelLogoutUri string `xorm:"varchar(200)" json:"frontchannelLogoutUri"`
+	BackchannelLogoutUri  string `xorm:"varchar(200)" json:"backchannelLogoutUri"`
+
+	RequirePushedAuthorizationRequests bool   `json:"requirePushedAuthorizationRequests"`
+	ClientJwksUri                      string `xorm:"varchar(200)" json:"clientJwksUri"`
+	ClientJwks                         string `xorm:"mediumtext" json:"clientJwks"`
+
+	TokenEndpointAuthMethod string `xorm:"varchar(100)" json:"tokenEndpointAuthMethod"`
+	TlsClientAuthSubjectDn  string `xorm:"varchar(500)" json:"tlsClientAuthSubjectDn"`
+
+	EnableDpop  bool `json:"enableDpop"`
+	RequireDpop bool `json:"requireDpop"`
+
+	EnableScim bool   `json:"enableScim"`
+	ScimToken  string `xorm:"varchar(100) index" json:"scimToken"`
+
 	FailedSigninLimit      int `json:"failedSigninLimit"`
 	FailedSigninfrozenTime int `json:"failedSigninfrozenTime"`
 }
@@ -290,6 +309,55 @@ func GetApplicationByClientId(clientId string) (*Application, error) {
 	}
 }
 
+// GetApplicationByScimToken returns the application with SCIM enabled that the bearer token is bound to,
+// the token is the SCIM token of the application, or an access token issued to the application by the
+// client credentials grant. The SCIM requests with the token are scoped to the organization of the application.
+// The access token bound to a client certificate or a DPoP key is only accepted with the key it is bound to.
+func GetApplicationByScimToken(bearerToken string, request *http.Request) (*Application, error) {
+	if bearerToken == "" {
+		return nil, nil
+	}
+
+	application := Application{}
+	existed, err := ormer.Engine.Where("scim_token = ? and enable_scim = ?", bearerToken, true).Get(&application)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		return &application, nil
+	}
+
+	token, err := GetTokenByAccessToken(bearerToken)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.IsRevoked {
+		return nil, nil
+	}
+	if isExpired, _ := util.IsTokenExpired(token.CreatedTime, token.ExpiresIn); isExpired {
+		return nil, nil
+	}
+	if CheckTokenConfirmation(token, request) != nil {
+		return nil, nil
+	}
+
+	// only the client credentials tokens are issued to the application itself, the tokens of a user named
+	// after the application must not be accepted
+	if token.GrantType != "client_credentials" {
+		return nil, nil
+	}
+
+	tokenApplication, err := getApplication(token.Owner, token.Application)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenApplication == nil || !tokenApplication.EnableScim {
+		return nil, nil
+	}
+	return tokenApplication, nil
+}
+
 func GetApplication(id string) (*Application, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
 	return getApplication(owner, name)
@@ -314,6 +382,9 @@ func GetMaskedApplication(application *Application, userId string) *Application
 	if application.ClientSecret != "" {
 		application.ClientSecret = "***"
 	}
+	if application.ScimToken != "" {
+		application.ScimToken = "***"
+	}
 
 	if application.OrganizationObj != nil {
 		if application.OrganizationObj.MasterPassword != "" {
@@ -328,6 +399,9 @@ func GetMaskedApplication(application *Application, userId string) *Application
 		if application.OrganizationObj.PasswordType != "" {
 			application.OrganizationObj.PasswordType = "***"
 		}
+		if application.OrganizationObj.TargetPasswordType != "" {
+			application.OrganizationObj.TargetPasswordType = "***"
+		}
 		if application.OrganizationObj.PasswordSalt != "" {
 			application.OrganizationObj.PasswordSalt = "***"
 		}
@@ -410,15 +484,32 @@ func UpdateApplication(id string, application *Application) (bool, error) {
 		providerItem.Provider = nil
 	}
 
+	// the SCIM token is regenerated after being cleared, so that a leaked token can be revoked
+	if application.EnableScim && application.ScimToken == "" {
+		application.ScimToken = util.GenerateClientSecret()
+	}
+
 	session := ormer.Engine.ID(core.PK{owner, name}).AllCols()
 	if application.ClientSecret == "***" {
 		session.Omit("client_secret")
 	}
+	if application.ScimToken == "***" {
+		session.Omit("scim_token")
+	}
 	affected, err := session.Update(application)
 	if err != nil {
 		return false, err
 	}
 
+	if affected != 0 {
+		newApplication, err := getApplication(owner, application.Name)
+		if err != nil {
+			return false, err
+		}
+
+		sendWebhookEvent(oldApplication.Organization, "update-application", oldApplication.GetId(), oldApplication, newApplication)
+	}
+
 	return affected != 0, nil
 }
 
@@ -435,6 +526,9 @@ func AddApplication(application *Application) (bool, error) {
 	if application.ClientSecret == "" {
 		application.ClientSecret = util.GenerateClientSecret()
 	}
+	if application.EnableScim && application.ScimToken == "" {
+		application.ScimToken = util.GenerateClientSecret()
+	}
 
 	app, err := GetApplicationByClientId(application.ClientId)
 	if err != nil {
@@ -454,6 +548,10 @@ func AddApplication(application *Application) (bool, error) {
 		return false, nil
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(application.Organization, "add-application", application.GetId(), nil, application)
+	}
+
 	return affected != 0, nil
 }
 
@@ -462,11 +560,20 @@ func DeleteApplication(application *Application) (bool, error) {
 		return false, nil
 	}
 
+	oldApplication, err := getApplication(application.Owner, application.Name)
+	if err != nil {
+		return false, err
+	}
+
 	affected, err := ormer.Engine.ID(core.PK{application.Owner, application.Name}).Delete(&Application{})
 	if err != nil {
 		return false, err
 	}
 
+	if affected != 0 && oldApplication != nil {
+		sendWebhookEvent(oldApplication.Organization, "delete-application", oldApplication.GetId(), oldApplication, nil)
+	}
+
 	return affected != 0, nil
 }
 
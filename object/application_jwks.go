@@ -0,0 +1,101 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// getClientJwks returns the public keys of the client, which are either configured inline
+// or fetched from the JWKS uri of the client
+func getClientJwks(application *Application) (*jose.JSONWebKeySet, error) {
+	data := []byte(application.ClientJwks)
+	if application.ClientJwks == "" {
+		if application.ClientJwksUri == "" {
+			return nil, fmt.Errorf("the JWKS of the application: %s is empty", application.GetId())
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(application.ClientJwksUri)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to get the JWKS from: %s, status: %s", application.ClientJwksUri, resp.Status)
+		}
+
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jwks := &jose.JSONWebKeySet{}
+	err := json.Unmarshal(data, jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwks, nil
+}
+
+// getClientJwtKeyFunc returns the key function to verify the JWTs signed by the client: the asymmetric
+// algorithms use the JWKS of the client, and the HMAC algorithms use the client secret
+func getClientJwtKeyFunc(application *Application) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg := token.Method.Alg()
+		if strings.HasPrefix(alg, "HS") {
+			if application.ClientSecret == "" {
+				return nil, fmt.Errorf("the client secret of the application: %s is empty", application.GetId())
+			}
+			return []byte(application.ClientSecret), nil
+		}
+
+		jwks, err := getClientJwks(application)
+		if err != nil {
+			return nil, err
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range jwks.Keys {
+			if kid != "" && key.KeyID != kid {
+				continue
+			}
+			if key.Use != "" && key.Use != "sig" {
+				continue
+			}
+			if key.Algorithm != "" && key.Algorithm != alg {
+				continue
+			}
+			if !key.IsPublic() {
+				continue
+			}
+
+			return key.Key, nil
+		}
+
+		return nil, fmt.Errorf("no key in the JWKS of the application: %s matches the kid: %s and alg: %s", application.GetId(), kid, alg)
+	}
+}
@@ -35,6 +35,12 @@ type Cert struct {
 
 	Certificate string `xorm:"mediumtext" json:"certificate"`
 	PrivateKey  string `xorm:"mediumtext" json:"privateKey"`
+
+	State              string `xorm:"varchar(100)" json:"state"`
+	StateChangedTime   string `xorm:"varchar(100)" json:"stateChangedTime"`
+	Successor          string `xorm:"varchar(100)" json:"successor"`
+	EnableAutoRotation bool   `json:"enableAutoRotation"`
+	RotateInDays       int    `json:"rotateInDays"`
 }
 
 func GetMaskedCert(cert *Cert) *Cert {
@@ -206,7 +212,7 @@ func (p *Cert) GetId() string {
 
 func (p *Cert) populateContent() error {
 	if p.Certificate == "" || p.PrivateKey == "" {
-		certificate, privateKey, err := generateRsaKeys(p.BitSize, p.ExpireInYears, p.Name, p.Owner)
+		certificate, privateKey, err := generateKeys(p.CryptoAlgorithm, p.BitSize, p.ExpireInYears, p.Name, p.Owner)
 		if err != nil {
 			return err
 		}
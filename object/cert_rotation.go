@@ -0,0 +1,329 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/xorm-io/core"
+)
+
+// The lifecycle of a cert during key rotation:
+// Pending: the successor is published in the JWKS so that relying parties can cache it, but not used for signing
+// Active: the cert is used for signing and published in the JWKS
+// Retiring: the cert is no longer used for signing, but is still published until the tokens signed by it have expired
+// Retired: the cert is neither published nor accepted
+const (
+	CertStatePending  = "Pending"
+	CertStateActive   = "Active"
+	CertStateRetiring = "Retiring"
+	CertStateRetired  = "Retired"
+
+	certPendingHours     = 24
+	certMaxRotationChain = 10
+	certRotationInterval = time.Hour
+)
+
+// the successors are named after the base name of the cert with the time of the rotation
+var certRotationSuffixRegex = regexp.MustCompile(`_\d{14}$`)
+
+// certs created before the key rotation was introduced have an empty state and are active
+func (p *Cert) isActive() bool {
+	return p.State == "" || p.State == CertStateActive
+}
+
+func (p *Cert) isPublished() bool {
+	return p.State != CertStateRetired
+}
+
+func (p *Cert) getExpireTime() (time.Time, error) {
+	block, _ := pem.Decode([]byte(p.Certificate))
+	if block == nil {
+		return time.Time{}, fmt.Errorf("the certificate of the cert: %s is not a valid PEM", p.GetId())
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return certificate.NotAfter, nil
+}
+
+func (p *Cert) isStateOlderThan(hours int) bool {
+	stateChangedTime, err := time.Parse(time.RFC3339, p.StateChangedTime)
+	if err != nil {
+		return true
+	}
+
+	return time.Now().After(stateChangedTime.Add(time.Duration(hours) * time.Hour))
+}
+
+func updateCertState(cert *Cert, state string) error {
+	cert.State = state
+	cert.StateChangedTime = util.GetCurrentTime()
+	_, err := ormer.Engine.ID(core.PK{cert.Owner, cert.Name}).Cols("state", "state_changed_time").Update(cert)
+	return err
+}
+
+// getCertBaseName strips the rotation time from the name of the cert, so that the name of the successor
+// doesn't grow with each rotation
+func getCertBaseName(name string) string {
+	return certRotationSuffixRegex.ReplaceAllString(name, "")
+}
+
+func getPredecessorCert(cert *Cert) (*Cert, error) {
+	predecessor := Cert{Owner: cert.Owner, Successor: cert.Name}
+	existed, err := ormer.Engine.Get(&predecessor)
+	if err != nil {
+		return nil, err
+	}
+
+	if !existed {
+		return nil, nil
+	}
+
+	return &predecessor, nil
+}
+
+// RotateCert generates the successor of the cert in the pending state. The successor will be activated
+// by the rotation job after it has been published for a while, or immediately if activate is true.
+func RotateCert(cert *Cert, activate bool) (*Cert, error) {
+	successor, err := getCert(cert.Owner, cert.Successor)
+	if err != nil {
+		return nil, err
+	}
+
+	if successor == nil {
+		successor = &Cert{
+			Owner:              cert.Owner,
+			Name:               fmt.Sprintf("%s_%s", getCertBaseName(cert.Name), util.GenerateSimpleTimeId()),
+			CreatedTime:        util.GetCurrentTime(),
+			DisplayName:        cert.DisplayName,
+			Scope:              cert.Scope,
+			Type:               cert.Type,
+			CryptoAlgorithm:    cert.CryptoAlgorithm,
+			BitSize:            cert.BitSize,
+			ExpireInYears:      cert.ExpireInYears,
+			State:              CertStatePending,
+			StateChangedTime:   util.GetCurrentTime(),
+			EnableAutoRotation: cert.EnableAutoRotation,
+			RotateInDays:       cert.RotateInDays,
+		}
+		_, err = AddCert(successor)
+		if err != nil {
+			return nil, err
+		}
+
+		cert.Successor = successor.Name
+		_, err = ormer.Engine.ID(core.PK{cert.Owner, cert.Name}).Cols("successor").Update(cert)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if activate && successor.State == CertStatePending {
+		err = activateCert(successor, cert)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return successor, nil
+}
+
+// GetActiveCert returns the cert of the id, or its active successor if the cert has been rotated, so that
+// the certs configured by id like "ldapCertId" and "radiusCertId" follow the rotation
+func GetActiveCert(id string) (*Cert, error) {
+	cert, err := GetCert(id)
+	if err != nil || cert == nil {
+		return cert, err
+	}
+
+	for i := 0; i < certMaxRotationChain; i++ {
+		if cert.isActive() || cert.State == CertStatePending || cert.Successor == "" {
+			return cert, nil
+		}
+
+		successor, err := getCert(cert.Owner, cert.Successor)
+		if err != nil {
+			return nil, err
+		}
+		if successor == nil {
+			return cert, nil
+		}
+
+		cert = successor
+	}
+
+	return cert, nil
+}
+
+// activateCert makes the successor sign the new tokens of the applications that used the predecessor,
+// while the predecessor keeps verifying the tokens it has signed
+func activateCert(successor *Cert, predecessor *Cert) error {
+	err := updateCertState(successor, CertStateActive)
+	if err != nil {
+		return err
+	}
+
+	if predecessor == nil {
+		return nil
+	}
+
+	err = updateCertState(predecessor, CertStateRetiring)
+	if err != nil {
+		return err
+	}
+
+	return certChangeTrigger(predecessor.Name, successor.Name)
+}
+
+// getCertRetiringHours returns the longest token lifetime of the applications signing with the successor,
+// after which no valid token signed by the retiring cert can exist any more
+func getCertRetiringHours(cert *Cert) (int, error) {
+	applications := []*Application{}
+	err := ormer.Engine.Where("cert = ?", cert.Successor).Find(&applications)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0
+	for _, application := range applications {
+		if application.ExpireInHours > res {
+			res = application.ExpireInHours
+		}
+		if application.RefreshExpireInHours > res {
+			res = application.RefreshExpireInHours
+		}
+	}
+	return res, nil
+}
+
+func updateCertRotation(cert *Cert) error {
+	switch {
+	case cert.isActive():
+		if !cert.EnableAutoRotation || cert.Successor != "" {
+			return nil
+		}
+
+		expireTime, err := cert.getExpireTime()
+		if err != nil {
+			return err
+		}
+
+		if time.Now().AddDate(0, 0, cert.RotateInDays).After(expireTime) {
+			_, err = RotateCert(cert, false)
+			return err
+		}
+	case cert.State == CertStatePending:
+		if !cert.isStateOlderThan(certPendingHours) {
+			return nil
+		}
+
+		predecessor, err := getPredecessorCert(cert)
+		if err != nil {
+			return err
+		}
+
+		return activateCert(cert, predecessor)
+	case cert.State == CertStateRetiring:
+		retiringHours, err := getCertRetiringHours(cert)
+		if err != nil {
+			return err
+		}
+
+		if cert.isStateOlderThan(retiringHours) {
+			return updateCertState(cert, CertStateRetired)
+		}
+	}
+
+	return nil
+}
+
+func RunCertRotationJob() {
+	ticker := time.NewTicker(certRotationInterval)
+	for ; true; <-ticker.C {
+		// only one instance rotates the certs, otherwise each of them would create a successor
+		locked, err := tryLockJob("cert-rotation", certRotationInterval)
+		if err != nil {
+			fmt.Printf("RunCertRotationJob() error: %s\n", err.Error())
+			continue
+		}
+		if !locked {
+			continue
+		}
+
+		certs, err := GetGlobalCerts()
+		if err != nil {
+			fmt.Printf("RunCertRotationJob() error: %s\n", err.Error())
+			continue
+		}
+
+		for _, cert := range certs {
+			err = updateCertRotation(cert)
+			if err != nil {
+				fmt.Printf("RunCertRotationJob() error for cert: %s, %s\n", cert.GetId(), err.Error())
+			}
+		}
+	}
+}
+
+// getCertByKid returns the cert that signed the token, which is either the cert of the application or
+// one of its predecessors that has not been retired yet
+func getCertByKid(token string, cert *Cert) (*Cert, error) {
+	t, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" || kid == cert.Name {
+		return cert, nil
+	}
+
+	kidCert, err := getCert(cert.Owner, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if kidCert == nil || !kidCert.isPublished() {
+		return nil, fmt.Errorf("the kid: %s of the token is invalid or has been retired", kid)
+	}
+
+	successor := kidCert
+	for i := 0; i < certMaxRotationChain && successor.Successor != ""; i++ {
+		if successor.Successor == cert.Name {
+			return kidCert, nil
+		}
+
+		successor, err = getCert(cert.Owner, successor.Successor)
+		if err != nil {
+			return nil, err
+		}
+
+		if successor == nil {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("the kid: %s of the token doesn't match the cert: %s", kid, cert.Name)
+}
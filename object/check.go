@@ -15,6 +15,7 @@
 package object
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -68,7 +69,8 @@ func CheckUserSignup(application *Application, organization *Organization, form
 	}
 
 	if application.IsSignupItemVisible("Password") {
-		msg := CheckPasswordComplexityByOrg(organization, form.Password)
+		user := &User{Owner: organization.Name, Name: form.Username, Email: form.Email, DisplayName: form.Name}
+		msg := checkUserPasswordComplexity(form.Password, user, organization)
 		if msg != "" {
 			return msg
 		}
@@ -204,6 +206,12 @@ func CheckPassword(user *User, password string, lang string, options ...bool) er
 		}
 
 		if credManager.IsPasswordCorrect(password, user.Password, user.PasswordSalt, organization.PasswordSalt) {
+			// the password is migrated again at the next sign-in if the re-hash fails
+			err = migrateUserPassword(user, organization, password, passwordType)
+			if err != nil {
+				fmt.Printf("migrateUserPassword() error for user: %s, %s\n", user.GetId(), err.Error())
+			}
+
 			return resetUserSigninErrorTimes(user)
 		}
 
@@ -220,7 +228,7 @@ func CheckPasswordComplexityByOrg(organization *Organization, password string) s
 
 func CheckPasswordComplexity(user *User, password string) string {
 	organization, _ := GetOrganizationByUser(user)
-	return CheckPasswordComplexityByOrg(organization, password)
+	return checkUserPasswordComplexity(password, user, organization)
 }
 
 func checkLdapUserPassword(user *User, password string, lang string) error {
@@ -312,6 +320,60 @@ func CheckUserPassword(organization string, username string, password string, la
 	return user, nil
 }
 
+// CheckUserPasswordByVerifier checks the password of the user for the challenge-response protocols like MS-CHAPv2,
+// where the password isn't sent by the client, so the response is verified against the NT hash of the password,
+// which is computed from the plain password, or kept for the hashed passwords if the organization enables it
+func CheckUserPasswordByVerifier(organization string, username string, lang string, verify func(ntPasswordHash []byte) bool) (*User, error) {
+	user, err := GetUserByFields(organization, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.IsDeleted {
+		return nil, fmt.Errorf(i18n.Translate(lang, "general:The user: %s doesn't exist"), util.GetId(organization, username))
+	}
+
+	if user.IsForbidden {
+		return nil, fmt.Errorf(i18n.Translate(lang, "check:The user is forbidden to sign in, please contact the administrator"))
+	}
+
+	err = checkSigninErrorTimes(user, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationObj, err := GetOrganizationByUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if organizationObj == nil {
+		return nil, fmt.Errorf(i18n.Translate(lang, "check:Organization does not exist"))
+	}
+
+	passwordType := user.PasswordType
+	if passwordType == "" {
+		passwordType = organizationObj.PasswordType
+	}
+	var ntPasswordHash []byte
+	if user.Ldap == "" && passwordType == "plain" {
+		ntPasswordHash = util.GetNtPasswordHash(user.Password)
+	} else if user.Ldap == "" && user.NtPasswordHash != "" {
+		ntPasswordHash, err = hex.DecodeString(user.NtPasswordHash)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf(i18n.Translate(lang, "check:unsupported password type: %s"), passwordType)
+	}
+
+	if !verify(ntPasswordHash) {
+		return nil, recordSigninErrorInfo(user, lang)
+	}
+
+	return user, resetUserSigninErrorTimes(user)
+}
+
 func CheckUserPermission(requestUserId, userId string, strict bool, lang string) (bool, error) {
 	if requestUserId == "" {
 		return false, fmt.Errorf(i18n.Translate(lang, "general:Please login first"))
@@ -470,7 +532,7 @@ func CheckUpdateUser(oldUser, user *User, lang string) string {
 	return ""
 }
 
-func CheckToEnableCaptcha(application *Application, organization, username string) (bool, error) {
+func CheckToEnableCaptcha(application *Application, organization, username string, clientIp string) (bool, error) {
 	if len(application.Providers) == 0 {
 		return false, nil
 	}
@@ -492,7 +554,11 @@ func CheckToEnableCaptcha(application *Application, organization, username strin
 				} else {
 					failedSigninLimit = application.FailedSigninLimit
 				}
-				return user != nil && user.SigninWrongTimes >= failedSigninLimit, nil
+				if user != nil && user.SigninWrongTimes >= failedSigninLimit {
+					return true, nil
+				}
+				// escalate to the CAPTCHA when the sign-ins from the IP or for the user keep failing
+				return IsSigninCaptchaRequired(clientIp, organization, username), nil
 			}
 			return providerItem.Rule == "Always", nil
 		}
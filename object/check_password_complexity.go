@@ -15,7 +15,16 @@
 package object
 
 import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/cred"
 )
 
 type ValidatorFunc func(password string) string
@@ -68,6 +77,110 @@ func isValidOption_NoRepeat(password string) string {
 	return ""
 }
 
+// isPasswordBreached looks up the password in a local copy of the HIBP-style breach corpus, the SHA-1 hash
+// of the password is split into a 5-character prefix that names the range file and a 35-character suffix
+// that is searched in the "<suffix>:<count>" lines of that file, so the password itself is never stored
+func isPasswordBreached(corpusDir string, password string) (bool, error) {
+	hash := sha1.Sum([]byte(password))
+	hashHex := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := hashHex[:5], hashHex[5:]
+
+	var file *os.File
+	var err error
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		file, err = os.Open(filepath.Join(corpusDir, name))
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	if file == nil {
+		return false, nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		tokens := strings.SplitN(line, ":", 2)
+		if !strings.EqualFold(tokens[0], suffix) {
+			continue
+		}
+		// the padded entries of the range files have a count of 0
+		if len(tokens) == 2 && strings.TrimSpace(tokens[1]) == "0" {
+			return false, nil
+		}
+		return true, nil
+	}
+	return false, scanner.Err()
+}
+
+func isValidOption_NoBreached(password string) string {
+	corpusDir := conf.GetConfigString("breachedPasswordPath")
+	if corpusDir == "" {
+		return ""
+	}
+
+	breached, err := isPasswordBreached(corpusDir, password)
+	if err != nil {
+		return err.Error()
+	}
+	if breached {
+		return "The password has appeared in a data breach, please choose a different password"
+	}
+	return ""
+}
+
+func isValidOption_NoPersonalInfo(password string, user *User) string {
+	lowerPassword := strings.ToLower(password)
+	values := []string{user.Name, user.Email, user.DisplayName}
+	if index := strings.Index(user.Email, "@"); index > 0 {
+		values = append(values, user.Email[:index])
+	}
+
+	for _, value := range values {
+		// too short values like initials would reject too many passwords
+		if len(value) < 3 {
+			continue
+		}
+		if strings.Contains(lowerPassword, strings.ToLower(value)) {
+			return "The password must not contain the username, email or display name"
+		}
+	}
+	return ""
+}
+
+func isValidOption_NoReuse(password string, user *User, organization *Organization) string {
+	if user.Password != "" {
+		passwordType := user.PasswordType
+		if passwordType == "" {
+			passwordType = organization.PasswordType
+		}
+		credManager := cred.GetCredManager(passwordType)
+		if credManager != nil && credManager.IsPasswordCorrect(password, user.Password, user.PasswordSalt, organization.PasswordSalt) {
+			return "The password must not be the same as the recent passwords"
+		}
+	}
+
+	for i, entry := range user.PasswordHistory {
+		if i >= organization.getPasswordHistoryCount()-1 {
+			break
+		}
+
+		tokens := strings.SplitN(entry, ":", 2)
+		if len(tokens) != 2 {
+			continue
+		}
+		credManager := cred.GetCredManager(tokens[0])
+		if credManager != nil && credManager.IsPasswordCorrect(password, tokens[1], user.PasswordSalt, organization.PasswordSalt) {
+			return "The password must not be the same as the recent passwords"
+		}
+	}
+	return ""
+}
+
 func checkPasswordComplexity(password string, options []string) string {
 	if len(password) == 0 {
 		return "Please input your password!"
@@ -83,6 +196,7 @@ func checkPasswordComplexity(password string, options []string) string {
 		"Aa123":       isValidOption_Aa123,
 		"SpecialChar": isValidOption_SpecialChar,
 		"NoRepeat":    isValidOption_NoRepeat,
+		"NoBreached":  isValidOption_NoBreached,
 	}
 
 	for _, option := range options {
@@ -96,3 +210,25 @@ func checkPasswordComplexity(password string, options []string) string {
 	}
 	return ""
 }
+
+// checkUserPasswordComplexity runs the options that need the user in addition to checkPasswordComplexity,
+// the user can be a new user that has not been saved yet
+func checkUserPasswordComplexity(password string, user *User, organization *Organization) string {
+	errorMsg := checkPasswordComplexity(password, organization.PasswordOptions)
+	if errorMsg != "" {
+		return errorMsg
+	}
+
+	for _, option := range organization.PasswordOptions {
+		switch option {
+		case "NoPersonalInfo":
+			errorMsg = isValidOption_NoPersonalInfo(password, user)
+		case "NoReuse":
+			errorMsg = isValidOption_NoReuse(password, user, organization)
+		}
+		if errorMsg != "" {
+			return errorMsg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsPasswordBreached(t *testing.T) {
+	corpusDir := t.TempDir()
+
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	err := os.WriteFile(filepath.Join(corpusDir, "5BAA6.txt"), []byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SHA-1 of "123456" is 7C4A8D09CA3762AF61E59520943DC26494F8941B, the padded entry has a count of 0
+	err = os.WriteFile(filepath.Join(corpusDir, "7C4A8"), []byte("D09CA3762AF61E59520943DC26494F8941B:0\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		password string
+		expected bool
+	}{
+		{"password", true},
+		{"123456", false},
+		{"Casdoor-1234", false},
+	}
+
+	for _, scenario := range scenarios {
+		breached, err := isPasswordBreached(corpusDir, scenario.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if breached != scenario.expected {
+			t.Errorf("isPasswordBreached(%s) = %v, expected %v", scenario.password, breached, scenario.expected)
+		}
+	}
+}
+
+func TestIsValidOptionNoPersonalInfo(t *testing.T) {
+	user := &User{Name: "alice", Email: "alice.smith@example.com", DisplayName: "Al"}
+
+	scenarios := []struct {
+		password string
+		valid    bool
+	}{
+		{"Alice2023!", false},
+		{"xALICE.SMITHx", false},
+		{"Alpine2023!", true},
+		{"Bob2023!", true},
+	}
+
+	for _, scenario := range scenarios {
+		errorMsg := isValidOption_NoPersonalInfo(scenario.password, user)
+		if (errorMsg == "") != scenario.valid {
+			t.Errorf("isValidOption_NoPersonalInfo(%s) = %s, expected valid: %v", scenario.password, errorMsg, scenario.valid)
+		}
+	}
+}
+
+func TestGetPasswordHistory(t *testing.T) {
+	organization := &Organization{PasswordType: "plain", PasswordHistoryCount: 3}
+	user := &User{Password: "123456", PasswordHistory: []string{"salt:1", "salt:2", "salt:3"}}
+
+	history := getPasswordHistory(user, organization)
+	if len(history) != 2 {
+		t.Fatalf("len(history) = %d, expected 2", len(history))
+	}
+	if history[0] == "plain:123456" || history[1] != "salt:1" {
+		t.Errorf("history = %v, the plain password should be hashed and prepended", history)
+	}
+
+	user.Password = "654321"
+	user.PasswordHistory = history
+	if errorMsg := isValidOption_NoReuse("123456", user, organization); errorMsg == "" {
+		t.Errorf("isValidOption_NoReuse() should reject the previous password")
+	}
+	if errorMsg := isValidOption_NoReuse("654321", user, organization); errorMsg == "" {
+		t.Errorf("isValidOption_NoReuse() should reject the current password")
+	}
+	if errorMsg := isValidOption_NoReuse("abcdef", user, organization); errorMsg != "" {
+		t.Errorf("isValidOption_NoReuse() = %s, expected valid", errorMsg)
+	}
+}
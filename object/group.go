@@ -29,11 +29,15 @@ type Group struct {
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
 	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
 
+	// the generated ID that the group is referred by in SCIM, which doesn't change with the name
+	Id string `xorm:"varchar(100) index" json:"id"`
+
 	DisplayName  string  `xorm:"varchar(100)" json:"displayName"`
 	Manager      string  `xorm:"varchar(100)" json:"manager"`
 	ContactEmail string  `xorm:"varchar(100)" json:"contactEmail"`
 	Type         string  `xorm:"varchar(100)" json:"type"`
 	ParentId     string  `xorm:"varchar(100)" json:"parentId"`
+	ExternalId   string  `xorm:"varchar(100) index" json:"externalId"`
 	IsTopGroup   bool    `xorm:"bool" json:"isTopGroup"`
 	Users        []*User `xorm:"-" json:"users"`
 
@@ -66,6 +70,30 @@ func GetGroups(owner string) ([]*Group, error) {
 	return groups, nil
 }
 
+func GetGlobalGroupsWithFilter(cond builder.Cond, sortField, sortOrder string) ([]*Group, error) {
+	groups := []*Group{}
+	session := ormer.Engine.NewSession()
+	defer session.Close()
+
+	if cond != nil {
+		session = session.Where(cond)
+	}
+	if sortField == "" || sortOrder == "" {
+		sortField = "created_time"
+	}
+	if sortOrder == "ascend" {
+		session = session.Asc(util.SnakeString(sortField))
+	} else {
+		session = session.Desc(util.SnakeString(sortField))
+	}
+	err := session.Find(&groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
 func GetPaginationGroups(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*Group, error) {
 	groups := []*Group{}
 	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
@@ -100,6 +128,43 @@ func GetGroup(id string) (*Group, error) {
 	return getGroup(owner, name)
 }
 
+// GetGroupById returns the group with the generated ID in the organization, or in any organization
+// if the owner is empty
+func GetGroupById(owner string, id string) (*Group, error) {
+	if id == "" {
+		return nil, nil
+	}
+
+	group := Group{Owner: owner, Id: id}
+	existed, err := ormer.Engine.Get(&group)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &group, nil
+	} else {
+		return nil, nil
+	}
+}
+
+// initGroupIds generates the IDs of the groups added before the IDs are generated
+func initGroupIds() {
+	groups := []*Group{}
+	err := ormer.Engine.Where("id = ? or id is null", "").Find(&groups)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, group := range groups {
+		group.Id = util.GenerateId()
+		_, err = ormer.Engine.ID(core.PK{group.Owner, group.Name}).Cols("id").Update(group)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 func UpdateGroup(id string, group *Group) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
 	oldGroup, err := getGroup(owner, name)
@@ -112,6 +177,9 @@ func UpdateGroup(id string, group *Group) (bool, error) {
 		return false, err
 	}
 
+	// the generated ID can't be changed
+	group.Id = oldGroup.Id
+
 	if name != group.Name {
 		err := GroupChangeTrigger(name, group.Name)
 		if err != nil {
@@ -124,6 +192,10 @@ func UpdateGroup(id string, group *Group) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(oldGroup.Owner, "update-group", oldGroup.GetId(), oldGroup, group)
+	}
+
 	return affected != 0, nil
 }
 
@@ -133,11 +205,19 @@ func AddGroup(group *Group) (bool, error) {
 		return false, err
 	}
 
+	if group.Id == "" {
+		group.Id = util.GenerateId()
+	}
+
 	affected, err := ormer.Engine.Insert(group)
 	if err != nil {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(group.Owner, "add-group", group.GetId(), nil, group)
+	}
+
 	return affected != 0, nil
 }
 
@@ -145,6 +225,11 @@ func AddGroups(groups []*Group) (bool, error) {
 	if len(groups) == 0 {
 		return false, nil
 	}
+	for _, group := range groups {
+		if group.Id == "" {
+			group.Id = util.GenerateId()
+		}
+	}
 	affected, err := ormer.Engine.Insert(groups)
 	if err != nil {
 		return false, err
@@ -175,6 +260,10 @@ func DeleteGroup(group *Group) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(group.Owner, "delete-group", group.GetId(), group, nil)
+	}
+
 	return affected != 0, nil
 }
 
@@ -45,6 +45,7 @@ func InitDb() {
 	}
 
 	initWebAuthn()
+	initGroupIds()
 }
 
 func getBuiltInAccountItems() []*AccountItem {
@@ -238,6 +239,7 @@ func initBuiltInCert() {
 		ExpireInYears:   20,
 		Certificate:     tokenJwtCertificate,
 		PrivateKey:      tokenJwtPrivateKey,
+		State:           CertStateActive,
 	}
 	_, err = AddCert(cert)
 	if err != nil {
@@ -0,0 +1,72 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+// JobLock is the lease of a background job shared by the replicas of Casdoor, so that the job runs on one
+// replica at a time
+type JobLock struct {
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	LockedBy    string `xorm:"varchar(100)" json:"lockedBy"`
+	LockedUntil int64  `json:"lockedUntil"`
+}
+
+// the holder of the leases taken by this replica
+var jobLockHolder = util.GenerateId()
+
+// tryLockJob takes the lease of the job for the duration, it returns false if the lease is held by another
+// replica. The lease is renewed if it is already held by this replica.
+func tryLockJob(name string, duration time.Duration) (bool, error) {
+	existed, err := addJobLockIfNotExist(name)
+	if err != nil {
+		return false, err
+	}
+	if !existed {
+		return false, nil
+	}
+
+	now := time.Now()
+	jobLock := &JobLock{LockedBy: jobLockHolder, LockedUntil: now.Add(duration).Unix()}
+	affected, err := ormer.Engine.Where("name = ? and (locked_until < ? or locked_by = ?)", name, now.Unix(), jobLockHolder).
+		Cols("locked_by", "locked_until").Update(jobLock)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// addJobLockIfNotExist inserts the row of the lock, it returns false if the row can't be inserted
+// because the replicas insert it at the same time
+func addJobLockIfNotExist(name string) (bool, error) {
+	existed, err := ormer.Engine.Exist(&JobLock{Name: name})
+	if err != nil {
+		return false, err
+	}
+
+	if !existed {
+		_, err = ormer.Engine.Insert(&JobLock{Name: name})
+		if err != nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	BackchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+	logoutTokenExpireInSeconds     = 120
+	frontchannelLogoutExpireInSecs = 300
+)
+
+// LogoutTokenClaims is the logout token sent to the back-channel logout uri of the applications,
+// per https://openid.net/specs/openid-connect-backchannel-1_0.html#LogoutToken
+type LogoutTokenClaims struct {
+	Events map[string]interface{} `json:"events"`
+	jwt.RegisteredClaims
+}
+
+type FrontchannelLogout struct {
+	Urls        []string
+	RedirectUrl string
+	ExpireAt    time.Time
+}
+
+var (
+	frontchannelLogouts          sync.Map
+	frontchannelLogoutsSweepOnce sync.Once
+)
+
+func generateLogoutToken(application *Application, user *User, host string) (string, error) {
+	cert, err := getCertByApplication(application)
+	if err != nil {
+		return "", err
+	}
+
+	if cert == nil {
+		return "", fmt.Errorf("The cert \"%s\" does not exist", application.Cert)
+	}
+
+	_, originBackend := getOriginFromHost(host)
+	nowTime := time.Now()
+	claims := LogoutTokenClaims{
+		Events: map[string]interface{}{
+			BackchannelLogoutEvent: map[string]interface{}{},
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    originBackend,
+			Subject:   user.Id,
+			Audience:  []string{application.ClientId},
+			ExpiresAt: jwt.NewNumericDate(nowTime.Add(logoutTokenExpireInSeconds * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(nowTime),
+			ID:        util.GenerateId(),
+		},
+	}
+
+	key, err := cert.getPrivateKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(cert.getSigningMethod(), claims)
+	token.Header["kid"] = cert.Name
+	token.Header["typ"] = "logout+jwt"
+	return token.SignedString(key)
+}
+
+func sendBackchannelLogout(application *Application, user *User, host string) error {
+	logoutToken, err := generateLogoutToken(application, user, host)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.PostForm(application.BackchannelLogoutUri, url.Values{"logout_token": {logoutToken}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("the back-channel logout uri: %s returned status: %s", application.BackchannelLogoutUri, resp.Status)
+	}
+
+	return nil
+}
+
+func getFrontchannelLogoutUrl(application *Application, host string) string {
+	_, originBackend := getOriginFromHost(host)
+
+	separator := "?"
+	if strings.Contains(application.FrontchannelLogoutUri, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%siss=%s", application.FrontchannelLogoutUri, separator, url.QueryEscape(originBackend))
+}
+
+// LogoutApplicationSessions signs the user out of every application that the browser session has signed in to:
+// the browser session is dropped from the application sessions, the back-channel logout tokens are sent
+// in the background, and the front-channel logout urls are returned to be loaded in the browser of the user
+func LogoutApplicationSessions(userId string, sessionId string, host string) ([]string, error) {
+	owner, name := util.GetOwnerAndNameFromId(userId)
+	user, err := getUser(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, nil
+	}
+
+	sessions := []*Session{}
+	err = ormer.Engine.Where("owner = ? and name = ? and application != ?", owner, name, CasdoorApplication).Find(&sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	frontchannelUrls := []string{}
+	for _, session := range sessions {
+		// the sessions of the other browsers and devices of the user stay signed in
+		if !util.InSlice(session.SessionId, sessionId) {
+			continue
+		}
+
+		application, err := getApplication("admin", session.Application)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = DeleteSessionId(session.GetId(), sessionId)
+		if err != nil {
+			return nil, err
+		}
+
+		if application == nil {
+			continue
+		}
+
+		if application.BackchannelLogoutUri != "" {
+			util.SafeGoroutine(func() {
+				err := sendBackchannelLogout(application, user, host)
+				if err != nil {
+					fmt.Printf("sendBackchannelLogout() error for application: %s, %s\n", application.GetId(), err.Error())
+				}
+			})
+		}
+
+		if application.FrontchannelLogoutUri != "" {
+			frontchannelUrls = append(frontchannelUrls, getFrontchannelLogoutUrl(application, host))
+		}
+	}
+
+	return frontchannelUrls, nil
+}
+
+// AddFrontchannelLogout keeps the front-channel logout urls for a short while, so that the logout page
+// returned to the browser can load them before redirecting to the redirect url
+func AddFrontchannelLogout(urls []string, redirectUrl string) string {
+	frontchannelLogoutsSweepOnce.Do(func() {
+		util.SafeGoroutine(sweepExpiredFrontchannelLogouts)
+	})
+
+	id := util.GenerateId()
+	frontchannelLogouts.Store(id, &FrontchannelLogout{
+		Urls:        urls,
+		RedirectUrl: redirectUrl,
+		ExpireAt:    time.Now().Add(frontchannelLogoutExpireInSecs * time.Second),
+	})
+	return id
+}
+
+// sweepExpiredFrontchannelLogouts removes the front-channel logouts whose page has never been loaded
+func sweepExpiredFrontchannelLogouts() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		frontchannelLogouts.Range(func(key, value interface{}) bool {
+			if now.After(value.(*FrontchannelLogout).ExpireAt) {
+				frontchannelLogouts.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// GetFrontchannelLogoutPageUrl returns the url of the page that performs the front-channel logout,
+// which falls back to the home page of Casdoor if there is no redirect url
+func GetFrontchannelLogoutPageUrl(urls []string, redirectUrl string, host string) string {
+	originFrontend, originBackend := getOriginFromHost(host)
+	if redirectUrl == "" {
+		redirectUrl = originFrontend
+	}
+
+	id := AddFrontchannelLogout(urls, redirectUrl)
+	return fmt.Sprintf("%s/api/frontchannel-logout?id=%s", originBackend, id)
+}
+
+func GetFrontchannelLogout(id string) *FrontchannelLogout {
+	value, ok := frontchannelLogouts.LoadAndDelete(id)
+	if !ok {
+		return nil
+	}
+
+	frontchannelLogout := value.(*FrontchannelLogout)
+	if time.Now().After(frontchannelLogout.ExpireAt) {
+		return nil
+	}
+
+	return frontchannelLogout
+}
+
+// GetFrontchannelLogoutHtml renders the page that loads the front-channel logout urls in hidden iframes,
+// per https://openid.net/specs/openid-connect-frontchannel-1_0.html#OPLogout, and then redirects
+func GetFrontchannelLogoutHtml(urls []string, redirectUrl string) string {
+	iframes := ""
+	for _, frontchannelUrl := range urls {
+		iframes += fmt.Sprintf(`<iframe src="%s" style="display:none"></iframe>`, html.EscapeString(frontchannelUrl))
+	}
+
+	redirect := ""
+	if redirectUrl != "" {
+		// the url is json encoded, which also escapes "<" and ">" for the script tag
+		redirect = fmt.Sprintf(`window.location.replace(%s);`, util.StructToJson(redirectUrl))
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head><title>Logout</title></head>
+<body>
+%s
+<script>
+window.onload = function() { %s };
+</script>
+</body>
+</html>`, iframes, redirect)
+}
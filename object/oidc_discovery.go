@@ -32,6 +32,10 @@ type OidcDiscovery struct {
 	UserinfoEndpoint                       string   `json:"userinfo_endpoint"`
 	JwksUri                                string   `json:"jwks_uri"`
 	IntrospectionEndpoint                  string   `json:"introspection_endpoint"`
+	RevocationEndpoint                     string   `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint            string   `json:"device_authorization_endpoint"`
+	PushedAuthorizationRequestEndpoint     string   `json:"pushed_authorization_request_endpoint"`
+	RequirePushedAuthorizationRequests     bool     `json:"require_pushed_authorization_requests"`
 	ResponseTypesSupported                 []string `json:"response_types_supported"`
 	ResponseModesSupported                 []string `json:"response_modes_supported"`
 	GrantTypesSupported                    []string `json:"grant_types_supported"`
@@ -40,8 +44,15 @@ type OidcDiscovery struct {
 	ScopesSupported                        []string `json:"scopes_supported"`
 	ClaimsSupported                        []string `json:"claims_supported"`
 	RequestParameterSupported              bool     `json:"request_parameter_supported"`
+	RequestUriParameterSupported           bool     `json:"request_uri_parameter_supported"`
+	TokenEndpointAuthMethodsSupported      []string `json:"token_endpoint_auth_methods_supported"`
+	TokenEndpointAuthSigningAlgValues      []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+	TlsClientCertificateBoundAccessTokens  bool     `json:"tls_client_certificate_bound_access_tokens"`
+	DpopSigningAlgValuesSupported          []string `json:"dpop_signing_alg_values_supported"`
 	RequestObjectSigningAlgValuesSupported []string `json:"request_object_signing_alg_values_supported"`
 	EndSessionEndpoint                     string   `json:"end_session_endpoint"`
+	FrontchannelLogoutSupported            bool     `json:"frontchannel_logout_supported"`
+	BackchannelLogoutSupported             bool     `json:"backchannel_logout_supported"`
 }
 
 func isIpAddress(host string) bool {
@@ -108,16 +119,27 @@ func GetOidcDiscovery(host string) OidcDiscovery {
 		UserinfoEndpoint:                       fmt.Sprintf("%s/api/userinfo", originBackend),
 		JwksUri:                                fmt.Sprintf("%s/.well-known/jwks", originBackend),
 		IntrospectionEndpoint:                  fmt.Sprintf("%s/api/login/oauth/introspect", originBackend),
+		RevocationEndpoint:                     fmt.Sprintf("%s/api/login/oauth/revoke", originBackend),
+		DeviceAuthorizationEndpoint:            fmt.Sprintf("%s/api/login/oauth/device_authorization", originBackend),
+		PushedAuthorizationRequestEndpoint:     fmt.Sprintf("%s/api/login/oauth/par", originBackend),
+		RequirePushedAuthorizationRequests:     false,
 		ResponseTypesSupported:                 []string{"code", "token", "id_token", "code token", "code id_token", "token id_token", "code token id_token", "none"},
 		ResponseModesSupported:                 []string{"query", "fragment", "login", "code", "link"},
-		GrantTypesSupported:                    []string{"password", "authorization_code"},
+		GrantTypesSupported:                    []string{"password", "authorization_code", DeviceCodeGrantType, TokenExchangeGrantType},
 		SubjectTypesSupported:                  []string{"public"},
-		IdTokenSigningAlgValuesSupported:       []string{"RS256"},
+		IdTokenSigningAlgValuesSupported:       []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
 		ScopesSupported:                        []string{"openid", "email", "profile", "address", "phone", "offline_access"},
 		ClaimsSupported:                        []string{"iss", "ver", "sub", "aud", "iat", "exp", "id", "type", "displayName", "avatar", "permanentAvatar", "email", "phone", "location", "affiliation", "title", "homepage", "bio", "tag", "region", "language", "score", "ranking", "isOnline", "isAdmin", "isForbidden", "signupApplication", "ldap"},
 		RequestParameterSupported:              true,
-		RequestObjectSigningAlgValuesSupported: []string{"HS256", "HS384", "HS512"},
+		RequestUriParameterSupported:           true,
+		TokenEndpointAuthMethodsSupported:      []string{ClientSecretBasic, ClientSecretPost, PrivateKeyJwt, TlsClientAuth},
+		TokenEndpointAuthSigningAlgValues:      []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
+		TlsClientCertificateBoundAccessTokens:  true,
+		DpopSigningAlgValuesSupported:          []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
+		RequestObjectSigningAlgValuesSupported: []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
 		EndSessionEndpoint:                     fmt.Sprintf("%s/api/logout", originBackend),
+		FrontchannelLogoutSupported:            true,
+		BackchannelLogoutSupported:             true,
 	}
 
 	return oidcDiscovery
@@ -134,7 +156,7 @@ func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
 	// link here: https://self-issued.info/docs/draft-ietf-jose-json-web-key.html
 	// or https://datatracker.ietf.org/doc/html/draft-ietf-jose-json-web-key
 	for _, cert := range certs {
-		if cert.Type != "x509" {
+		if cert.Type != "x509" || !cert.isPublished() {
 			continue
 		}
 
@@ -153,7 +175,7 @@ func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
 		jwk.Key = x509Cert.PublicKey
 		jwk.Certificates = []*x509.Certificate{x509Cert}
 		jwk.KeyID = cert.Name
-		jwk.Algorithm = cert.CryptoAlgorithm
+		jwk.Algorithm = cert.getSigningMethod().Alg()
 		jwk.Use = "sig"
 		jwks.Keys = append(jwks.Keys, jwk)
 	}
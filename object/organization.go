@@ -55,8 +55,12 @@ type Organization struct {
 	WebsiteUrl             string     `xorm:"varchar(100)" json:"websiteUrl"`
 	Favicon                string     `xorm:"varchar(100)" json:"favicon"`
 	PasswordType           string     `xorm:"varchar(100)" json:"passwordType"`
+	TargetPasswordType     string     `xorm:"varchar(100)" json:"targetPasswordType"`
 	PasswordSalt           string     `xorm:"varchar(100)" json:"passwordSalt"`
-	PasswordOptions        []string   `xorm:"varchar(100)" json:"passwordOptions"`
+	PasswordOptions        []string   `xorm:"varchar(300)" json:"passwordOptions"`
+	PasswordHistoryCount   int        `json:"passwordHistoryCount"`
+	PasswordExpireDays     int        `json:"passwordExpireDays"`
+	EnableNtPasswordHash   bool       `json:"enableNtPasswordHash"`
 	CountryCodes           []string   `xorm:"varchar(200)"  json:"countryCodes"`
 	DefaultAvatar          string     `xorm:"varchar(200)" json:"defaultAvatar"`
 	DefaultApplication     string     `xorm:"varchar(100)" json:"defaultApplication"`
@@ -289,6 +289,11 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(Record))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(VerificationRecord))
 	if err != nil {
 		panic(err)
@@ -299,6 +304,26 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(WebhookDelivery))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ProvisioningRecord))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(JobLock))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(SigninThrottle))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(Syncer))
 	if err != nil {
 		panic(err)
@@ -329,6 +354,16 @@ func (a *Ormer) createTable() {
 		panic(err)
 	}
 
+	err = a.Engine.Sync2(new(RadiusClient))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(RadiusPolicy))
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(xormadapter.CasbinRule))
 	if err != nil {
 		panic(err)
@@ -200,6 +200,8 @@ func UpdatePermission(id string, permission *Permission) (bool, error) {
 		if err != nil {
 			return false, err
 		}
+
+		sendWebhookEvent(oldPermission.Owner, "update-permission", oldPermission.GetId(), oldPermission, permission)
 	}
 
 	return affected != 0, nil
@@ -221,6 +223,8 @@ func AddPermission(permission *Permission) (bool, error) {
 		if err != nil {
 			return false, err
 		}
+
+		sendWebhookEvent(permission.Owner, "add-permission", permission.GetId(), nil, permission)
 	}
 
 	return affected != 0, nil
@@ -287,6 +291,11 @@ func AddPermissionsInBatch(permissions []*Permission) (bool, error) {
 }
 
 func DeletePermission(permission *Permission) (bool, error) {
+	oldPermission, err := getPermission(permission.Owner, permission.Name)
+	if err != nil {
+		return false, err
+	}
+
 	affected, err := ormer.Engine.ID(core.PK{permission.Owner, permission.Name}).Delete(&Permission{})
 	if err != nil {
 		return false, err
@@ -312,6 +321,8 @@ func DeletePermission(permission *Permission) (bool, error) {
 				}
 			}
 		}
+
+		sendWebhookEvent(permission.Owner, "delete-permission", permission.GetId(), oldPermission, nil)
 	}
 
 	return affected != 0, nil
@@ -0,0 +1,782 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/provisioning"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	ProvisioningSynced = "Synced"
+	ProvisioningFailed = "Failed"
+
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+
+	provisioningReconcileInterval = time.Hour
+	provisioningQueueSize         = 1000
+)
+
+// ProvisioningRecord is the state of a user or group provisioned to the downstream application of
+// a SCIM provider, the provider belongs to the organization of the user or group
+type ProvisioningRecord struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Provider     string `xorm:"varchar(100) index" json:"provider"`
+	ResourceType string `xorm:"varchar(100)" json:"resourceType"`
+	ResourceId   string `xorm:"varchar(100) index" json:"resourceId"`
+	ResourceName string `xorm:"varchar(100)" json:"resourceName"`
+	RemoteId     string `xorm:"varchar(100)" json:"remoteId"`
+
+	State      string `xorm:"varchar(100) index" json:"state"`
+	Error      string `xorm:"varchar(1000)" json:"error"`
+	SyncedTime string `xorm:"varchar(100)" json:"syncedTime"`
+}
+
+// defaultScimUserMapping maps the fields of the users to the SCIM attributes, it is used when the
+// user mapping of the provider is empty
+var defaultScimUserMapping = map[string]string{
+	"name":        "userName",
+	"id":          "externalId",
+	"displayName": "displayName",
+	"firstName":   "name.givenName",
+	"lastName":    "name.familyName",
+	"email":       "emails.value",
+	"phone":       "phoneNumbers.value",
+}
+
+var scimMultiValuedAttributes = []string{"emails", "phoneNumbers", "ims", "photos", "addresses", "entitlements", "roles", "x509Certificates"}
+
+// provisioningQueue provisions the events of a provider one by one in the background, the provisioning of
+// the events and the reconciliation of the provider are serialized by the mutex, so that a resource isn't
+// created twice in the downstream application. The slow downstream application only delays its own provider.
+type provisioningQueue struct {
+	mutex sync.Mutex
+	tasks chan func()
+}
+
+var (
+	provisioningQueuesMutex sync.Mutex
+	provisioningQueues      = map[string]*provisioningQueue{}
+)
+
+// getProvisioningQueue returns the queue of the provider, the worker of the queue is started with it
+func getProvisioningQueue(provider *Provider) *provisioningQueue {
+	provisioningQueuesMutex.Lock()
+	defer provisioningQueuesMutex.Unlock()
+
+	queue, ok := provisioningQueues[provider.GetId()]
+	if !ok {
+		queue = &provisioningQueue{tasks: make(chan func(), provisioningQueueSize)}
+		provisioningQueues[provider.GetId()] = queue
+
+		util.SafeGoroutine(func() {
+			for task := range queue.tasks {
+				queue.mutex.Lock()
+				task()
+				queue.mutex.Unlock()
+			}
+		})
+	}
+	return queue
+}
+
+// enqueue adds the task to the queue, the task is dropped if the queue is full, and the drift is
+// repaired by the next reconciliation
+func (queue *provisioningQueue) enqueue(task func()) bool {
+	select {
+	case queue.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+func GetProvisioningRecordCount(owner, provider, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&ProvisioningRecord{Provider: provider})
+}
+
+func GetPaginationProvisioningRecords(owner, provider string, offset, limit int, field, value, sortField, sortOrder string) ([]*ProvisioningRecord, error) {
+	records := []*ProvisioningRecord{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&records, &ProvisioningRecord{Provider: provider})
+	if err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+func getProvisioningRecord(provider *Provider, resourceType string, resourceId string) (*ProvisioningRecord, error) {
+	record := ProvisioningRecord{Owner: provider.Owner, Provider: provider.Name, ResourceType: resourceType, ResourceId: resourceId}
+	existed, err := ormer.Engine.Get(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	if existed {
+		return &record, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func getProvisioningRecords(provider *Provider, resourceType string) ([]*ProvisioningRecord, error) {
+	records := []*ProvisioningRecord{}
+	err := ormer.Engine.Find(&records, &ProvisioningRecord{Owner: provider.Owner, Provider: provider.Name, ResourceType: resourceType})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func newProvisioningRecord(provider *Provider, resourceType string, resourceId string, resourceName string) *ProvisioningRecord {
+	return &ProvisioningRecord{
+		Owner:        provider.Owner,
+		Provider:     provider.Name,
+		ResourceType: resourceType,
+		ResourceId:   resourceId,
+		ResourceName: resourceName,
+	}
+}
+
+// saveProvisioningRecord inserts the record if it is created by newProvisioningRecord, or updates it
+func saveProvisioningRecord(record *ProvisioningRecord) error {
+	record.UpdatedTime = util.GetCurrentTime()
+	if record.Name == "" {
+		record.Name = util.GenerateId()
+		record.CreatedTime = record.UpdatedTime
+		_, err := ormer.Engine.Insert(record)
+		return err
+	}
+
+	_, err := ormer.Engine.ID(core.PK{record.Owner, record.Name}).AllCols().Update(record)
+	return err
+}
+
+func deleteProvisioningRecord(record *ProvisioningRecord) error {
+	if record.Name == "" {
+		return nil
+	}
+
+	_, err := ormer.Engine.ID(core.PK{record.Owner, record.Name}).Delete(&ProvisioningRecord{})
+	return err
+}
+
+func (record *ProvisioningRecord) GetId() string {
+	return fmt.Sprintf("%s/%s", record.Owner, record.Name)
+}
+
+func getProvisioningProviders(organization string) ([]*Provider, error) {
+	providers := []*Provider{}
+	err := ormer.Engine.Find(&providers, &Provider{Owner: organization, Category: "SCIM"})
+	if err != nil {
+		return nil, err
+	}
+
+	return providers, nil
+}
+
+func getProvisioningClient(provider *Provider) (provisioning.ProvisioningProvider, error) {
+	client := provisioning.GetProvisioningProvider(provider.Type, provider.Endpoint, provider.ClientSecret)
+	if client == nil {
+		return nil, fmt.Errorf("the provisioning provider type: %s is not supported", provider.Type)
+	}
+	return client, nil
+}
+
+// setScimAttribute sets the attribute like "name.givenName" in the resource, the sub-attributes of
+// the multi-valued attributes like "emails.value" are set in their first value
+func setScimAttribute(resource map[string]interface{}, attribute string, value interface{}) {
+	tokens := strings.SplitN(attribute, ".", 2)
+	if len(tokens) == 1 {
+		resource[attribute] = value
+		return
+	}
+
+	name, subAttribute := tokens[0], tokens[1]
+	if util.InSlice(scimMultiValuedAttributes, name) {
+		values, _ := resource[name].([]interface{})
+		if len(values) == 0 {
+			values = []interface{}{map[string]interface{}{}}
+		}
+		values[0].(map[string]interface{})[subAttribute] = value
+		resource[name] = values
+		return
+	}
+
+	subResource, ok := resource[name].(map[string]interface{})
+	if !ok {
+		subResource = map[string]interface{}{}
+		resource[name] = subResource
+	}
+	subResource[subAttribute] = value
+}
+
+func getScimUserResource(provider *Provider, user *User) provisioning.Resource {
+	mapping := provider.UserMapping
+	if len(mapping) == 0 {
+		mapping = defaultScimUserMapping
+	}
+
+	userMap := getEntityMap(user)
+	resource := provisioning.Resource{}
+	schemas := []interface{}{scimUserSchema}
+	for field, attribute := range mapping {
+		value := userMap[field]
+		if value == nil || value == "" {
+			continue
+		}
+
+		// the attributes of the schema extensions are prefixed with the schema URI, e.g.
+		// urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department
+		if strings.HasPrefix(attribute, "urn:") {
+			index := strings.LastIndex(attribute, ":")
+			schema := attribute[:index]
+			extension, ok := resource[schema].(map[string]interface{})
+			if !ok {
+				extension = map[string]interface{}{}
+				resource[schema] = extension
+				schemas = append(schemas, schema)
+			}
+			setScimAttribute(extension, attribute[index+1:], value)
+			continue
+		}
+
+		setScimAttribute(resource, attribute, value)
+	}
+
+	resource["schemas"] = schemas
+	resource["active"] = !user.IsForbidden && !user.IsDeleted
+	return resource
+}
+
+func getScimGroupResource(group *Group, memberIds []string) provisioning.Resource {
+	displayName := group.DisplayName
+	if displayName == "" {
+		displayName = group.Name
+	}
+
+	members := []interface{}{}
+	for _, memberId := range memberIds {
+		members = append(members, map[string]interface{}{"value": memberId})
+	}
+
+	return provisioning.Resource{
+		"schemas":     []interface{}{scimGroupSchema},
+		"externalId":  group.Name,
+		"displayName": displayName,
+		"members":     members,
+	}
+}
+
+// getProvisionedMemberIds returns the ids of the members of the group in the downstream application,
+// the members that haven't been provisioned are skipped
+func getProvisionedMemberIds(provider *Provider, group *Group) ([]string, error) {
+	users, err := GetGroupUsers(group.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, user := range users {
+		record, err := getProvisioningRecord(provider, provisioning.UserResourceType, user.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if record != nil && record.RemoteId != "" {
+			res = append(res, record.RemoteId)
+		}
+	}
+	return res, nil
+}
+
+// isScimValueSynced checks whether the remote value contains the local value, the attributes
+// that aren't provisioned by Casdoor are ignored
+func isScimValueSynced(local interface{}, remote interface{}) bool {
+	switch v := local.(type) {
+	case map[string]interface{}:
+		remoteMap, ok := remote.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range v {
+			if key != "schemas" && !isScimValueSynced(value, remoteMap[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		remoteValues, ok := remote.([]interface{})
+		if !ok || len(remoteValues) != len(v) {
+			return false
+		}
+		for _, value := range v {
+			found := false
+			for _, remoteValue := range remoteValues {
+				if isScimValueSynced(value, remoteValue) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		if remote == nil {
+			return local == nil || local == ""
+		}
+		return fmt.Sprintf("%v", local) == fmt.Sprintf("%v", remote)
+	}
+}
+
+func getScimResourceKey(resourceType string) string {
+	if resourceType == provisioning.GroupResourceType {
+		return "displayName"
+	}
+	return "userName"
+}
+
+// syncProvisioningResource creates or updates the resource in the downstream application and records
+// the result. The remote resource is given by the reconciliation to skip the resources in sync, when it
+// is nil, the resource is updated by the remote id of the record, or found by its userName or displayName.
+func syncProvisioningResource(client provisioning.ProvisioningProvider, record *ProvisioningRecord, resource provisioning.Resource, remote provisioning.Resource) error {
+	err := func() error {
+		key := getScimResourceKey(record.ResourceType)
+		if record.RemoteId == "" && remote == nil {
+			value, _ := resource[key].(string)
+			if value != "" {
+				found, err := client.FindResource(record.ResourceType, key, value)
+				if err != nil {
+					return err
+				}
+				remote = found
+				record.RemoteId = found.GetId()
+			}
+		}
+
+		if record.RemoteId != "" {
+			if remote != nil && isScimValueSynced(map[string]interface{}(resource), map[string]interface{}(remote)) {
+				return nil
+			}
+
+			res, err := client.UpdateResource(record.ResourceType, record.RemoteId, resource)
+			if err != nil {
+				return err
+			}
+			if res != nil {
+				return nil
+			}
+			// the resource has been deleted in the downstream application
+			record.RemoteId = ""
+		}
+
+		res, err := client.AddResource(record.ResourceType, resource)
+		if err != nil {
+			return err
+		}
+		record.RemoteId = res.GetId()
+		return nil
+	}()
+
+	if err != nil {
+		record.State = ProvisioningFailed
+		record.Error = err.Error()
+		if len(record.Error) > 1000 {
+			record.Error = record.Error[:1000]
+		}
+	} else {
+		record.State = ProvisioningSynced
+		record.Error = ""
+		record.SyncedTime = util.GetCurrentTime()
+	}
+
+	saveErr := saveProvisioningRecord(record)
+	if saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// deleteProvisioningResource deletes the resource in the downstream application, the record is kept
+// as failed if the deletion fails so that it is retried by the reconciliation
+func deleteProvisioningResource(client provisioning.ProvisioningProvider, record *ProvisioningRecord) error {
+	if record.RemoteId != "" {
+		err := client.DeleteResource(record.ResourceType, record.RemoteId)
+		if err != nil {
+			record.State = ProvisioningFailed
+			record.Error = err.Error()
+			saveErr := saveProvisioningRecord(record)
+			if saveErr != nil {
+				return saveErr
+			}
+			return err
+		}
+	}
+
+	return deleteProvisioningRecord(record)
+}
+
+func provisionUser(provider *Provider, client provisioning.ProvisioningProvider, user *User) error {
+	record, err := getProvisioningRecord(provider, provisioning.UserResourceType, user.Id)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		record = newProvisioningRecord(provider, provisioning.UserResourceType, user.Id, user.GetId())
+	}
+	record.ResourceName = user.GetId()
+	return syncProvisioningResource(client, record, getScimUserResource(provider, user), nil)
+}
+
+func provisionGroup(provider *Provider, client provisioning.ProvisioningProvider, group *Group) error {
+	record, err := getProvisioningRecord(provider, provisioning.GroupResourceType, group.Name)
+	if err != nil {
+		return err
+	}
+
+	memberIds, err := getProvisionedMemberIds(provider, group)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		record = newProvisioningRecord(provider, provisioning.GroupResourceType, group.Name, group.GetId())
+	}
+	return syncProvisioningResource(client, record, getScimGroupResource(group, memberIds), nil)
+}
+
+func deprovisionResource(provider *Provider, client provisioning.ProvisioningProvider, resourceType string, resourceId string) error {
+	record, err := getProvisioningRecord(provider, resourceType, resourceId)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return nil
+	}
+	return deleteProvisioningResource(client, record)
+}
+
+// getChangedGroups returns the groups that the user has joined or left
+func getChangedGroups(before *User, after *User) []string {
+	res := []string{}
+	if before != nil && after != nil {
+		for _, group := range before.Groups {
+			if !util.InSlice(after.Groups, group) {
+				res = append(res, group)
+			}
+		}
+		for _, group := range after.Groups {
+			if !util.InSlice(before.Groups, group) {
+				res = append(res, group)
+			}
+		}
+	} else if after != nil {
+		res = append(res, after.Groups...)
+	} else if before != nil {
+		res = append(res, before.Groups...)
+	}
+	return res
+}
+
+// provisionEntityEvent provisions the lifecycle event of the user or group to the SCIM providers of
+// the organization in the background, the entities are read again as they may be modified by the caller.
+// The updates that only change the bookkeeping fields, like the counters of the failed sign-ins, are ignored.
+func provisionEntityEvent(organization string, action string, changedFields []string, before interface{}, after interface{}) {
+	if changedFields != nil && len(changedFields) == 0 {
+		return
+	}
+
+	var provision func(provider *Provider, client provisioning.ProvisioningProvider) error
+
+	switch action {
+	case "add-user", "update-user", "delete-user":
+		beforeUser, _ := before.(*User)
+		afterUser, _ := after.(*User)
+		if beforeUser == nil && afterUser == nil {
+			return
+		}
+
+		changedGroups := getChangedGroups(beforeUser, afterUser)
+		if afterUser == nil {
+			userId := beforeUser.Id
+			provision = func(provider *Provider, client provisioning.ProvisioningProvider) error {
+				return deprovisionResource(provider, client, provisioning.UserResourceType, userId)
+			}
+			break
+		}
+
+		owner, name := afterUser.Owner, afterUser.Name
+		provision = func(provider *Provider, client provisioning.ProvisioningProvider) error {
+			user, err := getUser(owner, name)
+			if err != nil || user == nil {
+				return err
+			}
+
+			err = provisionUser(provider, client, user)
+			if err != nil {
+				return err
+			}
+
+			for _, groupId := range changedGroups {
+				group, err := GetGroup(groupId)
+				if err != nil {
+					return err
+				}
+				if group == nil {
+					continue
+				}
+
+				err = provisionGroup(provider, client, group)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case "add-group", "update-group", "delete-group":
+		beforeGroup, _ := before.(*Group)
+		afterGroup, _ := after.(*Group)
+		if beforeGroup == nil && afterGroup == nil {
+			return
+		}
+
+		if afterGroup == nil {
+			groupName := beforeGroup.Name
+			provision = func(provider *Provider, client provisioning.ProvisioningProvider) error {
+				return deprovisionResource(provider, client, provisioning.GroupResourceType, groupName)
+			}
+			break
+		}
+
+		owner, name := afterGroup.Owner, afterGroup.Name
+		oldName := name
+		if beforeGroup != nil {
+			oldName = beforeGroup.Name
+		}
+		provision = func(provider *Provider, client provisioning.ProvisioningProvider) error {
+			group, err := getGroup(owner, name)
+			if err != nil || group == nil {
+				return err
+			}
+
+			if oldName != name {
+				record, err := getProvisioningRecord(provider, provisioning.GroupResourceType, oldName)
+				if err != nil {
+					return err
+				}
+				if record != nil {
+					record.ResourceId = group.Name
+					record.ResourceName = group.GetId()
+					err = saveProvisioningRecord(record)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			return provisionGroup(provider, client, group)
+		}
+	default:
+		return
+	}
+
+	providers, err := getProvisioningProviders(organization)
+	if err != nil {
+		fmt.Printf("provisionEntityEvent() error: %s\n", err.Error())
+		return
+	}
+
+	for _, provider := range providers {
+		provider := provider
+		ok := getProvisioningQueue(provider).enqueue(func() {
+			client, err := getProvisioningClient(provider)
+			if err == nil {
+				err = provision(provider, client)
+			}
+			if err != nil {
+				fmt.Printf("provisionEntityEvent() error for provider: %s, %s\n", provider.GetId(), err.Error())
+			}
+		})
+		if !ok {
+			fmt.Printf("provisionEntityEvent() error for provider: %s, the queue is full, the event: %s is left to the reconciliation\n", provider.GetId(), action)
+		}
+	}
+}
+
+// reconcileResources provisions the local resources that are missing or have drifted in the downstream
+// application, and deletes the provisioned resources that no longer exist locally
+func reconcileResources(provider *Provider, client provisioning.ProvisioningProvider, resourceType string, resources map[string]provisioning.Resource, resourceNames map[string]string) error {
+	remoteResources, err := client.GetResources(resourceType)
+	if err != nil {
+		return err
+	}
+
+	remoteResourceMap := map[string]provisioning.Resource{}
+	for _, remoteResource := range remoteResources {
+		remoteResourceMap[remoteResource.GetId()] = remoteResource
+	}
+
+	records, err := getProvisioningRecords(provider, resourceType)
+	if err != nil {
+		return err
+	}
+
+	recordMap := map[string]*ProvisioningRecord{}
+	for _, record := range records {
+		recordMap[record.ResourceId] = record
+	}
+
+	for resourceId, resource := range resources {
+		record, ok := recordMap[resourceId]
+		if !ok {
+			record = newProvisioningRecord(provider, resourceType, resourceId, resourceNames[resourceId])
+		}
+		delete(recordMap, resourceId)
+
+		var remote provisioning.Resource
+		if record.RemoteId != "" {
+			remote = remoteResourceMap[record.RemoteId]
+			if remote == nil {
+				// the resource has been deleted in the downstream application
+				record.RemoteId = ""
+			}
+		}
+
+		// the failure is recorded in the record, and retried by the next reconciliation
+		err = syncProvisioningResource(client, record, resource, remote)
+		if err != nil {
+			fmt.Printf("reconcileResources() error for %s: %s, %s\n", resourceType, record.ResourceName, err.Error())
+		}
+	}
+
+	for _, record := range recordMap {
+		err = deleteProvisioningResource(client, record)
+		if err != nil {
+			fmt.Printf("reconcileResources() error for %s: %s, %s\n", resourceType, record.ResourceName, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ReconcileProvider repairs the drift between the users and groups of the organization and the
+// downstream application of the SCIM provider
+func ReconcileProvider(provider *Provider) error {
+	if provider.Category != "SCIM" {
+		return fmt.Errorf("the provider: %s is not a SCIM provider", provider.GetId())
+	}
+
+	client, err := getProvisioningClient(provider)
+	if err != nil {
+		return err
+	}
+
+	queue := getProvisioningQueue(provider)
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	users, err := GetUsers(provider.Owner)
+	if err != nil {
+		return err
+	}
+
+	userResources := map[string]provisioning.Resource{}
+	userNames := map[string]string{}
+	for _, user := range users {
+		userResources[user.Id] = getScimUserResource(provider, user)
+		userNames[user.Id] = user.GetId()
+	}
+
+	err = reconcileResources(provider, client, provisioning.UserResourceType, userResources, userNames)
+	if err != nil {
+		return err
+	}
+
+	// the groups are reconciled after the users, so that the members have been provisioned
+	groups, err := GetGroups(provider.Owner)
+	if err != nil {
+		return err
+	}
+
+	groupResources := map[string]provisioning.Resource{}
+	groupNames := map[string]string{}
+	for _, group := range groups {
+		memberIds, err := getProvisionedMemberIds(provider, group)
+		if err != nil {
+			return err
+		}
+
+		groupResources[group.Name] = getScimGroupResource(group, memberIds)
+		groupNames[group.Name] = group.GetId()
+	}
+
+	return reconcileResources(provider, client, provisioning.GroupResourceType, groupResources, groupNames)
+}
+
+func reconcileProviders() error {
+	providers := []*Provider{}
+	err := ormer.Engine.Where("category = ? and owner <> ?", "SCIM", "admin").Find(&providers)
+	if err != nil {
+		return err
+	}
+
+	for _, provider := range providers {
+		err = ReconcileProvider(provider)
+		if err != nil {
+			fmt.Printf("reconcileProviders() error for provider: %s, %s\n", provider.GetId(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// RunProvisioningReconcileJob reconciles the SCIM providers periodically, the job runs on the replica that
+// takes the lease of the interval, and the other replicas skip it
+func RunProvisioningReconcileJob() {
+	ticker := time.NewTicker(provisioningReconcileInterval)
+	for ; true; <-ticker.C {
+		locked, err := tryLockJob("provisioning-reconcile", provisioningReconcileInterval)
+		if err != nil {
+			fmt.Printf("RunProvisioningReconcileJob() error: %s\n", err.Error())
+			continue
+		}
+		if !locked {
+			continue
+		}
+
+		err = reconcileProviders()
+		if err != nil {
+			fmt.Printf("RunProvisioningReconcileJob() error: %s\n", err.Error())
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+// RadiusClient is a NAS (network access server) which sends RADIUS requests on behalf of the organization,
+// the requests from the IP address are authenticated by the shared secret of the client
+type RadiusClient struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	IpAddress string `xorm:"varchar(100)" json:"ipAddress"` // e.g. "192.168.0.10" or "192.168.0.0/24"
+	Secret    string `xorm:"varchar(100)" json:"secret"`
+}
+
+// the RADIUS clients are looked up for every packet, so they are cached in memory. The cache is reloaded
+// when the clients are changed, or after the timeout for the changes made by the other replicas.
+const radiusClientCacheTimeout = time.Minute
+
+var (
+	radiusClientCacheMutex    sync.Mutex
+	radiusClientCache         []*RadiusClient
+	radiusClientCacheLoadedAt time.Time
+)
+
+func GetRadiusClientCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&RadiusClient{})
+}
+
+func GetRadiusClients(owner string) ([]*RadiusClient, error) {
+	radiusClients := []*RadiusClient{}
+	err := ormer.Engine.Desc("created_time").Find(&radiusClients, &RadiusClient{Owner: owner})
+	if err != nil {
+		return radiusClients, err
+	}
+
+	return radiusClients, nil
+}
+
+func GetPaginationRadiusClients(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*RadiusClient, error) {
+	radiusClients := []*RadiusClient{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&radiusClients)
+	if err != nil {
+		return radiusClients, err
+	}
+
+	return radiusClients, nil
+}
+
+func getRadiusClient(owner string, name string) (*RadiusClient, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	radiusClient := RadiusClient{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&radiusClient)
+	if err != nil {
+		return &radiusClient, err
+	}
+
+	if existed {
+		return &radiusClient, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetRadiusClient(id string) (*RadiusClient, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getRadiusClient(owner, name)
+}
+
+func getCachedRadiusClients() ([]*RadiusClient, error) {
+	radiusClientCacheMutex.Lock()
+	defer radiusClientCacheMutex.Unlock()
+
+	if radiusClientCache != nil && time.Since(radiusClientCacheLoadedAt) < radiusClientCacheTimeout {
+		return radiusClientCache, nil
+	}
+
+	radiusClients := []*RadiusClient{}
+	err := ormer.Engine.Find(&radiusClients)
+	if err != nil {
+		return nil, err
+	}
+
+	radiusClientCache = radiusClients
+	radiusClientCacheLoadedAt = time.Now()
+	return radiusClientCache, nil
+}
+
+func clearRadiusClientCache() {
+	radiusClientCacheMutex.Lock()
+	defer radiusClientCacheMutex.Unlock()
+
+	radiusClientCache = nil
+}
+
+// getRadiusClientIpNet returns the IP address or CIDR of the RADIUS client as a network,
+// an IP address is a network of a single address
+func getRadiusClientIpNet(ipAddress string) (*net.IPNet, error) {
+	if strings.Contains(ipAddress, "/") {
+		_, ipNet, err := net.ParseCIDR(ipAddress)
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ipAddress)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// GetRadiusClientByIp returns the RADIUS client whose IP address or CIDR contains the IP, the exact
+// IP address takes precedence over the CIDR
+func GetRadiusClientByIp(ip net.IP) (*RadiusClient, error) {
+	radiusClients, err := getCachedRadiusClients()
+	if err != nil {
+		return nil, err
+	}
+
+	var res *RadiusClient
+	for _, radiusClient := range radiusClients {
+		if !strings.Contains(radiusClient.IpAddress, "/") {
+			clientIp := net.ParseIP(radiusClient.IpAddress)
+			if clientIp != nil && clientIp.Equal(ip) {
+				return radiusClient, nil
+			}
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(radiusClient.IpAddress)
+		if err == nil && ipNet.Contains(ip) && res == nil {
+			res = radiusClient
+		}
+	}
+
+	return res, nil
+}
+
+// checkRadiusClient validates the RADIUS client with the id, the IP address or CIDR of the client must not
+// overlap with the clients of the other organizations, otherwise the requests from the overlapped IPs
+// could authenticate the users of another organization
+func checkRadiusClient(id string, radiusClient *RadiusClient) error {
+	if radiusClient.Secret == "" {
+		return fmt.Errorf("the secret of the RADIUS client should not be empty")
+	}
+
+	ipNet, err := getRadiusClientIpNet(radiusClient.IpAddress)
+	if err != nil {
+		return fmt.Errorf("the IP address of the RADIUS client: %s is invalid", radiusClient.IpAddress)
+	}
+
+	radiusClients := []*RadiusClient{}
+	err = ormer.Engine.Where("owner != ?", radiusClient.Owner).Find(&radiusClients)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range radiusClients {
+		if other.GetId() == id {
+			continue
+		}
+
+		otherIpNet, err := getRadiusClientIpNet(other.IpAddress)
+		if err != nil {
+			continue
+		}
+
+		if ipNet.Contains(otherIpNet.IP) || otherIpNet.Contains(ipNet.IP) {
+			return fmt.Errorf("the IP address of the RADIUS client: %s overlaps with the RADIUS client: %s of another organization", radiusClient.IpAddress, other.GetId())
+		}
+	}
+
+	return nil
+}
+
+func UpdateRadiusClient(id string, radiusClient *RadiusClient) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if r, err := getRadiusClient(owner, name); err != nil {
+		return false, err
+	} else if r == nil {
+		return false, nil
+	}
+
+	err := checkRadiusClient(id, radiusClient)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(radiusClient)
+	if err != nil {
+		return false, err
+	}
+
+	clearRadiusClientCache()
+
+	return affected != 0, nil
+}
+
+func AddRadiusClient(radiusClient *RadiusClient) (bool, error) {
+	if radiusClient.Secret == "" {
+		radiusClient.Secret = util.GenerateClientSecret()
+	}
+
+	err := checkRadiusClient(radiusClient.GetId(), radiusClient)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.Insert(radiusClient)
+	if err != nil {
+		return false, err
+	}
+
+	clearRadiusClientCache()
+
+	return affected != 0, nil
+}
+
+func DeleteRadiusClient(radiusClient *RadiusClient) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{radiusClient.Owner, radiusClient.Name}).Delete(&RadiusClient{})
+	if err != nil {
+		return false, err
+	}
+
+	clearRadiusClientCache()
+
+	return affected != 0, nil
+}
+
+func (radiusClient *RadiusClient) GetId() string {
+	return fmt.Sprintf("%s/%s", radiusClient.Owner, radiusClient.Name)
+}
@@ -0,0 +1,335 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const radiusVendorSpecificType = 26
+
+// the value types of the RADIUS attributes, "tagged-integer" is the integer with a leading tag of RFC 2868.
+// The multi-valued attributes may appear more than once in Access-Accept, see RFC 2865 section 5.44.
+var radiusAttributeDefinitions = map[string]struct {
+	typ         byte
+	valueType   string
+	multiValued bool
+}{
+	"Filter-Id":               {11, "string", true},
+	"Reply-Message":           {18, "string", true},
+	"Class":                   {25, "string", true},
+	"Session-Timeout":         {27, "integer", false},
+	"Idle-Timeout":            {28, "integer", false},
+	"Tunnel-Type":             {64, "tagged-integer", false},
+	"Tunnel-Medium-Type":      {65, "tagged-integer", false},
+	"Tunnel-Private-Group-Id": {81, "string", false},
+	"Acct-Interim-Interval":   {85, "integer", false},
+}
+
+// RadiusAttribute is a reply attribute of Access-Accept, the vendor ID, the vendor type and the value type are
+// only used by "Vendor-Specific"
+type RadiusAttribute struct {
+	Name       string `json:"name"`
+	VendorId   int    `json:"vendorId"`
+	VendorType int    `json:"vendorType"`
+	ValueType  string `json:"valueType"`
+	Value      string `json:"value"`
+}
+
+// RadiusPolicy grants the reply attributes to the users who are members of any of its roles or groups. Once an
+// organization has an enabled RADIUS policy, the users matching none of its policies are rejected. The policies
+// are matched in the ascending order of the priority, and a single-valued attribute is taken from the first match.
+type RadiusPolicy struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+	Priority    int    `json:"priority"`
+
+	Roles      []string           `xorm:"mediumtext" json:"roles"`
+	Groups     []string           `xorm:"mediumtext" json:"groups"`
+	Attributes []*RadiusAttribute `xorm:"mediumtext" json:"attributes"`
+	IsEnabled  bool               `json:"isEnabled"`
+}
+
+func GetRadiusPolicyCount(owner, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&RadiusPolicy{})
+}
+
+func GetRadiusPolicies(owner string) ([]*RadiusPolicy, error) {
+	radiusPolicies := []*RadiusPolicy{}
+	err := ormer.Engine.Desc("created_time").Find(&radiusPolicies, &RadiusPolicy{Owner: owner})
+	if err != nil {
+		return radiusPolicies, err
+	}
+
+	return radiusPolicies, nil
+}
+
+func GetPaginationRadiusPolicies(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*RadiusPolicy, error) {
+	radiusPolicies := []*RadiusPolicy{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&radiusPolicies)
+	if err != nil {
+		return radiusPolicies, err
+	}
+
+	return radiusPolicies, nil
+}
+
+func getRadiusPolicy(owner string, name string) (*RadiusPolicy, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	radiusPolicy := RadiusPolicy{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&radiusPolicy)
+	if err != nil {
+		return &radiusPolicy, err
+	}
+
+	if existed {
+		return &radiusPolicy, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetRadiusPolicy(id string) (*RadiusPolicy, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getRadiusPolicy(owner, name)
+}
+
+func checkRadiusPolicy(radiusPolicy *RadiusPolicy) error {
+	for _, attribute := range radiusPolicy.Attributes {
+		_, _, err := attribute.Encode()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UpdateRadiusPolicy(id string, radiusPolicy *RadiusPolicy) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if p, err := getRadiusPolicy(owner, name); err != nil {
+		return false, err
+	} else if p == nil {
+		return false, nil
+	}
+
+	err := checkRadiusPolicy(radiusPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(radiusPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func AddRadiusPolicy(radiusPolicy *RadiusPolicy) (bool, error) {
+	err := checkRadiusPolicy(radiusPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := ormer.Engine.Insert(radiusPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteRadiusPolicy(radiusPolicy *RadiusPolicy) (bool, error) {
+	affected, err := ormer.Engine.ID(core.PK{radiusPolicy.Owner, radiusPolicy.Name}).Delete(&RadiusPolicy{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (radiusPolicy *RadiusPolicy) GetId() string {
+	return fmt.Sprintf("%s/%s", radiusPolicy.Owner, radiusPolicy.Name)
+}
+
+func encodeRadiusAttributeValue(valueType string, value string) ([]byte, error) {
+	switch valueType {
+	case "", "string":
+		return []byte(value), nil
+	case "integer":
+		i, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("the value: %s is not a valid integer", value)
+		}
+
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(i))
+		return b, nil
+	case "tagged-integer":
+		i, err := strconv.ParseUint(value, 10, 24)
+		if err != nil {
+			return nil, fmt.Errorf("the value: %s is not a valid integer", value)
+		}
+
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, uint32(i))
+		b[0] = 0
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type: %s", valueType)
+	}
+}
+
+// Encode returns the type and the value of the RADIUS attribute in the wire format
+func (attribute *RadiusAttribute) Encode() (byte, []byte, error) {
+	if attribute.Name == "Vendor-Specific" {
+		if attribute.VendorId <= 0 || attribute.VendorType <= 0 || attribute.VendorType > 255 {
+			return 0, nil, fmt.Errorf("the vendor ID and the vendor type of the RADIUS attribute are invalid")
+		}
+		if attribute.ValueType == "tagged-integer" {
+			return 0, nil, fmt.Errorf("unsupported value type: %s", attribute.ValueType)
+		}
+
+		value, err := encodeRadiusAttributeValue(attribute.ValueType, attribute.Value)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(value) == 0 || len(value) > 247 {
+			return 0, nil, fmt.Errorf("the length of the RADIUS attribute: %s is invalid", attribute.Name)
+		}
+
+		// Vendor-Id (4 bytes), Vendor-Type (1 byte), Vendor-Length (1 byte) and the value, see RFC 2865 section 5.26
+		b := make([]byte, 6, 6+len(value))
+		binary.BigEndian.PutUint32(b, uint32(attribute.VendorId))
+		b[4] = byte(attribute.VendorType)
+		b[5] = byte(2 + len(value))
+		return radiusVendorSpecificType, append(b, value...), nil
+	}
+
+	definition, ok := radiusAttributeDefinitions[attribute.Name]
+	if !ok {
+		return 0, nil, fmt.Errorf("unsupported RADIUS attribute: %s", attribute.Name)
+	}
+
+	value, err := encodeRadiusAttributeValue(definition.valueType, attribute.Value)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(value) == 0 || len(value) > 253 {
+		return 0, nil, fmt.Errorf("the length of the RADIUS attribute: %s is invalid", attribute.Name)
+	}
+
+	return definition.typ, value, nil
+}
+
+// isMultiValued returns whether the attribute may be returned by more than one policy, the vendor-specific
+// attributes are left to the vendors
+func (attribute *RadiusAttribute) isMultiValued() bool {
+	if attribute.Name == "Vendor-Specific" {
+		return true
+	}
+
+	return radiusAttributeDefinitions[attribute.Name].multiValued
+}
+
+func isUserInRadiusPolicy(user *User, roleIds []string, radiusPolicy *RadiusPolicy) bool {
+	return util.HaveIntersection(radiusPolicy.Roles, roleIds) || util.HaveIntersection(radiusPolicy.Groups, user.Groups)
+}
+
+// GetRadiusReplyAttributes returns the reply attributes of the enabled RADIUS policies matched by the roles and
+// the groups of the user, the VLAN assigned by Tunnel-Private-Group-Id is completed with the default tunnel type.
+// The single-valued attributes like the VLAN are taken from the matched policy of the highest priority.
+func GetRadiusReplyAttributes(user *User) ([]*RadiusAttribute, error) {
+	if user.IsForbidden {
+		return nil, fmt.Errorf("the user: %s is forbidden to sign in", user.GetId())
+	}
+
+	radiusPolicies, err := GetRadiusPolicies(user.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	enabledPolicies := []*RadiusPolicy{}
+	for _, radiusPolicy := range radiusPolicies {
+		if radiusPolicy.IsEnabled {
+			enabledPolicies = append(enabledPolicies, radiusPolicy)
+		}
+	}
+	if len(enabledPolicies) == 0 {
+		return []*RadiusAttribute{}, nil
+	}
+
+	sort.SliceStable(enabledPolicies, func(i, j int) bool {
+		return enabledPolicies[i].Priority < enabledPolicies[j].Priority
+	})
+
+	roles, err := getRolesByUser(user.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	roleIds := []string{}
+	for _, role := range roles {
+		roleIds = append(roleIds, role.GetId())
+	}
+
+	matched := false
+	res := []*RadiusAttribute{}
+	names := map[string]bool{}
+	for _, radiusPolicy := range enabledPolicies {
+		if !isUserInRadiusPolicy(user, roleIds, radiusPolicy) {
+			continue
+		}
+
+		matched = true
+		for _, attribute := range radiusPolicy.Attributes {
+			if names[attribute.Name] && !attribute.isMultiValued() {
+				continue
+			}
+
+			res = append(res, attribute)
+			names[attribute.Name] = true
+		}
+	}
+
+	if !matched {
+		return nil, fmt.Errorf("the user: %s doesn't have any role or group of the RADIUS policies", user.GetId())
+	}
+
+	// Tunnel-Type is VLAN (13) and Tunnel-Medium-Type is IEEE-802 (6), see RFC 3580 section 3.31
+	if names["Tunnel-Private-Group-Id"] {
+		if !names["Tunnel-Type"] {
+			res = append(res, &RadiusAttribute{Name: "Tunnel-Type", Value: "13"})
+		}
+		if !names["Tunnel-Medium-Type"] {
+			res = append(res, &RadiusAttribute{Name: "Tunnel-Medium-Type", Value: "6"})
+		}
+	}
+
+	return res, nil
+}
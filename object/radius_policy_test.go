@@ -0,0 +1,69 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRadiusAttributeEncode(t *testing.T) {
+	scenarios := []struct {
+		attribute *RadiusAttribute
+		typ       byte
+		value     []byte
+	}{
+		{&RadiusAttribute{Name: "Session-Timeout", Value: "3600"}, 27, []byte{0, 0, 0x0e, 0x10}},
+		{&RadiusAttribute{Name: "Tunnel-Private-Group-Id", Value: "100"}, 81, []byte("100")},
+		// the tag of the tagged integer is 0, followed by the 3-byte value
+		{&RadiusAttribute{Name: "Tunnel-Type", Value: "13"}, 64, []byte{0, 0, 0, 13}},
+		{&RadiusAttribute{Name: "Tunnel-Medium-Type", Value: "6"}, 65, []byte{0, 0, 0, 6}},
+		// Vendor-Id, Vendor-Type, Vendor-Length and the value
+		{&RadiusAttribute{Name: "Vendor-Specific", VendorId: 9, VendorType: 1, ValueType: "string", Value: "priv-lvl=15"}, 26,
+			append([]byte{0, 0, 0, 9, 1, 13}, []byte("priv-lvl=15")...)},
+		{&RadiusAttribute{Name: "Vendor-Specific", VendorId: 14823, VendorType: 5, ValueType: "integer", Value: "1"}, 26,
+			[]byte{0, 0, 0x39, 0xe7, 5, 6, 0, 0, 0, 1}},
+	}
+
+	for _, scenario := range scenarios {
+		typ, value, err := scenario.attribute.Encode()
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.attribute.Name, err)
+		}
+		if typ != scenario.typ || !bytes.Equal(value, scenario.value) {
+			t.Errorf("%s: Encode() = %d, %v, expected %d, %v", scenario.attribute.Name, typ, value, scenario.typ, scenario.value)
+		}
+	}
+}
+
+func TestRadiusAttributeEncodeInvalid(t *testing.T) {
+	attributes := []*RadiusAttribute{
+		{Name: "Unknown-Attribute", Value: "1"},
+		{Name: "Session-Timeout", Value: "forever"},
+		{Name: "Reply-Message", Value: ""},
+		// the tagged integer has only 3 bytes for the value
+		{Name: "Tunnel-Type", Value: "16777216"},
+		{Name: "Vendor-Specific", VendorId: 9, VendorType: 1, ValueType: "tagged-integer", Value: "1"},
+		{Name: "Vendor-Specific", VendorId: 0, VendorType: 1, Value: "value"},
+		{Name: "Vendor-Specific", VendorId: 9, VendorType: 256, Value: "value"},
+	}
+
+	for _, attribute := range attributes {
+		_, _, err := attribute.Encode()
+		if err == nil {
+			t.Errorf("%s: Encode() should fail for the value: %s", attribute.Name, attribute.Value)
+		}
+	}
+}
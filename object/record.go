@@ -17,11 +17,13 @@ package object
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/beego/beego/context"
 	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/util"
 	"github.com/casvisor/casvisor-go-sdk/casvisorsdk"
+	"github.com/xorm-io/xorm"
 )
 
 var logPostOnly bool
@@ -31,10 +33,44 @@ func init() {
 }
 
 type Record struct {
-	casvisorsdk.Record
+	Id int `xorm:"int notnull pk autoincr" json:"id"`
+
+	Owner       string `xorm:"varchar(100) index" json:"owner"`
+	Name        string `xorm:"varchar(100) index" json:"name"`
+	CreatedTime string `xorm:"varchar(100) index" json:"createdTime"`
+
+	Organization string `xorm:"varchar(100) index" json:"organization"`
+	ClientIp     string `xorm:"varchar(100) index" json:"clientIp"`
+	User         string `xorm:"varchar(100) index" json:"user"`
+	Method       string `xorm:"varchar(100)" json:"method"`
+	RequestUri   string `xorm:"varchar(1000)" json:"requestUri"`
+	Action       string `xorm:"varchar(1000)" json:"action"`
+
+	Object      string `xorm:"mediumtext" json:"object"`
+	IsTriggered bool   `json:"isTriggered"`
+
+	PrevHash string `xorm:"varchar(100)" json:"prevHash"`
+	Hash     string `xorm:"varchar(100)" json:"hash"`
+}
+
+// RecordFilter is the query of the records, the empty fields are not filtered
+type RecordFilter struct {
+	Organization string
+	User         string
+	Action       string
+	ClientIp     string
+	StartTime    string
+	EndTime      string
 }
 
-func NewRecord(ctx *context.Context) *casvisorsdk.Record {
+// the records are appended one by one, so that each record is chained to the hash of the previous one.
+// The replicas are serialized by the row lock of the "record-chain" job lock, which is taken by updating
+// the row in the transaction that reads the last record and inserts the new one.
+const recordChainLockName = "record-chain"
+
+var recordMutex sync.Mutex
+
+func NewRecord(ctx *context.Context) *Record {
 	ip := strings.Replace(util.GetIPFromRequest(ctx.Request), ": ", "", -1)
 	action := strings.Replace(ctx.Request.URL.Path, "/api/", "", -1)
 	requestUri := util.FilterQuery(ctx.Request.RequestURI, []string{"accessToken"})
@@ -47,7 +83,7 @@ func NewRecord(ctx *context.Context) *casvisorsdk.Record {
 		object = string(ctx.Input.RequestBody)
 	}
 
-	record := casvisorsdk.Record{
+	record := Record{
 		Name:        util.GenerateId(),
 		CreatedTime: util.GetCurrentTime(),
 		ClientIp:    ip,
@@ -61,7 +97,117 @@ func NewRecord(ctx *context.Context) *casvisorsdk.Record {
 	return &record
 }
 
-func AddRecord(record *casvisorsdk.Record) bool {
+func (filter *RecordFilter) apply(session *xorm.Session) *xorm.Session {
+	if filter == nil {
+		return session
+	}
+
+	if filter.Organization != "" {
+		session = session.And("organization=?", filter.Organization)
+	}
+	if filter.User != "" {
+		session = session.And("user=?", filter.User)
+	}
+	if filter.Action != "" {
+		session = session.And("action=?", filter.Action)
+	}
+	if filter.ClientIp != "" {
+		session = session.And("client_ip=?", filter.ClientIp)
+	}
+	if filter.StartTime != "" {
+		session = session.And("created_time>=?", filter.StartTime)
+	}
+	if filter.EndTime != "" {
+		session = session.And("created_time<=?", filter.EndTime)
+	}
+	return session
+}
+
+func GetRecordCount(filter *RecordFilter, field, value string) (int64, error) {
+	session := filter.apply(GetSession("", -1, -1, field, value, "", ""))
+	return session.Count(&Record{})
+}
+
+func GetRecords(filter *RecordFilter) ([]*Record, error) {
+	records := []*Record{}
+	err := filter.apply(ormer.Engine.NewSession()).Desc("id").Find(&records)
+	if err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+func GetPaginationRecords(filter *RecordFilter, offset, limit int, field, value, sortField, sortOrder string) ([]*Record, error) {
+	records := []*Record{}
+	if sortField == "" || sortOrder == "" {
+		sortField = "id"
+	}
+
+	session := filter.apply(GetSession("", offset, limit, field, value, sortField, sortOrder))
+	err := session.Find(&records)
+	if err != nil {
+		return records, err
+	}
+
+	return records, nil
+}
+
+// getHash returns the hash of the record content chained to the hash of the previous record,
+// so that modifying or deleting a record in the middle of the log breaks the chain
+func (record *Record) getHash() string {
+	content := *record
+	content.Id = 0
+	content.Hash = ""
+	return util.GetSha256Hash(util.StructToJson(content))
+}
+
+func addRecord(record *Record) error {
+	recordMutex.Lock()
+	defer recordMutex.Unlock()
+
+	_, err := addJobLockIfNotExist(recordChainLockName)
+	if err != nil {
+		return err
+	}
+
+	session := ormer.Engine.NewSession()
+	defer session.Close()
+
+	err = session.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = session.Where("name = ?", recordChainLockName).Cols("locked_by").Update(&JobLock{LockedBy: jobLockHolder})
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	lastRecord := Record{}
+	existed, err := session.Desc("id").Limit(1).Get(&lastRecord)
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	record.PrevHash = ""
+	if existed {
+		record.PrevHash = lastRecord.Hash
+	}
+	record.Hash = record.getHash()
+
+	_, err = session.Insert(record)
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+func AddRecord(record *Record) bool {
 	if logPostOnly {
 		if record.Method == "GET" {
 			return false
@@ -81,16 +227,37 @@ func AddRecord(record *casvisorsdk.Record) bool {
 		fmt.Println(errWebhook)
 	}
 
-	if casvisorsdk.GetClient() == nil {
+	err := addRecord(record)
+	if err != nil {
+		fmt.Printf("AddRecord() error: %s\n", err.Error())
 		return false
 	}
 
-	affected, err := casvisorsdk.AddRecord(record)
-	if err != nil {
-		fmt.Printf("AddRecord() error: %s", err.Error())
+	// casvisor is an optional sink of the records stored locally
+	if casvisorsdk.GetClient() != nil {
+		_, err = casvisorsdk.AddRecord(record.toCasvisorRecord())
+		if err != nil {
+			fmt.Printf("AddRecord() error: %s\n", err.Error())
+		}
 	}
 
-	return affected
+	return true
+}
+
+func (record *Record) toCasvisorRecord() *casvisorsdk.Record {
+	return &casvisorsdk.Record{
+		Owner:        record.Owner,
+		Name:         record.Name,
+		CreatedTime:  record.CreatedTime,
+		Organization: record.Organization,
+		ClientIp:     record.ClientIp,
+		User:         record.User,
+		Method:       record.Method,
+		RequestUri:   record.RequestUri,
+		Action:       record.Action,
+		Object:       record.Object,
+		IsTriggered:  record.IsTriggered,
+	}
 }
 
 func getFilteredWebhooks(webhooks []*Webhook, action string) []*Webhook {
@@ -115,7 +282,7 @@ func getFilteredWebhooks(webhooks []*Webhook, action string) []*Webhook {
 	return res
 }
 
-func SendWebhooks(record *casvisorsdk.Record) error {
+func SendWebhooks(record *Record) error {
 	webhooks, err := getWebhooksByOrganization(record.Organization)
 	if err != nil {
 		return err
@@ -124,6 +291,12 @@ func SendWebhooks(record *casvisorsdk.Record) error {
 	errs := []error{}
 	webhooks = getFilteredWebhooks(webhooks, record.Action)
 	for _, webhook := range webhooks {
+		// the lifecycle events of the entities are sent to the CloudEvents webhooks by the object functions
+		// with the before and after entities
+		if webhook.IsCloudEvents && isEntityEvent(record.Action) {
+			continue
+		}
+
 		var user *User
 		if webhook.IsUserExtended {
 			user, err = getUser(record.Organization, record.User)
@@ -139,7 +312,14 @@ func SendWebhooks(record *casvisorsdk.Record) error {
 			}
 		}
 
-		err = sendWebhook(webhook, record, user)
+		delivery, err := enqueueWebhookDelivery(webhook, record.Action, getWebhookPayload(record, user))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// the failed delivery is retried by the delivery job
+		err = attemptWebhookDelivery(webhook, delivery)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -0,0 +1,157 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+)
+
+type RecordVerification struct {
+	IsValid      bool   `json:"isValid"`
+	Count        int    `json:"count"`
+	BrokenRecord string `json:"brokenRecord"`
+}
+
+// VerifyRecords walks through the hash chain of the records from the oldest one. The oldest record
+// left by the retention policy is trusted as the anchor of the chain.
+func VerifyRecords() (*RecordVerification, error) {
+	batchSize := conf.GetConfigBatchSize()
+	res := &RecordVerification{IsValid: true}
+
+	lastId := 0
+	prevHash := ""
+	for {
+		records := []*Record{}
+		err := ormer.Engine.Where("id>?", lastId).Asc("id").Limit(batchSize).Find(&records)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if record.Hash != record.getHash() || (res.Count != 0 && record.PrevHash != prevHash) {
+				res.IsValid = false
+				res.BrokenRecord = record.Name
+				return res, nil
+			}
+
+			res.Count += 1
+			prevHash = record.Hash
+			lastId = record.Id
+		}
+
+		if len(records) < batchSize {
+			return res, nil
+		}
+	}
+}
+
+// WriteRecords writes the filtered records from the newest one in the format of "jsonl" or "csv",
+// the records are read page by page so that the export doesn't hold all the records in memory
+func WriteRecords(writer io.Writer, filter *RecordFilter, format string) error {
+	var csvWriter *csv.Writer
+	if format == "csv" {
+		csvWriter = csv.NewWriter(writer)
+		err := csvWriter.Write([]string{"id", "owner", "name", "createdTime", "organization", "clientIp", "user", "method", "requestUri", "action", "object", "isTriggered", "prevHash", "hash"})
+		if err != nil {
+			return err
+		}
+	}
+
+	batchSize := conf.GetConfigBatchSize()
+	lastId := 0
+	for {
+		session := filter.apply(ormer.Engine.NewSession())
+		if lastId != 0 {
+			session = session.And("id<?", lastId)
+		}
+
+		records := []*Record{}
+		err := session.Desc("id").Limit(batchSize).Find(&records)
+		session.Close()
+		if err != nil {
+			return err
+		}
+
+		if csvWriter != nil {
+			err = writeRecordsCsv(csvWriter, records)
+		} else {
+			err = writeRecordsJsonl(writer, records)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(records) < batchSize {
+			return nil
+		}
+		lastId = records[len(records)-1].Id
+	}
+}
+
+func writeRecordsJsonl(writer io.Writer, records []*Record) error {
+	for _, record := range records {
+		_, err := io.WriteString(writer, util.StructToJson(record)+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRecordsCsv(csvWriter *csv.Writer, records []*Record) error {
+	for _, record := range records {
+		err := csvWriter.Write([]string{strconv.Itoa(record.Id), record.Owner, record.Name, record.CreatedTime, record.Organization, record.ClientIp, record.User, record.Method, record.RequestUri, record.Action, record.Object, strconv.FormatBool(record.IsTriggered), record.PrevHash, record.Hash})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// deleteExpiredRecords deletes the records older than "recordRetentionDays", the records are kept
+// forever if it is not configured
+func deleteExpiredRecords() (int64, error) {
+	retentionDays, err := conf.GetConfigInt64("recordRetentionDays")
+	if err != nil || retentionDays <= 0 {
+		return 0, nil
+	}
+
+	expireTime := time.Now().AddDate(0, 0, -int(retentionDays)).Format(time.RFC3339)
+	return ormer.Engine.Where("created_time<?", expireTime).Delete(&Record{})
+}
+
+func RunRecordRetentionJob() {
+	ticker := time.NewTicker(time.Hour)
+	for ; true; <-ticker.C {
+		affected, err := deleteExpiredRecords()
+		if err != nil {
+			fmt.Printf("RunRecordRetentionJob() error: %s\n", err.Error())
+			continue
+		}
+
+		if affected != 0 {
+			fmt.Printf("RunRecordRetentionJob(): %d expired records are deleted\n", affected)
+		}
+	}
+}
@@ -188,6 +188,10 @@ func UpdateRole(id string, role *Role) (bool, error) {
 		}
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(oldRole.Owner, "update-role", oldRole.GetId(), oldRole, role)
+	}
+
 	return affected != 0, nil
 }
 
@@ -197,6 +201,10 @@ func AddRole(role *Role) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(role.Owner, "add-role", role.GetId(), nil, role)
+	}
+
 	return affected != 0, nil
 }
 
@@ -239,6 +247,11 @@ func AddRolesInBatch(roles []*Role) bool {
 }
 
 func DeleteRole(role *Role) (bool, error) {
+	oldRole, err := getRole(role.Owner, role.Name)
+	if err != nil {
+		return false, err
+	}
+
 	roleId := role.GetId()
 	permissions, err := GetPermissionsByRole(roleId)
 	if err != nil {
@@ -258,6 +271,10 @@ func DeleteRole(role *Role) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(role.Owner, "delete-role", roleId, oldRole, nil)
+	}
+
 	return affected != 0, nil
 }
 
@@ -265,6 +282,47 @@ func (role *Role) GetId() string {
 	return fmt.Sprintf("%s/%s", role.Owner, role.Name)
 }
 
+// GetRoleUsers returns the users of the organization of the role, who are either assigned to the role
+// or members of the groups of the role
+func GetRoleUsers(role *Role) ([]*User, error) {
+	names := []string{}
+	for _, userId := range role.Users {
+		owner, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+		if owner == role.Owner {
+			names = append(names, name)
+		}
+	}
+
+	users := []*User{}
+	if len(names) != 0 {
+		err := ormer.Engine.Where("owner = ?", role.Owner).In("name", names).Find(&users)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	userIds := map[string]bool{}
+	for _, user := range users {
+		userIds[user.GetId()] = true
+	}
+
+	for _, groupId := range role.Groups {
+		groupUsers, err := GetGroupUsers(groupId)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range groupUsers {
+			if user.Owner == role.Owner && !userIds[user.GetId()] {
+				userIds[user.GetId()] = true
+				users = append(users, user)
+			}
+		}
+	}
+
+	return users, nil
+}
+
 func getRolesByUserInternal(userId string) ([]*Role, error) {
 	roles := []*Role{}
 	user, err := GetUser(userId)
@@ -0,0 +1,375 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+)
+
+// The sign-in throttle counts the failed sign-ins in a sliding window by the client IP, by the user and by the
+// pair of them. Unlike the SigninWrongTimes of the user, the failures of an attacker lock the attacker's IP out
+// first, so that spraying one password across many accounts is slowed down and the victims can still sign in
+// from their own IPs, by solving the CAPTCHA if the failures of the user keep growing.
+//
+// The failures are stored in the database, so that the replicas of a cluster share the throttles and the
+// lockouts listed and cleared by the admin APIs. The throttles without failures in the window are swept,
+// so that spraying random usernames can't grow the table without bound.
+
+const (
+	SigninThrottleTypeIp     = "IP"
+	SigninThrottleTypeUser   = "User"
+	SigninThrottleTypeIpUser = "IP and user"
+
+	signinThrottleWindow      = time.Hour
+	signinThrottleBaseBackoff = time.Minute
+	signinThrottleMaxBackoff  = time.Hour
+	// the base backoff doubled this many times exceeds the max backoff
+	signinThrottleMaxExponent = 6
+)
+
+type signinThrottleRule struct {
+	// the failures in the window after which the CAPTCHA is required
+	captchaLimit int
+	// the failures in the window after which the key is locked, the lock time is doubled by each further failure
+	lockLimit int
+}
+
+var signinThrottleRules = map[string]signinThrottleRule{
+	SigninThrottleTypeIp:     {captchaLimit: 10, lockLimit: 30},
+	SigninThrottleTypeUser:   {captchaLimit: 3, lockLimit: 20},
+	SigninThrottleTypeIpUser: {captchaLimit: 3, lockLimit: 5},
+}
+
+type SigninLockout struct {
+	Owner             string `json:"owner"`
+	Name              string `json:"name"`
+	Type              string `json:"type"`
+	Ip                string `json:"ip"`
+	User              string `json:"user"`
+	FailedTimes       int    `json:"failedTimes"`
+	LastFailedTime    string `json:"lastFailedTime"`
+	LockedUntil       string `json:"lockedUntil"`
+	IsCaptchaRequired bool   `json:"isCaptchaRequired"`
+}
+
+// SigninThrottle is the failed sign-ins of an IP, a user or the pair of them in the window, the owner is empty
+// for the IPs, which are not bound to an organization. The last failed time is in UTC, so that the times of
+// the replicas are compared as strings.
+type SigninThrottle struct {
+	Owner          string      `xorm:"varchar(100) index" json:"owner"`
+	Name           string      `xorm:"varchar(300) notnull pk" json:"name"`
+	Type           string      `xorm:"varchar(100)" json:"type"`
+	Ip             string      `xorm:"varchar(100)" json:"ip"`
+	User           string      `xorm:"varchar(300)" json:"user"`
+	FailedTimes    []time.Time `xorm:"mediumtext" json:"failedTimes"`
+	LastFailedTime string      `xorm:"varchar(100) index" json:"lastFailedTime"`
+}
+
+var (
+	signinThrottleSweepMutex sync.Mutex
+	signinThrottleLastSweep  time.Time
+)
+
+// getSigninThrottleKeys returns the throttles of the sign-in without failures, the IP throttle is skipped for
+// the allow-listed IPs like the corporate egress IPs, where many users share the same IP
+func getSigninThrottleKeys(ip string, organization string, username string, allowList string) []*SigninThrottle {
+	userId := util.GetId(organization, username)
+	keys := []*SigninThrottle{
+		{Owner: organization, Name: fmt.Sprintf("user:%s", userId), Type: SigninThrottleTypeUser, User: userId},
+	}
+	if ip != "" {
+		keys = append(keys, &SigninThrottle{Owner: organization, Name: fmt.Sprintf("ip-user:%s:%s", ip, userId), Type: SigninThrottleTypeIpUser, Ip: ip, User: userId})
+		if !util.IsIpInList(ip, allowList) {
+			keys = append(keys, &SigninThrottle{Name: fmt.Sprintf("ip:%s", ip), Type: SigninThrottleTypeIp, Ip: ip})
+		}
+	}
+	return keys
+}
+
+// getSigninThrottles returns the stored throttles of the keys, the keys that have not failed are returned as is
+func getSigninThrottles(keys []*SigninThrottle) ([]*SigninThrottle, error) {
+	names := []string{}
+	for _, key := range keys {
+		names = append(names, key.Name)
+	}
+
+	throttles := []*SigninThrottle{}
+	err := ormer.Engine.In("name", names).Find(&throttles)
+	if err != nil {
+		return nil, err
+	}
+
+	throttleMap := map[string]*SigninThrottle{}
+	for _, throttle := range throttles {
+		throttleMap[throttle.Name] = throttle
+	}
+
+	res := []*SigninThrottle{}
+	for _, key := range keys {
+		if throttle, ok := throttleMap[key.Name]; ok {
+			res = append(res, throttle)
+		} else {
+			res = append(res, key)
+		}
+	}
+	return res, nil
+}
+
+func (throttle *SigninThrottle) prune(now time.Time) {
+	i := 0
+	for i < len(throttle.FailedTimes) && now.Sub(throttle.FailedTimes[i]) >= signinThrottleWindow {
+		i++
+	}
+	throttle.FailedTimes = throttle.FailedTimes[i:]
+}
+
+func (throttle *SigninThrottle) getLockedUntil() time.Time {
+	count := len(throttle.FailedTimes)
+	lockLimit := signinThrottleRules[throttle.Type].lockLimit
+	if count < lockLimit {
+		return time.Time{}
+	}
+
+	backoff := signinThrottleMaxBackoff
+	if exponent := count - lockLimit; exponent < signinThrottleMaxExponent {
+		backoff = signinThrottleBaseBackoff << uint(exponent)
+		if backoff > signinThrottleMaxBackoff {
+			backoff = signinThrottleMaxBackoff
+		}
+	}
+	return throttle.FailedTimes[count-1].Add(backoff)
+}
+
+func (throttle *SigninThrottle) isCaptchaRequired() bool {
+	return len(throttle.FailedTimes) >= signinThrottleRules[throttle.Type].captchaLimit
+}
+
+func (throttle *SigninThrottle) getSigninLockout(now time.Time) *SigninLockout {
+	lockout := &SigninLockout{
+		Owner:             throttle.Owner,
+		Name:              throttle.Name,
+		Type:              throttle.Type,
+		Ip:                throttle.Ip,
+		User:              throttle.User,
+		FailedTimes:       len(throttle.FailedTimes),
+		IsCaptchaRequired: throttle.isCaptchaRequired(),
+	}
+	if len(throttle.FailedTimes) > 0 {
+		lockout.LastFailedTime = throttle.FailedTimes[len(throttle.FailedTimes)-1].Format(time.RFC3339)
+	}
+	if lockedUntil := throttle.getLockedUntil(); lockedUntil.After(now) {
+		lockout.LockedUntil = lockedUntil.Format(time.RFC3339)
+	}
+	return lockout
+}
+
+// checkSigninThrottle returns the time until which the sign-in is locked, and whether the CAPTCHA is required.
+// The lock of the user is skipped if the CAPTCHA has been solved, so that an attacker can't lock the victim out.
+func checkSigninThrottle(throttles []*SigninThrottle, isCaptchaVerified bool, now time.Time) (time.Time, bool) {
+	lockedUntil := time.Time{}
+	isCaptchaRequired := false
+	for _, throttle := range throttles {
+		throttle.prune(now)
+		if throttle.isCaptchaRequired() {
+			isCaptchaRequired = true
+		}
+		if isCaptchaVerified && throttle.Type == SigninThrottleTypeUser {
+			continue
+		}
+		if throttleLockedUntil := throttle.getLockedUntil(); throttleLockedUntil.After(lockedUntil) {
+			lockedUntil = throttleLockedUntil
+		}
+	}
+	return lockedUntil, isCaptchaRequired
+}
+
+// recordSigninThrottle records the result of the sign-in in the throttles, and returns the throttles to be
+// updated and the ones to be deleted
+func recordSigninThrottle(throttles []*SigninThrottle, isSucceeded bool, now time.Time) ([]*SigninThrottle, []*SigninThrottle) {
+	updated := []*SigninThrottle{}
+	deleted := []*SigninThrottle{}
+	for _, throttle := range throttles {
+		if isSucceeded {
+			// the IP is kept throttled, as a password spraying attack may succeed for some of the users
+			if len(throttle.FailedTimes) != 0 && throttle.Type != SigninThrottleTypeIp {
+				deleted = append(deleted, throttle)
+			}
+			continue
+		}
+
+		throttle.prune(now)
+		throttle.FailedTimes = append(throttle.FailedTimes, now)
+
+		// the failures beyond the max backoff don't change the lock time
+		maxCount := signinThrottleRules[throttle.Type].lockLimit + signinThrottleMaxExponent
+		if len(throttle.FailedTimes) > maxCount {
+			throttle.FailedTimes = throttle.FailedTimes[len(throttle.FailedTimes)-maxCount:]
+		}
+
+		throttle.LastFailedTime = now.UTC().Format(time.RFC3339)
+		updated = append(updated, throttle)
+	}
+	return updated, deleted
+}
+
+// getSigninLockouts returns the lockouts of the throttles that have failed in the window, the most recent first
+func getSigninLockouts(throttles []*SigninThrottle, now time.Time) []*SigninLockout {
+	lockouts := []*SigninLockout{}
+	for _, throttle := range throttles {
+		throttle.prune(now)
+		if len(throttle.FailedTimes) == 0 {
+			continue
+		}
+		lockouts = append(lockouts, throttle.getSigninLockout(now))
+	}
+
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].LastFailedTime > lockouts[j].LastFailedTime
+	})
+	return lockouts
+}
+
+// upsertSigninThrottle stores the throttle, the failures recorded by the replicas at the same time may overwrite
+// each other, which only loosens the throttle by a few failures
+func upsertSigninThrottle(throttle *SigninThrottle) error {
+	affected, err := ormer.Engine.ID(throttle.Name).AllCols().Update(throttle)
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		_, err = ormer.Engine.Insert(throttle)
+	}
+	return err
+}
+
+// sweepSigninThrottles removes the throttles without failures in the window, so that the throttles of the
+// one-off failures don't pile up
+func sweepSigninThrottles(now time.Time) error {
+	signinThrottleSweepMutex.Lock()
+	defer signinThrottleSweepMutex.Unlock()
+
+	if now.Sub(signinThrottleLastSweep) < signinThrottleWindow {
+		return nil
+	}
+
+	_, err := ormer.Engine.Where("last_failed_time < ?", now.Add(-signinThrottleWindow).UTC().Format(time.RFC3339)).Delete(&SigninThrottle{})
+	if err != nil {
+		return err
+	}
+
+	signinThrottleLastSweep = now
+	return nil
+}
+
+// CheckSigninThrottle returns an error if the sign-in of the user from the IP is locked by the previous failures,
+// the options[0] is whether the CAPTCHA has been solved for this sign-in
+func CheckSigninThrottle(ip string, organization string, username string, lang string, options ...bool) error {
+	isCaptchaVerified := false
+	if len(options) > 0 {
+		isCaptchaVerified = options[0]
+	}
+
+	throttles, err := getSigninThrottles(getSigninThrottleKeys(ip, organization, username, conf.GetConfigString("signinThrottleAllowList")))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	lockedUntil, _ := checkSigninThrottle(throttles, isCaptchaVerified, now)
+	if !lockedUntil.After(now) {
+		return nil
+	}
+
+	minutes := int(math.Ceil(lockedUntil.Sub(now).Minutes()))
+	return fmt.Errorf(i18n.Translate(lang, "check:You have entered the wrong password or code too many times, please wait for %d minutes and try again"), minutes)
+}
+
+// IsSigninCaptchaRequired returns whether the failed sign-ins of the user or the IP are enough to require the CAPTCHA
+func IsSigninCaptchaRequired(ip string, organization string, username string) bool {
+	throttles, err := getSigninThrottles(getSigninThrottleKeys(ip, organization, username, conf.GetConfigString("signinThrottleAllowList")))
+	if err != nil {
+		fmt.Printf("IsSigninCaptchaRequired() error: %s\n", err.Error())
+		return false
+	}
+
+	_, isCaptchaRequired := checkSigninThrottle(throttles, false, time.Now())
+	return isCaptchaRequired
+}
+
+// RecordSigninThrottle records the result of the sign-in, a successful sign-in clears the failures of the user
+func RecordSigninThrottle(ip string, organization string, username string, isSucceeded bool) {
+	now := time.Now()
+	err := sweepSigninThrottles(now)
+	if err != nil {
+		fmt.Printf("RecordSigninThrottle() error: %s\n", err.Error())
+	}
+
+	throttles, err := getSigninThrottles(getSigninThrottleKeys(ip, organization, username, conf.GetConfigString("signinThrottleAllowList")))
+	if err != nil {
+		fmt.Printf("RecordSigninThrottle() error: %s\n", err.Error())
+		return
+	}
+
+	updated, deleted := recordSigninThrottle(throttles, isSucceeded, now)
+	for _, throttle := range updated {
+		err = upsertSigninThrottle(throttle)
+		if err != nil {
+			fmt.Printf("RecordSigninThrottle() error: %s\n", err.Error())
+		}
+	}
+	for _, throttle := range deleted {
+		_, err = ormer.Engine.ID(throttle.Name).Delete(&SigninThrottle{})
+		if err != nil {
+			fmt.Printf("RecordSigninThrottle() error: %s\n", err.Error())
+		}
+	}
+}
+
+// GetSigninLockouts returns the throttled sign-ins of the organization, or all of them including the IPs if the
+// owner is empty
+func GetSigninLockouts(owner string) ([]*SigninLockout, error) {
+	now := time.Now()
+	session := ormer.Engine.Where("last_failed_time >= ?", now.Add(-signinThrottleWindow).UTC().Format(time.RFC3339))
+	if owner != "" {
+		session = session.And("owner = ?", owner)
+	}
+
+	throttles := []*SigninThrottle{}
+	err := session.Find(&throttles)
+	if err != nil {
+		return nil, err
+	}
+
+	return getSigninLockouts(throttles, now), nil
+}
+
+// ClearSigninLockout removes the failures of the throttled sign-in, the owner must match the owner of the lockout
+func ClearSigninLockout(lockout *SigninLockout) (bool, error) {
+	affected, err := ormer.Engine.Where("name = ? and owner = ?", lockout.Name, lockout.Owner).Delete(&SigninThrottle{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testSigninThrottles keeps the throttles of the tests in memory instead of the database
+type testSigninThrottles map[string]*SigninThrottle
+
+func (throttles testSigninThrottles) get(ip string, organization string, username string, allowList string) []*SigninThrottle {
+	res := []*SigninThrottle{}
+	for _, key := range getSigninThrottleKeys(ip, organization, username, allowList) {
+		if throttle, ok := throttles[key.Name]; ok {
+			res = append(res, throttle)
+		} else {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+func (throttles testSigninThrottles) check(ip string, organization string, username string, allowList string, isCaptchaVerified bool, now time.Time) (time.Time, bool) {
+	return checkSigninThrottle(throttles.get(ip, organization, username, allowList), isCaptchaVerified, now)
+}
+
+func (throttles testSigninThrottles) record(ip string, organization string, username string, allowList string, isSucceeded bool, now time.Time) {
+	updated, deleted := recordSigninThrottle(throttles.get(ip, organization, username, allowList), isSucceeded, now)
+	for _, throttle := range updated {
+		throttles[throttle.Name] = throttle
+	}
+	for _, throttle := range deleted {
+		delete(throttles, throttle.Name)
+	}
+}
+
+func (throttles testSigninThrottles) getLockouts(owner string, now time.Time) []*SigninLockout {
+	res := []*SigninThrottle{}
+	for _, throttle := range throttles {
+		if owner == "" || throttle.Owner == owner {
+			res = append(res, throttle)
+		}
+	}
+	return getSigninLockouts(res, now)
+}
+
+func TestSigninThrottleIpUser(t *testing.T) {
+	throttles := testSigninThrottles{}
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < signinThrottleRules[SigninThrottleTypeIpUser].lockLimit; i++ {
+		throttles.record("192.0.2.1", "built-in", "alice", "", false, now)
+	}
+
+	// the attacker's IP is locked for the user with the base backoff
+	lockedUntil, isCaptchaRequired := throttles.check("192.0.2.1", "built-in", "alice", "", false, now)
+	if !lockedUntil.Equal(now.Add(signinThrottleBaseBackoff)) || !isCaptchaRequired {
+		t.Errorf("checkSigninThrottle() = %v, %v, expected locked until %v", lockedUntil, isCaptchaRequired, now.Add(signinThrottleBaseBackoff))
+	}
+
+	// the victim is not locked from another IP, but is asked for the CAPTCHA
+	lockedUntil, isCaptchaRequired = throttles.check("198.51.100.1", "built-in", "alice", "", false, now)
+	if lockedUntil.After(now) || !isCaptchaRequired {
+		t.Errorf("checkSigninThrottle() = %v, %v, expected not locked with CAPTCHA", lockedUntil, isCaptchaRequired)
+	}
+
+	// the backoff is doubled by the next failure after the lock
+	now = now.Add(signinThrottleBaseBackoff)
+	throttles.record("192.0.2.1", "built-in", "alice", "", false, now)
+	lockedUntil, _ = throttles.check("192.0.2.1", "built-in", "alice", "", false, now)
+	if !lockedUntil.Equal(now.Add(2 * signinThrottleBaseBackoff)) {
+		t.Errorf("checkSigninThrottle() = %v, expected locked until %v", lockedUntil, now.Add(2*signinThrottleBaseBackoff))
+	}
+
+	// the failures are out of the window
+	now = now.Add(signinThrottleWindow)
+	lockedUntil, isCaptchaRequired = throttles.check("192.0.2.1", "built-in", "alice", "", false, now)
+	if lockedUntil.After(now) || isCaptchaRequired {
+		t.Errorf("checkSigninThrottle() = %v, %v, expected not throttled", lockedUntil, isCaptchaRequired)
+	}
+}
+
+func TestSigninThrottleIp(t *testing.T) {
+	throttles := testSigninThrottles{}
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// one password sprayed across many users from one IP
+	for i := 0; i < signinThrottleRules[SigninThrottleTypeIp].lockLimit; i++ {
+		throttles.record("192.0.2.1", "built-in", fmt.Sprintf("user%d", i), "", false, now)
+		throttles.record("203.0.113.7", "built-in", fmt.Sprintf("user%d", i), "203.0.113.7", false, now)
+	}
+
+	lockedUntil, _ := throttles.check("192.0.2.1", "built-in", "bob", "", false, now)
+	if !lockedUntil.After(now) {
+		t.Errorf("checkSigninThrottle() = %v, expected the IP to be locked", lockedUntil)
+	}
+
+	// the allow-listed IP is not throttled as a whole
+	lockedUntil, isCaptchaRequired := throttles.check("203.0.113.7", "built-in", "bob", "203.0.113.7", false, now)
+	if lockedUntil.After(now) || isCaptchaRequired {
+		t.Errorf("checkSigninThrottle() = %v, %v, expected the allow-listed IP not to be throttled", lockedUntil, isCaptchaRequired)
+	}
+
+	// a successful sign-in doesn't clear the failures of the IP
+	throttles.record("192.0.2.1", "built-in", "bob", "", true, now)
+	lockedUntil, _ = throttles.check("192.0.2.1", "built-in", "bob", "", false, now)
+	if !lockedUntil.After(now) {
+		t.Errorf("checkSigninThrottle() = %v, expected the IP to be locked", lockedUntil)
+	}
+}
+
+func TestSigninThrottleUser(t *testing.T) {
+	throttles := testSigninThrottles{}
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the user is attacked from many IPs
+	for i := 0; i < signinThrottleRules[SigninThrottleTypeUser].lockLimit; i++ {
+		throttles.record(fmt.Sprintf("192.0.2.%d", i), "built-in", "alice", "", false, now)
+	}
+
+	lockedUntil, _ := throttles.check("198.51.100.1", "built-in", "alice", "", false, now)
+	if !lockedUntil.After(now) {
+		t.Errorf("checkSigninThrottle() = %v, expected the user to be locked", lockedUntil)
+	}
+
+	// the victim who has solved the CAPTCHA can still sign in
+	lockedUntil, _ = throttles.check("198.51.100.1", "built-in", "alice", "", true, now)
+	if lockedUntil.After(now) {
+		t.Errorf("checkSigninThrottle() = %v, expected the user not to be locked with CAPTCHA", lockedUntil)
+	}
+
+	lockouts := throttles.getLockouts("built-in", now)
+	if len(lockouts) != signinThrottleRules[SigninThrottleTypeUser].lockLimit+1 {
+		t.Errorf("len(getSigninLockouts()) = %d, expected %d", len(lockouts), signinThrottleRules[SigninThrottleTypeUser].lockLimit+1)
+	}
+
+	// a successful sign-in clears the failures of the user
+	throttles.record("198.51.100.1", "built-in", "alice", "", true, now)
+	lockedUntil, _ = throttles.check("198.51.100.1", "built-in", "alice", "", false, now)
+	if lockedUntil.After(now) {
+		t.Errorf("checkSigninThrottle() = %v, expected the user not to be locked", lockedUntil)
+	}
+}
+
+func TestSigninThrottleLastFailedTime(t *testing.T) {
+	throttles := testSigninThrottles{}
+	now := time.Date(2023, 1, 1, 8, 0, 0, 0, time.FixedZone("UTC+8", 8*60*60))
+
+	// the last failed time is compared as a string by the sweep, so it is in UTC whatever the zone of the replica
+	throttles.record("192.0.2.1", "built-in", "alice", "", false, now)
+	for _, throttle := range throttles {
+		if throttle.LastFailedTime != "2023-01-01T00:00:00Z" {
+			t.Errorf("LastFailedTime = %s, expected 2023-01-01T00:00:00Z", throttle.LastFailedTime)
+		}
+	}
+}
@@ -16,6 +16,7 @@ package object
 
 import (
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
@@ -63,15 +64,21 @@ type Token struct {
 	CodeChallenge    string `xorm:"varchar(100)" json:"codeChallenge"`
 	CodeIsUsed       bool   `json:"codeIsUsed"`
 	CodeExpireIn     int64  `json:"codeExpireIn"`
+	IsRevoked        bool   `json:"isRevoked"`
+
+	// the grant type is only recorded for the client credentials tokens, which are issued to the application itself
+	GrantType string            `xorm:"varchar(100)" json:"grantType"`
+	Cnf       map[string]string `xorm:"varchar(200)" json:"cnf"`
 }
 
 type TokenWrapper struct {
-	AccessToken  string `json:"access_token"`
-	IdToken      string `json:"id_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int    `json:"expires_in"`
-	Scope        string `json:"scope"`
+	AccessToken     string `json:"access_token"`
+	IdToken         string `json:"id_token"`
+	RefreshToken    string `json:"refresh_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	Scope           string `json:"scope"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
 }
 
 type TokenError struct {
@@ -92,6 +99,8 @@ type IntrospectionResponse struct {
 	Aud       []string `json:"aud,omitempty"`
 	Iss       string   `json:"iss,omitempty"`
 	Jti       string   `json:"jti,omitempty"`
+
+	Cnf map[string]string `json:"cnf,omitempty"`
 }
 
 func GetTokenCount(owner, organization, field, value string) (int64, error) {
@@ -283,6 +292,51 @@ func ExpireTokenByAccessToken(accessToken string) (bool, *Application, *Token, e
 	return affected != 0, application, token, nil
 }
 
+// RevokeToken
+// Token Revocation, per rfc 7009. The access token and the refresh token of the same grant are stored
+// in one row, so revoking a refresh token also invalidates the access tokens minted with it.
+func RevokeToken(application *Application, tokenValue string, tokenTypeHint string) (bool, error) {
+	var token *Token
+	var err error
+	if tokenTypeHint == "refresh_token" {
+		token, err = GetTokenByRefreshToken(tokenValue)
+	} else {
+		token, err = GetTokenByAccessToken(tokenValue)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// the hint is only used to speed up the lookup, fall back to the other token type
+	if token == nil {
+		if tokenTypeHint == "refresh_token" {
+			token, err = GetTokenByAccessToken(tokenValue)
+		} else {
+			token, err = GetTokenByRefreshToken(tokenValue)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// invalid tokens and tokens issued to other clients are silently ignored, per rfc 7009 section 2.2
+	if token == nil || token.Owner != application.Owner || token.Application != application.Name {
+		return false, nil
+	}
+
+	if token.IsRevoked {
+		return false, nil
+	}
+
+	token.IsRevoked = true
+	affected, err := ormer.Engine.ID(core.PK{token.Owner, token.Name}).Cols("is_revoked").Update(token)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
 func GetTokenByTokenAndApplication(token string, application string) (*Token, error) {
 	tokenResult := Token{}
 	existed, err := ormer.Engine.Where("(refresh_token = ? or access_token = ? ) and application = ?", token, token, application).Get(&tokenResult)
@@ -297,7 +351,7 @@ func GetTokenByTokenAndApplication(token string, application string) (*Token, er
 	return &tokenResult, nil
 }
 
-func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string, lang string) (string, *Application, error) {
+func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string, requestUri string, lang string) (string, *Application, error) {
 	if responseType != "code" && responseType != "token" && responseType != "id_token" {
 		return fmt.Sprintf(i18n.Translate(lang, "token:Grant_type: %s is not supported in this application"), responseType), nil, nil
 	}
@@ -311,6 +365,10 @@ func CheckOAuthLogin(clientId string, responseType string, redirectUri string, s
 		return i18n.Translate(lang, "token:Invalid client_id"), nil, nil
 	}
 
+	if application.RequirePushedAuthorizationRequests && requestUri == "" {
+		return i18n.Translate(lang, "token:The application requires pushed authorization requests"), application, nil
+	}
+
 	if !application.IsRedirectUriValid(redirectUri) {
 		return fmt.Sprintf(i18n.Translate(lang, "token:Redirect URI: %s doesn't exist in the allowed Redirect URI list"), redirectUri), application, nil
 	}
@@ -320,7 +378,7 @@ func CheckOAuthLogin(clientId string, responseType string, redirectUri string, s
 	return "", application, nil
 }
 
-func GetOAuthCode(userId string, clientId string, responseType string, redirectUri string, scope string, state string, nonce string, challenge string, host string, lang string) (*Code, error) {
+func GetOAuthCode(userId string, clientId string, responseType string, redirectUri string, scope string, state string, nonce string, challenge string, requestUri string, host string, lang string) (*Code, error) {
 	user, err := GetUser(userId)
 	if err != nil {
 		return nil, err
@@ -339,7 +397,7 @@ func GetOAuthCode(userId string, clientId string, responseType string, redirectU
 		}, nil
 	}
 
-	msg, application, err := CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, lang)
+	msg, application, err := CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, requestUri, lang)
 	if err != nil {
 		return nil, err
 	}
@@ -392,52 +450,123 @@ func GetOAuthCode(userId string, clientId string, responseType string, redirectU
 	}, nil
 }
 
-func GetOAuthToken(grantType string, clientId string, clientSecret string, code string, verifier string, scope string, username string, password string, host string, refreshToken string, tag string, avatar string, lang string) (interface{}, error) {
-	application, err := GetApplicationByClientId(clientId)
+// OAuthTokenRequest is the parameters of the token request, the client is authenticated by the client secret,
+// the client assertion or the client certificate, and the tokens are bound to the DPoP key of dpopJkt
+type OAuthTokenRequest struct {
+	GrantType    string
+	ClientId     string
+	ClientSecret string
+	Code         string
+	Verifier     string
+	Scope        string
+	Username     string
+	Password     string
+	Host         string
+	RefreshToken string
+	DeviceCode   string
+
+	SubjectToken     string
+	SubjectTokenType string
+	ActorToken       string
+	ActorTokenType   string
+	Audience         string
+
+	ClientAssertionType string
+	ClientAssertion     string
+	ClientCert          *x509.Certificate
+	DpopJkt             string
+
+	Tag    string
+	Avatar string
+	Lang   string
+}
+
+// prepareTokenRequest looks up the client of the token request and authenticates the client that doesn't use
+// the shared secret. The client id, the client secret and the DPoP key of the request are replaced by the ones
+// the grants check, and the confirmation that the issued tokens are bound to is returned
+func prepareTokenRequest(request *OAuthTokenRequest) (*Application, map[string]string, *TokenError, error) {
+	if request.ClientId == "" && request.ClientAssertion != "" {
+		request.ClientId = getClientIdFromAssertion(request.ClientAssertion)
+	}
+
+	application, err := GetApplicationByClientId(request.ClientId)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	if application == nil {
-		return &TokenError{
+		return nil, nil, &TokenError{
 			Error:            InvalidClient,
 			ErrorDescription: "client_id is invalid",
 		}, nil
 	}
 
+	if !application.usesClientSecret() {
+		tokenError := authenticateClient(application, request.ClientAssertionType, request.ClientAssertion, request.ClientCert, request.Host)
+		if tokenError != nil {
+			return nil, nil, tokenError, nil
+		}
+
+		// the client has been authenticated without the shared secret, which is what the grants check
+		request.ClientSecret = application.ClientSecret
+	}
+
+	if !application.EnableDpop && !application.RequireDpop {
+		// the DPoP proof is ignored if the application doesn't opt in, per rfc 9449 section 5
+		request.DpopJkt = ""
+	} else if application.RequireDpop && request.DpopJkt == "" {
+		return nil, nil, &TokenError{
+			Error:            InvalidDpopProof,
+			ErrorDescription: "the DPoP proof is required by the application",
+		}, nil
+	}
+
+	return application, getTokenConfirmation(application, request.ClientCert, request.DpopJkt), nil, nil
+}
+
+func GetOAuthToken(request *OAuthTokenRequest) (interface{}, error) {
+	application, cnf, tokenError, err := prepareTokenRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenError != nil {
+		return tokenError, nil
+	}
+	clientSecret := request.ClientSecret
+
 	// Check if grantType is allowed in the current application
 
-	if !IsGrantTypeValid(grantType, application.GrantTypes) && tag == "" {
+	if !IsGrantTypeValid(request.GrantType, application.GrantTypes) && request.Tag == "" {
 		return &TokenError{
 			Error:            UnsupportedGrantType,
-			ErrorDescription: fmt.Sprintf("grant_type: %s is not supported in this application", grantType),
+			ErrorDescription: fmt.Sprintf("grant_type: %s is not supported in this application", request.GrantType),
 		}, nil
 	}
 
 	var token *Token
-	var tokenError *TokenError
-	switch grantType {
+	switch request.GrantType {
 	case "authorization_code": // Authorization Code Grant
-		token, tokenError, err = GetAuthorizationCodeToken(application, clientSecret, code, verifier)
+		token, tokenError, err = GetAuthorizationCodeToken(application, clientSecret, request.Code, request.Verifier)
 	case "password": //	Resource Owner Password Credentials Grant
-		token, tokenError, err = GetPasswordToken(application, username, password, scope, host)
+		token, tokenError, err = GetPasswordToken(application, request.Username, request.Password, request.Scope, request.Host)
 	case "client_credentials": // Client Credentials Grant
-		token, tokenError, err = GetClientCredentialsToken(application, clientSecret, scope, host)
+		token, tokenError, err = GetClientCredentialsToken(application, clientSecret, request.Scope, request.Host)
+	case DeviceCodeGrantType: // Device Authorization Grant
+		token, tokenError, err = GetDeviceCodeToken(application, clientSecret, request.DeviceCode, request.Host)
+	case TokenExchangeGrantType: // Token Exchange Grant
+		token, tokenError, err = GetTokenExchangeToken(application, clientSecret, request.SubjectToken, request.SubjectTokenType, request.ActorToken, request.ActorTokenType, request.Audience, request.Scope, request.Host)
 	case "refresh_token":
-		refreshToken2, err := RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host)
-		if err != nil {
-			return nil, err
-		}
-		return refreshToken2, nil
+		return refreshOAuthToken(application, request, cnf)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	if tag == "wechat_miniprogram" {
+	if request.Tag == "wechat_miniprogram" {
 		// Wechat Mini Program
-		token, tokenError, err = GetWechatMiniProgramToken(application, code, host, username, avatar, lang)
+		token, tokenError, err = GetWechatMiniProgramToken(application, request.Code, request.Host, request.Username, request.Avatar, request.Lang)
 		if err != nil {
 			return nil, err
 		}
@@ -447,6 +576,27 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 		return tokenError, nil
 	}
 
+	// the access token is bound to the client certificate and the DPoP key, per rfc 8705 section 3 and rfc 9449 section 6
+	if cnf != nil {
+		// the token of the token exchange is issued for the target application, and is re-signed by its cert
+		tokenApplication := application
+		if token.Owner != application.Owner || token.Application != application.Name {
+			tokenApplication, err = getApplication(token.Owner, token.Application)
+			if err != nil {
+				return nil, err
+			}
+
+			if tokenApplication == nil {
+				return nil, fmt.Errorf("The application: %s does not exist", util.GetId(token.Owner, token.Application))
+			}
+		}
+
+		err = bindAccessToken(tokenApplication, token, cnf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	token.CodeIsUsed = true
 
 	go updateUsedByCode(token)
@@ -459,10 +609,55 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 		ExpiresIn:    token.ExpiresIn,
 		Scope:        token.Scope,
 	}
+	if request.GrantType == TokenExchangeGrantType {
+		tokenWrapper.IssuedTokenType = AccessTokenType
+	}
 
 	return tokenWrapper, nil
 }
 
+// RefreshOAuthToken serves the refresh token endpoint, the client is authenticated and the tokens are bound
+// in the same way as the refresh_token grant of the token endpoint
+func RefreshOAuthToken(request *OAuthTokenRequest) (interface{}, error) {
+	if request.GrantType != "refresh_token" {
+		return &TokenError{
+			Error:            UnsupportedGrantType,
+			ErrorDescription: "grant_type should be refresh_token",
+		}, nil
+	}
+
+	application, cnf, tokenError, err := prepareTokenRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenError != nil {
+		return tokenError, nil
+	}
+
+	return refreshOAuthToken(application, request, cnf)
+}
+
+func refreshOAuthToken(application *Application, request *OAuthTokenRequest, cnf map[string]string) (interface{}, error) {
+	tokenError := checkRefreshTokenDpopKey(request.RefreshToken, request.DpopJkt)
+	if tokenError != nil {
+		return tokenError, nil
+	}
+
+	refreshToken, err := RefreshToken(request.GrantType, request.RefreshToken, request.Scope, request.ClientId, request.ClientSecret, request.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenWrapper, ok := refreshToken.(*TokenWrapper); ok && cnf != nil {
+		err = bindTokenWrapper(application, tokenWrapper, cnf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return refreshToken, nil
+}
+
 func RefreshToken(grantType string, refreshToken string, scope string, clientId string, clientSecret string, host string) (interface{}, error) {
 	// check parameters
 	if grantType != "refresh_token" {
@@ -492,7 +687,7 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 
 	// check whether the refresh token is valid, and has not expired.
 	token, err := GetTokenByRefreshToken(refreshToken)
-	if err != nil || token == nil {
+	if err != nil || token == nil || token.IsRevoked {
 		return &TokenError{
 			Error:            InvalidGrant,
 			ErrorDescription: "refresh token is invalid, expired or revoked",
@@ -510,7 +705,7 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 		}, nil
 	}
 
-	_, err = ParseJwtToken(refreshToken, cert)
+	_, err = ParseJwtTokenByApplication(refreshToken, application)
 	if err != nil {
 		return &TokenError{
 			Error:            InvalidGrant,
@@ -776,6 +971,7 @@ func GetClientCredentialsToken(application *Application, clientSecret string, sc
 		Scope:        scope,
 		TokenType:    "Bearer",
 		CodeIsUsed:   true,
+		GrantType:    "client_credentials",
 	}
 	_, err = AddToken(token)
 	if err != nil {
@@ -0,0 +1,349 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/xorm-io/core"
+)
+
+const (
+	ClientAssertionTypeJwtBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
+	PrivateKeyJwt     = "private_key_jwt"
+	TlsClientAuth     = "tls_client_auth"
+
+	CnfX5tS256 = "x5t#S256"
+)
+
+// the jti of the client assertions that have been used, mapped to their expiration time
+var (
+	usedClientAssertions          sync.Map
+	usedClientAssertionsSweepOnce sync.Once
+)
+
+// sweepExpiredTimes removes the entries mapped to an expiration time from the map on a ticker,
+// instead of walking through the map in each request
+func sweepExpiredTimes(m *sync.Map) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		m.Range(func(key, value interface{}) bool {
+			if now.After(value.(time.Time)) {
+				m.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// usesClientSecret returns whether the application authenticates with the shared client secret,
+// which is the default authentication method
+func (application *Application) usesClientSecret() bool {
+	return application.TokenEndpointAuthMethod == "" || application.TokenEndpointAuthMethod == ClientSecretBasic || application.TokenEndpointAuthMethod == ClientSecretPost
+}
+
+// GetClientCertificate returns the TLS client certificate of the request, which is either presented to
+// Casdoor directly, or forwarded by the reverse proxy in the header configured by "clientCertHeader".
+// The header is only honored if the request comes from a proxy listed in "trustedProxies", and the chain
+// of the forwarded certificate is not validated here, which is delegated to the proxy.
+func GetClientCertificate(request *http.Request) (*x509.Certificate, error) {
+	if request.TLS != nil && len(request.TLS.PeerCertificates) > 0 {
+		return request.TLS.PeerCertificates[0], nil
+	}
+
+	header := conf.GetConfigString("clientCertHeader")
+	if header == "" {
+		return nil, nil
+	}
+
+	remoteIp, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		remoteIp = request.RemoteAddr
+	}
+	if !util.IsIpInList(remoteIp, conf.GetConfigString("trustedProxies")) {
+		return nil, nil
+	}
+
+	value := request.Header.Get(header)
+	if value == "" {
+		return nil, nil
+	}
+
+	// the certificate is url encoded by the reverse proxy, e.g. "$ssl_client_escaped_cert" of nginx
+	value, err = url.QueryUnescape(value)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, fmt.Errorf("the client certificate in the header: %s is not a valid PEM", header)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// getCertificateThumbprint returns the base64url-encoded SHA-256 thumbprint of the certificate, per rfc 8705 section 3.1
+func getCertificateThumbprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getClientIdFromAssertion returns the subject of the client assertion, so that the client can omit client_id
+func getClientIdFromAssertion(clientAssertion string) string {
+	claims := jwt.RegisteredClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(clientAssertion, &claims)
+	if err != nil {
+		return ""
+	}
+
+	return claims.Subject
+}
+
+// verifyClientAssertion verifies the JWT signed by the private key of the client, per rfc 7523 section 3
+func verifyClientAssertion(application *Application, clientAssertionType string, clientAssertion string, host string) error {
+	if clientAssertionType != ClientAssertionTypeJwtBearer {
+		return fmt.Errorf("client_assertion_type: %s is not supported", clientAssertionType)
+	}
+
+	if clientAssertion == "" {
+		return fmt.Errorf("client_assertion should not be empty")
+	}
+
+	keyFunc := getClientJwtKeyFunc(application)
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(clientAssertion, &claims, func(token *jwt.Token) (interface{}, error) {
+		// the HMAC algorithms use the shared secret, which is client_secret_jwt instead of private_key_jwt
+		if strings.HasPrefix(token.Method.Alg(), "HS") {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return keyFunc(token)
+	})
+	if err != nil {
+		return err
+	}
+
+	if claims.Issuer != application.ClientId || claims.Subject != application.ClientId {
+		return fmt.Errorf("the iss and sub of client_assertion should be the client_id")
+	}
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("the exp of client_assertion should not be empty")
+	}
+
+	_, originBackend := getOriginFromHost(host)
+	tokenEndpoint := fmt.Sprintf("%s/api/login/oauth/access_token", originBackend)
+	if !claims.VerifyAudience(originBackend, true) && !claims.VerifyAudience(tokenEndpoint, true) {
+		return fmt.Errorf("the aud of client_assertion should be: %s", tokenEndpoint)
+	}
+
+	// the assertion can only be used once before it expires
+	if claims.ID == "" {
+		return fmt.Errorf("the jti of client_assertion should not be empty")
+	}
+
+	usedClientAssertionsSweepOnce.Do(func() {
+		util.SafeGoroutine(func() { sweepExpiredTimes(&usedClientAssertions) })
+	})
+
+	jti := fmt.Sprintf("%s/%s", application.ClientId, claims.ID)
+	if _, loaded := usedClientAssertions.LoadOrStore(jti, claims.ExpiresAt.Time); loaded {
+		return fmt.Errorf("client_assertion has been used")
+	}
+
+	return nil
+}
+
+// verifyClientCertificate verifies the TLS client certificate by its subject, per rfc 8705 section 2.1.2.
+// The certificate chain is validated by the TLS server or the reverse proxy in front of Casdoor.
+func verifyClientCertificate(application *Application, clientCert *x509.Certificate) error {
+	if clientCert == nil {
+		return fmt.Errorf("the client certificate should not be empty")
+	}
+
+	if application.TlsClientAuthSubjectDn == "" || clientCert.Subject.String() != application.TlsClientAuthSubjectDn {
+		return fmt.Errorf("the subject: %s of the client certificate doesn't match", clientCert.Subject.String())
+	}
+
+	return nil
+}
+
+// authenticateClient authenticates the client with the method configured by the application,
+// which is required to be private_key_jwt or tls_client_auth
+func authenticateClient(application *Application, clientAssertionType string, clientAssertion string, clientCert *x509.Certificate, host string) *TokenError {
+	var err error
+	switch application.TokenEndpointAuthMethod {
+	case PrivateKeyJwt:
+		err = verifyClientAssertion(application, clientAssertionType, clientAssertion, host)
+	case TlsClientAuth:
+		err = verifyClientCertificate(application, clientCert)
+	default:
+		err = fmt.Errorf("token_endpoint_auth_method: %s is not supported", application.TokenEndpointAuthMethod)
+	}
+
+	if err != nil {
+		return &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: fmt.Sprintf("client authentication failed: %s", err.Error()),
+		}
+	}
+
+	return nil
+}
+
+// CheckClientAuthentication authenticates the client with the shared secret, or with the method
+// configured by the application
+func CheckClientAuthentication(application *Application, clientSecret string, clientAssertionType string, clientAssertion string, clientCert *x509.Certificate, host string) *TokenError {
+	if !application.usesClientSecret() {
+		return authenticateClient(application, clientAssertionType, clientAssertion, clientCert, host)
+	}
+
+	if clientSecret == "" || application.ClientSecret != clientSecret {
+		return &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: "client_secret is invalid",
+		}
+	}
+
+	return nil
+}
+
+// getTokenConfirmation returns the confirmation claim for the keys that the issued tokens are bound to,
+// which are the TLS client certificate and the DPoP key, or nil if the tokens are not bound
+func getTokenConfirmation(application *Application, clientCert *x509.Certificate, dpopJkt string) map[string]string {
+	cnf := map[string]string{}
+	if application.TokenEndpointAuthMethod == TlsClientAuth && clientCert != nil {
+		cnf[CnfX5tS256] = getCertificateThumbprint(clientCert)
+	}
+	if dpopJkt != "" {
+		cnf[CnfJkt] = dpopJkt
+	}
+
+	if len(cnf) == 0 {
+		return nil
+	}
+	return cnf
+}
+
+// bindAccessToken adds the confirmation claim to the access token, so that the token can only be used
+// together with the key it is bound to, and stores the confirmation for the introspection
+func bindAccessToken(application *Application, token *Token, cnf map[string]string) error {
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token.AccessToken, claims)
+	if err != nil {
+		return err
+	}
+
+	cert, err := getCertByApplication(application)
+	if err != nil {
+		return err
+	}
+
+	if cert == nil {
+		return fmt.Errorf("The cert \"%s\" does not exist", application.Cert)
+	}
+
+	key, err := cert.getPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	claims["cnf"] = cnf
+	jwtToken := jwt.NewWithClaims(cert.getSigningMethod(), claims)
+	jwtToken.Header["kid"] = cert.Name
+	accessToken, err := jwtToken.SignedString(key)
+	if err != nil {
+		return err
+	}
+
+	token.AccessToken = accessToken
+	token.AccessTokenHash = getTokenHash(accessToken)
+	token.Cnf = cnf
+
+	// the token bound to a DPoP key is sent in the "Authorization: DPoP" header, per rfc 9449 section 5
+	if _, ok := cnf[CnfJkt]; ok {
+		token.TokenType = DpopTokenType
+	}
+
+	_, err = ormer.Engine.ID(core.PK{token.Owner, token.Name}).Cols("access_token", "access_token_hash", "token_type", "cnf").Update(token)
+	return err
+}
+
+func bindTokenWrapper(application *Application, tokenWrapper *TokenWrapper, cnf map[string]string) error {
+	token, err := GetTokenByAccessToken(tokenWrapper.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return fmt.Errorf("the access token doesn't exist")
+	}
+
+	err = bindAccessToken(application, token, cnf)
+	if err != nil {
+		return err
+	}
+
+	tokenWrapper.AccessToken = token.AccessToken
+	tokenWrapper.IdToken = token.AccessToken
+	tokenWrapper.TokenType = token.TokenType
+	return nil
+}
+
+// CheckTokenConfirmation checks that the request presents the keys the access token is bound to
+func CheckTokenConfirmation(token *Token, request *http.Request) error {
+	if thumbprint, ok := token.Cnf[CnfX5tS256]; ok {
+		clientCert, err := GetClientCertificate(request)
+		if err != nil {
+			return err
+		}
+
+		if clientCert == nil || getCertificateThumbprint(clientCert) != thumbprint {
+			return fmt.Errorf("the access token is bound to another client certificate")
+		}
+	}
+
+	if _, ok := token.Cnf[CnfJkt]; ok {
+		if !strings.HasPrefix(request.Header.Get("Authorization"), DpopTokenType+" ") {
+			return fmt.Errorf("the access token is bound to a DPoP key, and should be sent with the DPoP authorization scheme")
+		}
+
+		err := VerifyDpopProofForToken(token, request.Header.Get("DPoP"), request.Method, getDpopRequestUrl(request))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	AuthorizationPending = "authorization_pending"
+	SlowDown             = "slow_down"
+	AccessDenied         = "access_denied"
+	ExpiredToken         = "expired_token"
+
+	deviceCodeExpireInSeconds = 300
+	deviceCodeInterval        = 5
+
+	// user codes avoid vowels and look-alike characters, per rfc 8628 section 6.1
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+)
+
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type DeviceAuthCache struct {
+	Application string
+	Scope       string
+	UserCode    string
+	UserId      string
+	IsApproved  bool
+	IsDenied    bool
+	Interval    int
+	ExpireAt    time.Time
+	LastPollAt  time.Time
+
+	// guards the approval and the polling state, which are changed by the concurrent requests
+	mutex sync.Mutex
+}
+
+// deviceCodeToCache maps a device code to its pending authorization
+var deviceCodeToCache sync.Map
+
+// userCodeToDeviceCode maps a user code to the device code it was issued with
+var userCodeToDeviceCode sync.Map
+
+func generateUserCode() (string, error) {
+	res := make([]byte, 8)
+	for i := range res {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharset))))
+		if err != nil {
+			return "", err
+		}
+		res[i] = userCodeCharset[n.Int64()]
+	}
+
+	return fmt.Sprintf("%s-%s", res[:4], res[4:]), nil
+}
+
+// normalizeUserCode makes the user code case-insensitive and ignores the dash or spaces typed by the user
+func normalizeUserCode(userCode string) string {
+	userCode = strings.ToUpper(userCode)
+	userCode = strings.NewReplacer("-", "", " ", "").Replace(userCode)
+	if len(userCode) != 8 {
+		return userCode
+	}
+
+	return fmt.Sprintf("%s-%s", userCode[:4], userCode[4:])
+}
+
+func deleteDeviceAuthCache(deviceCode string, cache *DeviceAuthCache) {
+	deviceCodeToCache.Delete(deviceCode)
+	userCodeToDeviceCode.Delete(cache.UserCode)
+}
+
+func purgeExpiredDeviceAuthCaches() {
+	now := time.Now()
+	deviceCodeToCache.Range(func(key, value interface{}) bool {
+		cache := value.(*DeviceAuthCache)
+		if now.After(cache.ExpireAt) {
+			deleteDeviceAuthCache(key.(string), cache)
+		}
+		return true
+	})
+}
+
+// GetDeviceAuthorization
+// Device Authorization Request, per rfc 8628 section 3.1
+func GetDeviceAuthorization(clientId string, scope string, host string) (interface{}, error) {
+	application, err := GetApplicationByClientId(clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	if application == nil {
+		return &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: "client_id is invalid",
+		}, nil
+	}
+
+	if !IsGrantTypeValid(DeviceCodeGrantType, application.GrantTypes) {
+		return &TokenError{
+			Error:            UnauthorizedClient,
+			ErrorDescription: fmt.Sprintf("grant_type: %s is not supported in this application", DeviceCodeGrantType),
+		}, nil
+	}
+
+	purgeExpiredDeviceAuthCaches()
+
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCode := util.GenerateClientSecret()
+	cache := &DeviceAuthCache{
+		Application: application.GetId(),
+		Scope:       scope,
+		UserCode:    userCode,
+		Interval:    deviceCodeInterval,
+		ExpireAt:    time.Now().Add(time.Second * deviceCodeExpireInSeconds),
+	}
+	deviceCodeToCache.Store(deviceCode, cache)
+	userCodeToDeviceCode.Store(userCode, deviceCode)
+
+	originFrontend, _ := getOriginFromHost(host)
+	verificationUri := fmt.Sprintf("%s/login/oauth/device", originFrontend)
+
+	return &DeviceAuthResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationUri:         verificationUri,
+		VerificationUriComplete: fmt.Sprintf("%s?user_code=%s", verificationUri, userCode),
+		ExpiresIn:               deviceCodeExpireInSeconds,
+		Interval:                deviceCodeInterval,
+	}, nil
+}
+
+func getDeviceAuthCacheByUserCode(userCode string) (string, *DeviceAuthCache) {
+	deviceCode, ok := userCodeToDeviceCode.Load(normalizeUserCode(userCode))
+	if !ok {
+		return "", nil
+	}
+
+	value, ok := deviceCodeToCache.Load(deviceCode)
+	if !ok {
+		return "", nil
+	}
+
+	cache := value.(*DeviceAuthCache)
+	if time.Now().After(cache.ExpireAt) {
+		deleteDeviceAuthCache(deviceCode.(string), cache)
+		return "", nil
+	}
+
+	return deviceCode.(string), cache
+}
+
+// GetDeviceAuthApplication returns the application that requested the given user code, so that
+// the verification page can show the user which client is asking for access
+func GetDeviceAuthApplication(userCode string) (*Application, error) {
+	_, cache := getDeviceAuthCacheByUserCode(userCode)
+	if cache == nil {
+		return nil, nil
+	}
+
+	application, err := GetApplication(cache.Application)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetMaskedApplication(application, ""), nil
+}
+
+// VerifyDeviceCode binds the user code to the signed-in user, or denies the request when approved is false
+func VerifyDeviceCode(userCode string, user *User, approved bool) (bool, error) {
+	_, cache := getDeviceAuthCacheByUserCode(userCode)
+	if cache == nil {
+		return false, nil
+	}
+
+	if user.IsForbidden {
+		return false, fmt.Errorf("the user: %s is forbidden to sign in, please contact the administrator", user.GetId())
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.IsApproved || cache.IsDenied {
+		return false, nil
+	}
+
+	if approved {
+		cache.UserId = user.GetId()
+		cache.IsApproved = true
+	} else {
+		cache.IsDenied = true
+	}
+
+	return true, nil
+}
+
+// poll returns the error of the authorization that is still pending and updates the polling state, or nil
+// if the authorization has been completed or has expired
+func (cache *DeviceAuthCache) poll(now time.Time) *TokenError {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.IsApproved || cache.IsDenied || now.After(cache.ExpireAt) {
+		return nil
+	}
+
+	lastPollAt := cache.LastPollAt
+	cache.LastPollAt = now
+	if now.Sub(lastPollAt) < time.Duration(cache.Interval)*time.Second {
+		// the client must add 5 seconds to its polling interval, per rfc 8628 section 3.5
+		cache.Interval += deviceCodeInterval
+		return &TokenError{
+			Error:            SlowDown,
+			ErrorDescription: fmt.Sprintf("the polling interval should be at least %d seconds", cache.Interval),
+		}
+	}
+
+	return &TokenError{
+		Error:            AuthorizationPending,
+		ErrorDescription: "the user has not yet completed the authorization",
+	}
+}
+
+// GetDeviceCodeToken
+// Device Access Token Request, per rfc 8628 section 3.4
+func GetDeviceCodeToken(application *Application, clientSecret string, deviceCode string, host string) (*Token, *TokenError, error) {
+	if deviceCode == "" {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: "device_code should not be empty",
+		}, nil
+	}
+
+	// device clients are usually public clients, but if the secret is provided, it must be accurate.
+	if clientSecret != "" && application.ClientSecret != clientSecret {
+		return nil, &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: "client_secret is invalid",
+		}, nil
+	}
+
+	value, ok := deviceCodeToCache.Load(deviceCode)
+	if !ok {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "device_code is invalid",
+		}, nil
+	}
+
+	cache := value.(*DeviceAuthCache)
+	if cache.Application != application.GetId() {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the device_code is for wrong application (client_id)",
+		}, nil
+	}
+
+	now := time.Now()
+	if tokenError := cache.poll(now); tokenError != nil {
+		return nil, tokenError, nil
+	}
+
+	// the device code is redeemed only once, the concurrent requests with it don't get another token
+	if _, ok := deviceCodeToCache.LoadAndDelete(deviceCode); !ok {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "device_code is invalid",
+		}, nil
+	}
+	userCodeToDeviceCode.Delete(cache.UserCode)
+
+	cache.mutex.Lock()
+	isApproved, userId := cache.IsApproved, cache.UserId
+	cache.mutex.Unlock()
+
+	if now.After(cache.ExpireAt) {
+		return nil, &TokenError{
+			Error:            ExpiredToken,
+			ErrorDescription: "device_code has expired",
+		}, nil
+	}
+
+	if !isApproved {
+		return nil, &TokenError{
+			Error:            AccessDenied,
+			ErrorDescription: "the authorization request was denied by the user",
+		}, nil
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: fmt.Sprintf("the user: %s doesn't exist", userId),
+		}, nil
+	}
+
+	if user.IsForbidden {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the user is forbidden to sign in, please contact the administrator",
+		}, nil
+	}
+
+	token, err := GetTokenByUser(application, user, cache.Scope, "", host)
+	if err != nil {
+		return nil, &TokenError{
+			Error:            EndpointError,
+			ErrorDescription: fmt.Sprintf("generate jwt token error: %s", err.Error()),
+		}, nil
+	}
+
+	return token, nil, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func addTestDeviceAuthCache(application *Application, deviceCode string, userCode string, expireAt time.Time) *DeviceAuthCache {
+	cache := &DeviceAuthCache{
+		Application: application.GetId(),
+		UserCode:    userCode,
+		Interval:    deviceCodeInterval,
+		ExpireAt:    expireAt,
+	}
+	deviceCodeToCache.Store(deviceCode, cache)
+	userCodeToDeviceCode.Store(userCode, deviceCode)
+	return cache
+}
+
+func getDeviceCodeTokenError(t *testing.T, application *Application, deviceCode string) string {
+	token, tokenError, err := GetDeviceCodeToken(application, "", deviceCode, "")
+	if err != nil {
+		t.Fatalf("GetDeviceCodeToken() error: %v", err)
+	}
+	if token != nil || tokenError == nil {
+		t.Fatalf("GetDeviceCodeToken() = %v, %v, expected a token error", token, tokenError)
+	}
+	return tokenError.Error
+}
+
+func TestDeviceCodeTokenPolling(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app-device-polling"}
+	cache := addTestDeviceAuthCache(application, "device-code-polling", "BCDF-GHJK", time.Now().Add(deviceCodeExpireInSeconds*time.Second))
+	defer deleteDeviceAuthCache("device-code-polling", cache)
+
+	// the first poll is pending until the user completes the authorization
+	if res := getDeviceCodeTokenError(t, application, "device-code-polling"); res != AuthorizationPending {
+		t.Errorf("GetDeviceCodeToken() = %s, expected %s", res, AuthorizationPending)
+	}
+
+	// polling again within the interval slows the client down, and the interval grows by 5 seconds
+	if res := getDeviceCodeTokenError(t, application, "device-code-polling"); res != SlowDown {
+		t.Errorf("GetDeviceCodeToken() = %s, expected %s", res, SlowDown)
+	}
+	if cache.Interval != 2*deviceCodeInterval {
+		t.Errorf("cache.Interval = %d, expected %d", cache.Interval, 2*deviceCodeInterval)
+	}
+
+	// polling after the grown interval is pending again
+	cache.LastPollAt = time.Now().Add(-time.Duration(cache.Interval) * time.Second)
+	if res := getDeviceCodeTokenError(t, application, "device-code-polling"); res != AuthorizationPending {
+		t.Errorf("GetDeviceCodeToken() = %s, expected %s", res, AuthorizationPending)
+	}
+
+	// the device code of another application is rejected
+	if res := getDeviceCodeTokenError(t, &Application{Owner: "admin", Name: "app-another"}, "device-code-polling"); res != InvalidGrant {
+		t.Errorf("GetDeviceCodeToken() = %s, expected %s", res, InvalidGrant)
+	}
+}
+
+func TestDeviceCodeTokenExpired(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app-device-expired"}
+	addTestDeviceAuthCache(application, "device-code-expired", "LMNP-QRST", time.Now().Add(-time.Second))
+
+	if res := getDeviceCodeTokenError(t, application, "device-code-expired"); res != ExpiredToken {
+		t.Errorf("GetDeviceCodeToken() = %s, expected %s", res, ExpiredToken)
+	}
+
+	// the expired device code is removed with its user code
+	if _, ok := deviceCodeToCache.Load("device-code-expired"); ok {
+		t.Errorf("the expired device code should be removed")
+	}
+	if _, ok := userCodeToDeviceCode.Load("LMNP-QRST"); ok {
+		t.Errorf("the user code of the expired device code should be removed")
+	}
+}
+
+func TestDeviceCodeTokenRedeemedOnce(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app-device-redeemed"}
+	addTestDeviceAuthCache(application, "device-code-redeemed", "VWXZ-BCDF", time.Now().Add(deviceCodeExpireInSeconds*time.Second))
+
+	ok, err := VerifyDeviceCode("vwxz bcdf", &User{Owner: "built-in", Name: "alice"}, false)
+	if err != nil || !ok {
+		t.Fatalf("VerifyDeviceCode() = %v, %v, expected the user code to be denied", ok, err)
+	}
+
+	// the concurrent requests with the completed device code are answered only once
+	results := make([]string, 10)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, tokenError, err := GetDeviceCodeToken(application, "", "device-code-redeemed", "")
+			if err == nil && tokenError != nil {
+				results[i] = tokenError.Error
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	deniedCount := 0
+	for _, res := range results {
+		switch res {
+		case AccessDenied:
+			deniedCount++
+		case InvalidGrant:
+		default:
+			t.Errorf("GetDeviceCodeToken() = %s, expected %s or %s", res, AccessDenied, InvalidGrant)
+		}
+	}
+	if deniedCount != 1 {
+		t.Errorf("the device code was redeemed %d times, expected once", deniedCount)
+	}
+
+	// the user code can't be verified again after the device code is redeemed
+	ok, err = VerifyDeviceCode("VWXZ-BCDF", &User{Owner: "built-in", Name: "alice"}, true)
+	if err != nil || ok {
+		t.Errorf("VerifyDeviceCode() = %v, %v, expected the redeemed user code to be invalid", ok, err)
+	}
+}
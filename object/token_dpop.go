@@ -0,0 +1,212 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const (
+	DpopTokenType    = "DPoP"
+	InvalidDpopProof = "invalid_dpop_proof"
+
+	CnfJkt = "jkt"
+
+	// the proof is accepted if its iat is within this window around the current time
+	dpopProofMaxAgeSeconds = 300
+)
+
+type DpopClaims struct {
+	Htm string `json:"htm"`
+	Htu string `json:"htu"`
+	Ath string `json:"ath,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// the jti of the DPoP proofs that have been used, mapped to their expiration time
+var (
+	usedDpopProofs          sync.Map
+	usedDpopProofsSweepOnce sync.Once
+)
+
+func getDpopRequestUrl(request *http.Request) string {
+	_, originBackend := getOriginFromHost(request.Host)
+	return originBackend + request.URL.Path
+}
+
+func getAccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func normalizeDpopUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+
+	// the query and fragment parts are ignored, per rfc 9449 section 4.3
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), strings.ToLower(u.Host), u.Path)
+}
+
+// verifyDpopProof verifies the DPoP proof for the http method and url, and returns the JWK SHA-256
+// thumbprint of the public key that signed it, per rfc 9449 section 4.3. The access token is checked
+// against the ath claim if it is not empty.
+func verifyDpopProof(proof string, method string, requestUrl string, accessToken string) (string, error) {
+	var jwk jose.JSONWebKey
+	claims := DpopClaims{}
+	_, err := jwt.ParseWithClaims(proof, &claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, fmt.Errorf("the typ of the DPoP proof should be dpop+jwt")
+		}
+
+		if strings.HasPrefix(token.Method.Alg(), "HS") {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		data, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal(data, &jwk)
+		if err != nil {
+			return nil, fmt.Errorf("the jwk of the DPoP proof is invalid: %s", err.Error())
+		}
+
+		if !jwk.Valid() || !jwk.IsPublic() {
+			return nil, fmt.Errorf("the jwk of the DPoP proof should be a public key")
+		}
+
+		return jwk.Key, nil
+	}, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return "", err
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return "", fmt.Errorf("the jti and iat of the DPoP proof should not be empty")
+	}
+
+	if !strings.EqualFold(claims.Htm, method) {
+		return "", fmt.Errorf("the htm: %s of the DPoP proof doesn't match the request method: %s", claims.Htm, method)
+	}
+
+	if normalizeDpopUrl(claims.Htu) != normalizeDpopUrl(requestUrl) {
+		return "", fmt.Errorf("the htu: %s of the DPoP proof doesn't match the request url: %s", claims.Htu, requestUrl)
+	}
+
+	now := time.Now()
+	maxAge := dpopProofMaxAgeSeconds * time.Second
+	if claims.IssuedAt.Time.Before(now.Add(-maxAge)) || claims.IssuedAt.Time.After(now.Add(maxAge)) {
+		return "", fmt.Errorf("the iat of the DPoP proof is out of the acceptable window")
+	}
+
+	if accessToken != "" && claims.Ath != getAccessTokenHash(accessToken) {
+		return "", fmt.Errorf("the ath of the DPoP proof doesn't match the access token")
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	// each proof can only be used once within the acceptable window
+	usedDpopProofsSweepOnce.Do(func() {
+		util.SafeGoroutine(func() { sweepExpiredTimes(&usedDpopProofs) })
+	})
+
+	if _, loaded := usedDpopProofs.LoadOrStore(fmt.Sprintf("%s/%s", jkt, claims.ID), claims.IssuedAt.Time.Add(2*maxAge)); loaded {
+		return "", fmt.Errorf("the DPoP proof has been used")
+	}
+
+	return jkt, nil
+}
+
+// GetDpopJkt verifies the DPoP proof in the header of the token request, and returns the thumbprint
+// of the key that the issued tokens will be bound to, or an empty string if there is no proof
+func GetDpopJkt(request *http.Request) (string, *TokenError) {
+	proof := request.Header.Get("DPoP")
+	if proof == "" {
+		return "", nil
+	}
+
+	jkt, err := verifyDpopProof(proof, request.Method, getDpopRequestUrl(request), "")
+	if err != nil {
+		return "", &TokenError{
+			Error:            InvalidDpopProof,
+			ErrorDescription: err.Error(),
+		}
+	}
+
+	return jkt, nil
+}
+
+// checkRefreshTokenDpopKey checks that the refresh token issued together with a DPoP-bound access token
+// is used with the same DPoP key, per rfc 9449 section 5
+func checkRefreshTokenDpopKey(refreshToken string, dpopJkt string) *TokenError {
+	token, err := GetTokenByRefreshToken(refreshToken)
+	if err != nil || token == nil {
+		// the refresh token itself is checked by the refresh token grant
+		return nil
+	}
+
+	if jkt, ok := token.Cnf[CnfJkt]; ok && jkt != dpopJkt {
+		return &TokenError{
+			Error:            InvalidDpopProof,
+			ErrorDescription: "the refresh token is bound to another DPoP key",
+		}
+	}
+
+	return nil
+}
+
+// VerifyDpopProofForToken checks that the DPoP proof for the http method and url is signed by the key
+// that the access token is bound to
+func VerifyDpopProofForToken(token *Token, proof string, method string, requestUrl string) error {
+	expectedJkt, ok := token.Cnf[CnfJkt]
+	if !ok {
+		return nil
+	}
+
+	if proof == "" {
+		return fmt.Errorf("the access token is bound to a DPoP key, but the DPoP proof is empty")
+	}
+
+	jkt, err := verifyDpopProof(proof, method, requestUrl, token.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	if jkt != expectedJkt {
+		return fmt.Errorf("the DPoP proof is signed by another key than the access token is bound to")
+	}
+
+	return nil
+}
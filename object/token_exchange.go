@@ -0,0 +1,304 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const (
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+	JwtTokenType    = "urn:ietf:params:oauth:token-type:jwt"
+
+	InvalidTarget = "invalid_target"
+)
+
+func isTokenTypeSupported(tokenType string) bool {
+	return tokenType == AccessTokenType || tokenType == JwtTokenType
+}
+
+// IsTokenExchangeAudienceValid checks whether the application is allowed to exchange tokens
+// for the audience, which is the client id of the downstream application
+func (application *Application) IsTokenExchangeAudienceValid(audience string) bool {
+	for _, item := range application.TokenExchangeAudiences {
+		if item == audience || item == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// isScopeSubset checks that every scope in the requested scope has been granted, so that an exchanged
+// token can only be narrower than the subject token
+func isScopeSubset(scope string, grantedScope string) bool {
+	granted := strings.Fields(grantedScope)
+	for _, item := range strings.Fields(scope) {
+		if !util.InSlice(granted, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// getExchangeableToken looks up an access token issued by Casdoor and verifies that it is still active
+func getExchangeableToken(tokenValue string) (*Token, *Claims, error) {
+	token, err := GetTokenByAccessToken(tokenValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token == nil || token.IsRevoked {
+		return nil, nil, nil
+	}
+
+	if isExpired, _ := util.IsTokenExpired(token.CreatedTime, token.ExpiresIn); isExpired {
+		return nil, nil, nil
+	}
+
+	application, err := getApplication(token.Owner, token.Application)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if application == nil {
+		return nil, nil, nil
+	}
+
+	claims, err := ParseJwtTokenByApplication(tokenValue, application)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	return token, claims, nil
+}
+
+// getTokenRemainingSeconds returns the seconds before the token expires
+func getTokenRemainingSeconds(token *Token) int {
+	createdTime, _ := time.Parse(time.RFC3339, token.CreatedTime)
+	return int(time.Until(createdTime.Add(time.Duration(token.ExpiresIn) * time.Second)).Seconds())
+}
+
+// isActorAuthorized checks that the actor of actor_token is allowed to act for the subject of subject_token.
+// The actor token must be presented to the client itself, and the actor is either the client itself by the
+// client credentials, the subject itself, or an administrator of the organization of the subject.
+func isActorAuthorized(application *Application, actorToken *Token, subjectToken *Token) (bool, error) {
+	if actorToken.Owner != application.Owner || actorToken.Application != application.Name {
+		return false, nil
+	}
+
+	if actorToken.GrantType == "client_credentials" {
+		return true, nil
+	}
+
+	if actorToken.Organization == subjectToken.Organization && actorToken.User == subjectToken.User {
+		return true, nil
+	}
+
+	actorUser, err := getUser(actorToken.Organization, actorToken.User)
+	if err != nil {
+		return false, err
+	}
+
+	if actorUser == nil || actorUser.IsForbidden {
+		return false, nil
+	}
+
+	return actorUser.IsGlobalAdmin() || (actorUser.IsAdmin && actorUser.Owner == subjectToken.Organization), nil
+}
+
+// GetTokenExchangeToken
+// Token Exchange flow, per rfc 8693
+func GetTokenExchangeToken(application *Application, clientSecret string, subjectToken string, subjectTokenType string, actorToken string, actorTokenType string, audience string, scope string, host string) (*Token, *TokenError, error) {
+	if application.ClientSecret != clientSecret {
+		return nil, &TokenError{
+			Error:            InvalidClient,
+			ErrorDescription: "client_secret is invalid",
+		}, nil
+	}
+
+	if subjectToken == "" || subjectTokenType == "" {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: "subject_token and subject_token_type should not be empty",
+		}, nil
+	}
+
+	if !isTokenTypeSupported(subjectTokenType) {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: fmt.Sprintf("subject_token_type: %s is not supported", subjectTokenType),
+		}, nil
+	}
+
+	// actor_token_type is required with actor_token, and must not be sent without it, per rfc 8693 section 2.1
+	if actorToken == "" && actorTokenType != "" {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: "actor_token_type should not be sent without actor_token",
+		}, nil
+	}
+
+	if actorToken != "" && !isTokenTypeSupported(actorTokenType) {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: fmt.Sprintf("actor_token_type: %s is not supported", actorTokenType),
+		}, nil
+	}
+
+	targetApplication := application
+	if audience != "" && audience != application.ClientId {
+		if !application.IsTokenExchangeAudienceValid(audience) {
+			return nil, &TokenError{
+				Error:            InvalidTarget,
+				ErrorDescription: fmt.Sprintf("the application is not allowed to exchange tokens for the audience: %s", audience),
+			}, nil
+		}
+
+		var err error
+		targetApplication, err = GetApplicationByClientId(audience)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if targetApplication == nil {
+			return nil, &TokenError{
+				Error:            InvalidTarget,
+				ErrorDescription: fmt.Sprintf("the audience: %s doesn't exist", audience),
+			}, nil
+		}
+	}
+
+	token, claims, err := getExchangeableToken(subjectToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// only the tokens presented to the client itself can be exchanged
+	if token == nil || token.Owner != application.Owner || token.Application != application.Name {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "subject_token is invalid, expired or revoked",
+		}, nil
+	}
+
+	if scope == "" {
+		scope = token.Scope
+	} else if !isScopeSubset(scope, token.Scope) {
+		return nil, &TokenError{
+			Error:            InvalidScope,
+			ErrorDescription: "the requested scope exceeds the scope granted to subject_token",
+		}, nil
+	}
+
+	// the exchanged token doesn't outlive the subject token, nor the actor token
+	expiresIn := targetApplication.ExpireInHours * hourSeconds
+	if remainingSeconds := getTokenRemainingSeconds(token); remainingSeconds < expiresIn {
+		expiresIn = remainingSeconds
+	}
+
+	actor := &ActorClaims{
+		Subject: application.ClientId,
+		Act:     claims.Act,
+	}
+	if actorToken != "" {
+		actorTokenObj, actorClaims, err := getExchangeableToken(actorToken)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if actorTokenObj == nil {
+			return nil, &TokenError{
+				Error:            InvalidGrant,
+				ErrorDescription: "actor_token is invalid, expired or revoked",
+			}, nil
+		}
+
+		isAuthorized, err := isActorAuthorized(application, actorTokenObj, token)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !isAuthorized {
+			return nil, &TokenError{
+				Error:            InvalidGrant,
+				ErrorDescription: "the actor of actor_token is not allowed to act for the subject of subject_token",
+			}, nil
+		}
+
+		actor.Subject = actorClaims.Subject
+		if remainingSeconds := getTokenRemainingSeconds(actorTokenObj); remainingSeconds < expiresIn {
+			expiresIn = remainingSeconds
+		}
+	}
+
+	user, err := getUser(token.Organization, token.User)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the subject of subject_token is not a user",
+		}, nil
+	}
+
+	if user.IsForbidden {
+		return nil, &TokenError{
+			Error:            InvalidGrant,
+			ErrorDescription: "the user is forbidden to sign in, please contact the administrator",
+		}, nil
+	}
+
+	err = ExtendUserWithRolesAndPermissions(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, _, tokenName, err := generateJwtTokenWithActor(targetApplication, user, "", scope, host, actor, expiresIn)
+	if err != nil {
+		return nil, &TokenError{
+			Error:            EndpointError,
+			ErrorDescription: fmt.Sprintf("generate jwt token error: %s", err.Error()),
+		}, nil
+	}
+
+	newToken := &Token{
+		Owner:        targetApplication.Owner,
+		Name:         tokenName,
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  targetApplication.Name,
+		Organization: user.Owner,
+		User:         user.Name,
+		Code:         util.GenerateClientId(),
+		AccessToken:  accessToken,
+		ExpiresIn:    expiresIn,
+		Scope:        scope,
+		TokenType:    "Bearer",
+		CodeIsUsed:   true,
+	}
+	_, err = AddToken(newToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newToken, nil, nil
+}
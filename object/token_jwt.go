@@ -22,12 +22,20 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// ActorClaims is the "act" claim of a delegated token, per rfc 8693 section 4.1.
+// Nested actors describe the chain of delegation, the outermost actor is the current one.
+type ActorClaims struct {
+	Subject string       `json:"sub"`
+	Act     *ActorClaims `json:"act,omitempty"`
+}
+
 type Claims struct {
 	*User
-	TokenType string `json:"tokenType,omitempty"`
-	Nonce     string `json:"nonce,omitempty"`
-	Tag       string `json:"tag"`
-	Scope     string `json:"scope,omitempty"`
+	TokenType string       `json:"tokenType,omitempty"`
+	Nonce     string       `json:"nonce,omitempty"`
+	Tag       string       `json:"tag"`
+	Scope     string       `json:"scope,omitempty"`
+	Act       *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -131,18 +139,20 @@ type UserWithoutThirdIdp struct {
 
 type ClaimsShort struct {
 	*UserShort
-	TokenType string `json:"tokenType,omitempty"`
-	Nonce     string `json:"nonce,omitempty"`
-	Scope     string `json:"scope,omitempty"`
+	TokenType string       `json:"tokenType,omitempty"`
+	Nonce     string       `json:"nonce,omitempty"`
+	Scope     string       `json:"scope,omitempty"`
+	Act       *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type ClaimsWithoutThirdIdp struct {
 	*UserWithoutThirdIdp
-	TokenType string `json:"tokenType,omitempty"`
-	Nonce     string `json:"nonce,omitempty"`
-	Tag       string `json:"tag"`
-	Scope     string `json:"scope,omitempty"`
+	TokenType string       `json:"tokenType,omitempty"`
+	Nonce     string       `json:"nonce,omitempty"`
+	Tag       string       `json:"tag"`
+	Scope     string       `json:"scope,omitempty"`
+	Act       *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -253,6 +263,7 @@ func getShortClaims(claims Claims) ClaimsShort {
 		TokenType:        claims.TokenType,
 		Nonce:            claims.Nonce,
 		Scope:            claims.Scope,
+		Act:              claims.Act,
 		RegisteredClaims: claims.RegisteredClaims,
 	}
 	return res
@@ -265,6 +276,7 @@ func getClaimsWithoutThirdIdp(claims Claims) ClaimsWithoutThirdIdp {
 		Nonce:               claims.Nonce,
 		Tag:                 claims.Tag,
 		Scope:               claims.Scope,
+		Act:                 claims.Act,
 		RegisteredClaims:    claims.RegisteredClaims,
 	}
 	return res
@@ -293,8 +305,12 @@ func refineUser(user *User) *User {
 }
 
 func generateJwtToken(application *Application, user *User, nonce string, scope string, host string) (string, string, string, error) {
+	return generateJwtTokenWithActor(application, user, nonce, scope, host, nil, application.ExpireInHours*hourSeconds)
+}
+
+func generateJwtTokenWithActor(application *Application, user *User, nonce string, scope string, host string, actor *ActorClaims, expiresIn int) (string, string, string, error) {
 	nowTime := time.Now()
-	expireTime := nowTime.Add(time.Duration(application.ExpireInHours) * time.Hour)
+	expireTime := nowTime.Add(time.Duration(expiresIn) * time.Second)
 	refreshExpireTime := nowTime.Add(time.Duration(application.RefreshExpireInHours) * time.Hour)
 	if application.RefreshExpireInHours == 0 {
 		refreshExpireTime = expireTime
@@ -314,6 +330,7 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 		// FIXME: A workaround for custom claim by reusing `tag` in user info
 		Tag:   user.Tag,
 		Scope: scope,
+		Act:   actor,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    originBackend,
 			Subject:   user.Id,
@@ -325,6 +342,21 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 		},
 	}
 
+	cert, err := getCertByApplication(application)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if cert == nil {
+		if application.Cert == "" {
+			return "", "", "", fmt.Errorf("The cert field of the application \"%s\" should not be empty", application.GetId())
+		} else {
+			return "", "", "", fmt.Errorf("The cert \"%s\" does not exist", application.Cert)
+		}
+	}
+
+	signingMethod := cert.getSigningMethod()
+
 	var token *jwt.Token
 	var refreshToken *jwt.Token
 
@@ -332,34 +364,20 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 	if application.TokenFormat == "JWT-Empty" {
 		claimsShort := getShortClaims(claims)
 
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
+		token = jwt.NewWithClaims(signingMethod, claimsShort)
 		claimsShort.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
 		claimsShort.TokenType = "refresh-token"
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
+		refreshToken = jwt.NewWithClaims(signingMethod, claimsShort)
 	} else {
 		claimsWithoutThirdIdp := getClaimsWithoutThirdIdp(claims)
 
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsWithoutThirdIdp)
+		token = jwt.NewWithClaims(signingMethod, claimsWithoutThirdIdp)
 		claimsWithoutThirdIdp.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
 		claimsWithoutThirdIdp.TokenType = "refresh-token"
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsWithoutThirdIdp)
-	}
-
-	cert, err := getCertByApplication(application)
-	if err != nil {
-		return "", "", "", err
+		refreshToken = jwt.NewWithClaims(signingMethod, claimsWithoutThirdIdp)
 	}
 
-	if cert == nil {
-		if application.Cert == "" {
-			return "", "", "", fmt.Errorf("The cert field of the application \"%s\" should not be empty", application.GetId())
-		} else {
-			return "", "", "", fmt.Errorf("The cert \"%s\" does not exist", application.Cert)
-		}
-	}
-
-	// RSA private key
-	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cert.PrivateKey))
+	key, err := cert.getPrivateKey()
 	if err != nil {
 		return "", "", "", err
 	}
@@ -376,21 +394,11 @@ func generateJwtToken(application *Application, user *User, nonce string, scope
 
 func ParseJwtToken(token string, cert *Cert) (*Claims, error) {
 	t, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		if !cert.isSigningMethodAccepted(token.Method.Alg()) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		if cert.Certificate == "" {
-			return nil, fmt.Errorf("the certificate field should not be empty for the cert: %v", cert)
-		}
-
-		// RSA certificate
-		certificate, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cert.Certificate))
-		if err != nil {
-			return nil, err
-		}
-
-		return certificate, nil
+		return cert.getPublicKey()
 	})
 
 	if t != nil {
@@ -408,5 +416,14 @@ func ParseJwtTokenByApplication(token string, application *Application) (*Claims
 		return nil, err
 	}
 
+	if cert == nil {
+		return nil, fmt.Errorf("The cert \"%s\" does not exist", application.Cert)
+	}
+
+	cert, err = getCertByKid(token, cert)
+	if err != nil {
+		return nil, err
+	}
+
 	return ParseJwtToken(token, cert)
 }
@@ -15,13 +15,20 @@
 package object
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"time"
+
+	"github.com/golang-jwt/jwt/v4"
 )
 
 func generateRsaKeys(bitSize int, expireInYears int, commonName string, organization string) (string, string, error) {
@@ -42,6 +49,95 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		},
 	)
 
+	certPem, err := generateCertificate(&key.PublicKey, key, expireInYears, commonName, organization)
+	if err != nil {
+		return "", "", err
+	}
+
+	return certPem, string(privateKeyPem), nil
+}
+
+// getEsCurve returns the curve of the ECDSA algorithm, the curves of JWS are fixed by the algorithms, see:
+// https://www.rfc-editor.org/rfc/rfc7518#section-3.4. "ES521" is an alias of "ES512", which uses P-521.
+func getEsCurve(cryptoAlgorithm string) (elliptic.Curve, error) {
+	switch cryptoAlgorithm {
+	case "ES256":
+		return elliptic.P256(), nil
+	case "ES384":
+		return elliptic.P384(), nil
+	case "ES512", "ES521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported crypto algorithm for ECDSA: %s", cryptoAlgorithm)
+	}
+}
+
+func generateEsKeys(cryptoAlgorithm string, bitSize int, expireInYears int, commonName string, organization string) (string, string, error) {
+	curve, err := getEsCurve(cryptoAlgorithm)
+	if err != nil {
+		return "", "", err
+	}
+
+	if bitSize != curve.Params().BitSize {
+		return "", "", fmt.Errorf("the bit size of %s should be %d, but got: %d", cryptoAlgorithm, curve.Params().BitSize, bitSize)
+	}
+
+	// Generate ECDSA key.
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Encode private key to SEC 1 ASN.1 PEM.
+	privateKeyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKeyPem := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: privateKeyBytes,
+		},
+	)
+
+	certPem, err := generateCertificate(&key.PublicKey, key, expireInYears, commonName, organization)
+	if err != nil {
+		return "", "", err
+	}
+
+	return certPem, string(privateKeyPem), nil
+}
+
+func generateEdKeys(expireInYears int, commonName string, organization string) (string, string, error) {
+	// Generate Ed25519 key.
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Encode private key to PKCS#8 ASN.1 PEM.
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKeyPem := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privateKeyBytes,
+		},
+	)
+
+	certPem, err := generateCertificate(publicKey, key, expireInYears, commonName, organization)
+	if err != nil {
+		return "", "", err
+	}
+
+	return certPem, string(privateKeyPem), nil
+}
+
+func generateCertificate(publicKey crypto.PublicKey, privateKey crypto.Signer, expireInYears int, commonName string, organization string) (string, error) {
 	tml := x509.Certificate{
 		// you can add any attr that you need
 		NotBefore: time.Now(),
@@ -55,9 +151,9 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		BasicConstraintsValid: true,
 	}
 
-	cert, err := x509.CreateCertificate(rand.Reader, &tml, &tml, &key.PublicKey, key)
+	cert, err := x509.CreateCertificate(rand.Reader, &tml, &tml, publicKey, privateKey)
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 
 	// Generate a pem block with the certificate
@@ -66,5 +162,130 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		Bytes: cert,
 	})
 
-	return string(certPem), string(privateKeyPem), nil
+	return string(certPem), nil
+}
+
+// generateKeys generates the certificate and private key according to the crypto algorithm of the cert,
+// RSA keys are used for the RSA-PSS algorithms as well as for the legacy algorithms
+func generateKeys(cryptoAlgorithm string, bitSize int, expireInYears int, commonName string, organization string) (string, string, error) {
+	switch cryptoAlgorithm {
+	case "ES256", "ES384", "ES512", "ES521":
+		return generateEsKeys(cryptoAlgorithm, bitSize, expireInYears, commonName, organization)
+	case "EdDSA":
+		return generateEdKeys(expireInYears, commonName, organization)
+	default:
+		return generateRsaKeys(bitSize, expireInYears, commonName, organization)
+	}
+}
+
+func getSigningMethod(cryptoAlgorithm string) jwt.SigningMethod {
+	switch cryptoAlgorithm {
+	case "RS384":
+		return jwt.SigningMethodRS384
+	case "RS512":
+		return jwt.SigningMethodRS512
+	case "PS256":
+		return jwt.SigningMethodPS256
+	case "PS384":
+		return jwt.SigningMethodPS384
+	case "PS512":
+		return jwt.SigningMethodPS512
+	case "ES256":
+		return jwt.SigningMethodES256
+	case "ES384":
+		return jwt.SigningMethodES384
+	case "ES512", "ES521":
+		return jwt.SigningMethodES512
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA
+	default:
+		// certs created before the crypto algorithm was honored always contain RSA keys
+		return jwt.SigningMethodRS256
+	}
+}
+
+// getSigningMethod returns the signing method of the crypto algorithm of the cert, as long as it fits the key
+// in the certificate. The certs created before the crypto algorithm was honored may be labeled with an ECDSA
+// or EdDSA algorithm but contain RSA keys, and keep signing with RS256.
+func (p *Cert) getSigningMethod() jwt.SigningMethod {
+	signingMethod := getSigningMethod(p.CryptoAlgorithm)
+
+	publicKey, err := p.getPublicKey()
+	if err != nil {
+		return signingMethod
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if isRsaSigningMethod(signingMethod) {
+			return signingMethod
+		}
+		return jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		switch key.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256
+		case 384:
+			return jwt.SigningMethodES384
+		case 521:
+			return jwt.SigningMethodES512
+		}
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return signingMethod
+}
+
+func isRsaSigningMethod(signingMethod jwt.SigningMethod) bool {
+	switch signingMethod.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSigningMethodAccepted returns whether the tokens signed with the algorithm are verified by the cert, only the
+// algorithm of the cert is accepted to avoid the algorithm confusion attacks, except that the tokens issued with
+// RS256 before the crypto algorithm was honored stay valid for the RSA keys
+func (p *Cert) isSigningMethodAccepted(alg string) bool {
+	signingMethod := p.getSigningMethod()
+	if alg == signingMethod.Alg() {
+		return true
+	}
+
+	return alg == jwt.SigningMethodRS256.Alg() && isRsaSigningMethod(signingMethod)
+}
+
+func (p *Cert) getPrivateKey() (crypto.PrivateKey, error) {
+	switch p.getSigningMethod().(type) {
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPrivateKeyFromPEM([]byte(p.PrivateKey))
+	case *jwt.SigningMethodEd25519:
+		return jwt.ParseEdPrivateKeyFromPEM([]byte(p.PrivateKey))
+	default:
+		return jwt.ParseRSAPrivateKeyFromPEM([]byte(p.PrivateKey))
+	}
+}
+
+func (p *Cert) getPublicKey() (crypto.PublicKey, error) {
+	if p.Certificate == "" {
+		return nil, fmt.Errorf("the certificate field should not be empty for the cert: %v", p)
+	}
+
+	block, _ := pem.Decode([]byte(p.Certificate))
+	if block == nil {
+		return nil, fmt.Errorf("the certificate of the cert: %s is not a valid PEM", p.GetId())
+	}
+
+	if block.Type != "CERTIFICATE" {
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificate.PublicKey, nil
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 func TestGenerateRsaKeys(t *testing.T) {
@@ -34,3 +35,126 @@ func TestGenerateRsaKeys(t *testing.T) {
 	// Write private key to file.
 	util.WriteStringToPath(privateKey, fmt.Sprintf("%s.key", fileId))
 }
+
+func TestSignWithCryptoAlgorithms(t *testing.T) {
+	scenarios := []struct {
+		cryptoAlgorithm string
+		bitSize         int
+	}{
+		{"RS256", 2048},
+		{"PS256", 2048},
+		{"ES256", 256},
+		{"ES384", 384},
+		{"ES521", 521},
+		{"EdDSA", 0},
+	}
+
+	for _, scenario := range scenarios {
+		cert := &Cert{
+			Owner:           "admin",
+			Name:            "cert-" + scenario.cryptoAlgorithm,
+			CryptoAlgorithm: scenario.cryptoAlgorithm,
+			BitSize:         scenario.bitSize,
+			ExpireInYears:   1,
+		}
+
+		err := cert.populateContent()
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.cryptoAlgorithm, err)
+		}
+
+		key, err := cert.getPrivateKey()
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.cryptoAlgorithm, err)
+		}
+
+		claims := Claims{User: &User{Owner: "built-in", Name: "admin"}}
+		tokenString, err := jwt.NewWithClaims(cert.getSigningMethod(), claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.cryptoAlgorithm, err)
+		}
+
+		res, err := ParseJwtToken(tokenString, cert)
+		if err != nil {
+			t.Fatalf("%s: %v", scenario.cryptoAlgorithm, err)
+		}
+
+		if res.User.Name != "admin" {
+			t.Errorf("%s: got user %s, expected admin", scenario.cryptoAlgorithm, res.User.Name)
+		}
+	}
+}
+
+func TestGenerateEsKeysWithMismatchedBitSize(t *testing.T) {
+	scenarios := []struct {
+		cryptoAlgorithm string
+		bitSize         int
+	}{
+		{"ES256", 384},
+		{"ES384", 256},
+		{"ES512", 256},
+		{"ES521", 0},
+	}
+
+	for _, scenario := range scenarios {
+		_, _, err := generateEsKeys(scenario.cryptoAlgorithm, scenario.bitSize, 1, "Casdoor Cert", "Casdoor Organization")
+		if err == nil {
+			t.Errorf("generateEsKeys(%s, %d) should fail for the mismatched bit size", scenario.cryptoAlgorithm, scenario.bitSize)
+		}
+	}
+}
+
+func TestSignWithLegacyCerts(t *testing.T) {
+	certificate, privateKey, err := generateRsaKeys(2048, 1, "Casdoor Cert", "Casdoor Organization")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the certs created before the crypto algorithm was honored contain RSA keys and issued RS256 tokens
+	claims := Claims{User: &User{Owner: "built-in", Name: "admin"}}
+	legacyTokenString, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		cryptoAlgorithm string
+		expectedAlg     string
+	}{
+		{"RS256", "RS256"},
+		{"RS512", "RS512"},
+		{"PS256", "PS256"},
+		{"ES256", "RS256"},
+		{"ES512", "RS256"},
+		{"EdDSA", "RS256"},
+	}
+
+	for _, scenario := range scenarios {
+		cert := &Cert{
+			Owner:           "admin",
+			Name:            "cert-" + scenario.cryptoAlgorithm,
+			CryptoAlgorithm: scenario.cryptoAlgorithm,
+			Certificate:     certificate,
+			PrivateKey:      privateKey,
+		}
+
+		if alg := cert.getSigningMethod().Alg(); alg != scenario.expectedAlg {
+			t.Errorf("%s: getSigningMethod() = %s, expected %s", scenario.cryptoAlgorithm, alg, scenario.expectedAlg)
+		}
+
+		_, err = cert.getPrivateKey()
+		if err != nil {
+			t.Errorf("%s: %v", scenario.cryptoAlgorithm, err)
+		}
+
+		_, err = ParseJwtToken(legacyTokenString, cert)
+		if err != nil {
+			t.Errorf("%s: the legacy token should be accepted: %v", scenario.cryptoAlgorithm, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/i18n"
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	RequestUriPrefix = "urn:ietf:params:oauth:request_uri:"
+
+	InvalidRequestObject = "invalid_request_object"
+
+	// the request uri is used by both the login page and the login API, so it lives long enough for the user to sign in
+	requestUriExpireInSeconds = 600
+)
+
+// OAuthRequest is the authorization request pushed to the PAR endpoint or passed as a request object
+type OAuthRequest struct {
+	ClientId            string `json:"client_id"`
+	ResponseType        string `json:"response_type"`
+	RedirectUri         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	CodeChallenge       string `json:"code_challenge"`
+
+	ExpireAt time.Time `json:"-"`
+}
+
+type ParResponse struct {
+	RequestUri string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+var (
+	requestUriToOAuthRequest sync.Map
+	requestUriSweepOnce      sync.Once
+)
+
+func getClaimString(claims jwt.MapClaims, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+// parseRequestObject verifies the request object signed by the client and returns the authorization
+// request in it, per rfc 9101
+func parseRequestObject(application *Application, request string, host string) (*OAuthRequest, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(request, claims, getClientJwtKeyFunc(application))
+	if err != nil {
+		return nil, err
+	}
+
+	if clientId := getClaimString(claims, "client_id"); clientId != "" && clientId != application.ClientId {
+		return nil, fmt.Errorf("the client_id: %s of the request object doesn't match the client", clientId)
+	}
+
+	if iss := getClaimString(claims, "iss"); iss != "" && iss != application.ClientId {
+		return nil, fmt.Errorf("the iss: %s of the request object doesn't match the client", iss)
+	}
+
+	_, originBackend := getOriginFromHost(host)
+	if _, ok := claims["aud"]; ok && !claims.VerifyAudience(originBackend, true) {
+		return nil, fmt.Errorf("the aud of the request object should be: %s", originBackend)
+	}
+
+	return &OAuthRequest{
+		ClientId:            application.ClientId,
+		ResponseType:        getClaimString(claims, "response_type"),
+		RedirectUri:         getClaimString(claims, "redirect_uri"),
+		Scope:               getClaimString(claims, "scope"),
+		State:               getClaimString(claims, "state"),
+		Nonce:               getClaimString(claims, "nonce"),
+		CodeChallengeMethod: getClaimString(claims, "code_challenge_method"),
+		CodeChallenge:       getClaimString(claims, "code_challenge"),
+	}, nil
+}
+
+// PushAuthorizationRequest
+// Pushed Authorization Requests, per rfc 9126. The request of the authenticated client is stored
+// by the server and referred to by the returned request uri in the authorization request
+func PushAuthorizationRequest(application *Application, oAuthRequest *OAuthRequest, request string, host string) (*ParResponse, *TokenError, error) {
+	// the parameters in the request object take precedence over the form parameters, per rfc 9126 section 3
+	if request != "" {
+		var err error
+		oAuthRequest, err = parseRequestObject(application, request, host)
+		if err != nil {
+			return nil, &TokenError{
+				Error:            InvalidRequestObject,
+				ErrorDescription: err.Error(),
+			}, nil
+		}
+	}
+
+	if oAuthRequest.ResponseType == "" {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: "response_type should not be empty",
+		}, nil
+	}
+
+	if !application.IsRedirectUriValid(oAuthRequest.RedirectUri) {
+		return nil, &TokenError{
+			Error:            InvalidRequest,
+			ErrorDescription: fmt.Sprintf("redirect_uri: %s doesn't exist in the allowed Redirect URI list", oAuthRequest.RedirectUri),
+		}, nil
+	}
+
+	oAuthRequest.ClientId = application.ClientId
+	oAuthRequest.ExpireAt = time.Now().Add(requestUriExpireInSeconds * time.Second)
+
+	requestUriSweepOnce.Do(func() {
+		util.SafeGoroutine(sweepExpiredOAuthRequests)
+	})
+
+	requestUri := RequestUriPrefix + util.GenerateClientId()
+	requestUriToOAuthRequest.Store(requestUri, oAuthRequest)
+
+	return &ParResponse{
+		RequestUri: requestUri,
+		ExpiresIn:  requestUriExpireInSeconds,
+	}, nil, nil
+}
+
+// sweepExpiredOAuthRequests removes the pushed requests that have expired without being used
+func sweepExpiredOAuthRequests() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		requestUriToOAuthRequest.Range(func(key, value interface{}) bool {
+			if now.After(value.(*OAuthRequest).ExpireAt) {
+				requestUriToOAuthRequest.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// GetOAuthRequest resolves the authorization request referred to by the request uri,
+// or passed by value as a request object
+func GetOAuthRequest(clientId string, requestUri string, request string, host string, lang string) (*OAuthRequest, string, error) {
+	if requestUri != "" {
+		if !strings.HasPrefix(requestUri, RequestUriPrefix) {
+			return nil, i18n.Translate(lang, "token:The request_uri is invalid or has expired"), nil
+		}
+
+		value, ok := requestUriToOAuthRequest.Load(requestUri)
+		if !ok {
+			return nil, i18n.Translate(lang, "token:The request_uri is invalid or has expired"), nil
+		}
+
+		oAuthRequest := value.(*OAuthRequest)
+		if time.Now().After(oAuthRequest.ExpireAt) {
+			requestUriToOAuthRequest.Delete(requestUri)
+			return nil, i18n.Translate(lang, "token:The request_uri is invalid or has expired"), nil
+		}
+
+		if oAuthRequest.ClientId != clientId {
+			return nil, i18n.Translate(lang, "token:Invalid client_id"), nil
+		}
+
+		return oAuthRequest, "", nil
+	}
+
+	application, err := GetApplicationByClientId(clientId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if application == nil {
+		return nil, i18n.Translate(lang, "token:Invalid client_id"), nil
+	}
+
+	oAuthRequest, err := parseRequestObject(application, request, host)
+	if err != nil {
+		return nil, fmt.Sprintf(i18n.Translate(lang, "token:The request object is invalid: %s"), err.Error()), nil
+	}
+
+	return oAuthRequest, "", nil
+}
+
+// DeleteOAuthRequest makes the request uri one-time use once the authorization code has been issued
+func DeleteOAuthRequest(requestUri string) {
+	requestUriToOAuthRequest.Delete(requestUri)
+}
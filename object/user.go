@@ -194,6 +194,11 @@ type User struct {
 	LastSigninWrongTime string `xorm:"varchar(100)" json:"lastSigninWrongTime"`
 	SigninWrongTimes    int    `json:"signinWrongTimes"`
 
+	PasswordHistory     []string `xorm:"mediumtext" json:"-"`
+	PasswordChangedTime string   `xorm:"varchar(100)" json:"passwordChangedTime"`
+	// the NT hash of the password for MS-CHAPv2, which is kept for the hashed passwords if the organization enables it
+	NtPasswordHash string `xorm:"varchar(100)" json:"-"`
+
 	ManagedAccounts []ManagedAccount `xorm:"managedAccounts blob" json:"managedAccounts"`
 }
 
@@ -247,6 +252,36 @@ func GetGlobalUsersWithFilter(cond builder.Cond) ([]*User, error) {
 	return users, nil
 }
 
+func GetGlobalUserCountWithFilter(cond builder.Cond) (int64, error) {
+	session := ormer.Engine.Prepare()
+	if cond != nil {
+		session = session.Where(cond)
+	}
+	return session.Count(&User{})
+}
+
+func GetPaginationGlobalUsersWithFilter(offset, limit int, cond builder.Cond, sortField, sortOrder string) ([]*User, error) {
+	users := []*User{}
+	session := ormer.Engine.Limit(limit, offset)
+	if cond != nil {
+		session = session.Where(cond)
+	}
+	if sortField == "" || sortOrder == "" {
+		sortField = "created_time"
+	}
+	if sortOrder == "ascend" {
+		session = session.Asc(util.SnakeString(sortField))
+	} else {
+		session = session.Desc(util.SnakeString(sortField))
+	}
+	err := session.Find(&users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func GetPaginationGlobalUsers(offset, limit int, field, value, sortField, sortOrder string) ([]*User, error) {
 	users := []*User{}
 	session := GetSessionForUser("", offset, limit, field, value, sortField, sortOrder)
@@ -650,6 +685,15 @@ func UpdateUser(id string, user *User, columns []string, isAdmin bool) (bool, er
 		return false, err
 	}
 
+	if affected != 0 {
+		newUser, err := getUser(user.Owner, user.Name)
+		if err != nil {
+			return false, err
+		}
+
+		sendWebhookEvent(oldUser.Owner, "update-user", oldUser.GetId(), oldUser, newUser)
+	}
+
 	return affected != 0, nil
 }
 
@@ -705,6 +749,10 @@ func UpdateUserForAllFields(id string, user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(oldUser.Owner, "update-user", oldUser.GetId(), oldUser, user)
+	}
+
 	return affected != 0, nil
 }
 
@@ -773,6 +821,10 @@ func AddUser(user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(user.Owner, "add-user", user.GetId(), nil, user)
+	}
+
 	return affected != 0, nil
 }
 
@@ -837,8 +889,13 @@ func AddUsersInBatch(users []*User) (bool, error) {
 }
 
 func DeleteUser(user *User) (bool, error) {
+	oldUser, err := getUser(user.Owner, user.Name)
+	if err != nil {
+		return false, err
+	}
+
 	// Forced offline the user first
-	_, err := DeleteSession(util.GetSessionId(user.Owner, user.Name, CasdoorApplication))
+	_, err = DeleteSession(util.GetSessionId(user.Owner, user.Name, CasdoorApplication))
 	if err != nil {
 		return false, err
 	}
@@ -848,6 +905,10 @@ func DeleteUser(user *User) (bool, error) {
 		return false, err
 	}
 
+	if affected != 0 {
+		sendWebhookEvent(user.Owner, "delete-user", user.GetId(), oldUser, nil)
+	}
+
 	return affected != 0, nil
 }
 
@@ -14,7 +14,16 @@
 
 package object
 
-import "github.com/casdoor/casdoor/cred"
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/casdoor/casdoor/cred"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
 
 func calculateHash(user *User) (string, error) {
 	syncer, err := getDbSyncerForUser(user)
@@ -39,11 +48,182 @@ func (user *User) UpdateUserHash() error {
 	return nil
 }
 
+const defaultPasswordHistoryCount = 5
+
+type PasswordTypeCount struct {
+	PasswordType string `json:"passwordType"`
+	Count        int64  `json:"count"`
+}
+
+// getTargetPasswordType returns the password type that the new passwords are hashed with, the passwords
+// of the other types are re-hashed with the target password type when the users sign in successfully
+func (org *Organization) getTargetPasswordType() string {
+	if org.TargetPasswordType != "" {
+		return org.TargetPasswordType
+	}
+	return org.PasswordType
+}
+
+// getNtPasswordHash returns the hex-encoded NT hash of the password kept for MS-CHAPv2, it is only kept if the
+// organization enables it, as MS-CHAPv2 can't be verified by the passwords hashed with the other password types
+func (org *Organization) getNtPasswordHash(password string, passwordType string) string {
+	if !org.EnableNtPasswordHash || passwordType == "plain" {
+		return ""
+	}
+	return hex.EncodeToString(util.GetNtPasswordHash(password))
+}
+
+// getPasswordHistoryCount returns how many recent passwords, including the current one, are rejected by the
+// "NoReuse" password option
+func (org *Organization) getPasswordHistoryCount() int {
+	if org.PasswordHistoryCount <= 0 {
+		return defaultPasswordHistoryCount
+	}
+	return org.PasswordHistoryCount
+}
+
+// getPasswordHistory returns the password history of the user after the current password is replaced, the
+// entries are "<password type>:<hashed password>" with the newest one first
+func getPasswordHistory(user *User, organization *Organization) []string {
+	history := []string{}
+	if user.Password != "" {
+		passwordType := user.PasswordType
+		if passwordType == "" {
+			passwordType = organization.PasswordType
+		}
+		hashedPassword := user.Password
+		// the plain passwords are hashed, so that the history never keeps the old passwords in plain text
+		if passwordType == "plain" {
+			passwordType = "salt"
+			hashedPassword = cred.GetCredManager(passwordType).GetHashedPassword(user.Password, user.PasswordSalt, organization.PasswordSalt)
+		}
+		history = append(history, fmt.Sprintf("%s:%s", passwordType, hashedPassword))
+	}
+	history = append(history, user.PasswordHistory...)
+
+	// the current password is not part of the history
+	count := organization.getPasswordHistoryCount() - 1
+	if len(history) > count {
+		history = history[:count]
+	}
+	return history
+}
+
+// IsPasswordExpired returns whether the password of the user has not been changed for longer than the
+// password expire days of the organization, the LDAP users are skipped as their passwords are not managed here
+func IsPasswordExpired(user *User) (bool, error) {
+	if user.Ldap != "" {
+		return false, nil
+	}
+
+	organization, err := GetOrganizationByUser(user)
+	if err != nil {
+		return false, err
+	}
+	if organization == nil || organization.PasswordExpireDays <= 0 {
+		return false, nil
+	}
+
+	changedTime := user.PasswordChangedTime
+	if changedTime == "" {
+		changedTime = user.CreatedTime
+	}
+	if changedTime == "" {
+		return false, nil
+	}
+
+	// the users imported with a malformed created time are not locked out
+	changedTimeObj, err := time.Parse(time.RFC3339, changedTime)
+	if err != nil {
+		return false, nil
+	}
+
+	expireTime := changedTimeObj.AddDate(0, 0, organization.PasswordExpireDays)
+	return time.Now().After(expireTime), nil
+}
+
 func (user *User) UpdateUserPassword(organization *Organization) {
-	credManager := cred.GetCredManager(organization.PasswordType)
+	passwordType := organization.getTargetPasswordType()
+	credManager := cred.GetCredManager(passwordType)
 	if credManager != nil {
+		user.NtPasswordHash = organization.getNtPasswordHash(user.Password, passwordType)
 		hashedPassword := credManager.GetHashedPassword(user.Password, user.PasswordSalt, organization.PasswordSalt)
 		user.Password = hashedPassword
-		user.PasswordType = organization.PasswordType
+		user.PasswordType = passwordType
+	}
+}
+
+// rehashUserPassword hashes the plaintext password that has just been verified with the target password type
+// of the organization, it returns false if the password is already hashed with the target password type
+func rehashUserPassword(user *User, organization *Organization, password string, passwordType string) bool {
+	if organization.TargetPasswordType == "" || organization.TargetPasswordType == passwordType {
+		return false
+	}
+
+	credManager := cred.GetCredManager(organization.TargetPasswordType)
+	if credManager == nil {
+		return false
+	}
+
+	user.Password = credManager.GetHashedPassword(password, user.PasswordSalt, organization.PasswordSalt)
+	user.PasswordType = organization.TargetPasswordType
+	return true
+}
+
+// migrateUserPassword re-hashes the password of the user at sign-in, so that the legacy hashes like md5-salt
+// are retired without resetting the passwords, and keeps the NT hash of the password up to date with the
+// organization. The columns are written directly like SetUserField, so that the re-hash doesn't fire the
+// webhooks or the provisioning of a user update.
+func migrateUserPassword(user *User, organization *Organization, password string, passwordType string) error {
+	oldPassword, oldPasswordType, oldNtPasswordHash := user.Password, user.PasswordType, user.NtPasswordHash
+	if rehashUserPassword(user, organization, password, passwordType) {
+		passwordType = user.PasswordType
+	}
+	user.NtPasswordHash = organization.getNtPasswordHash(password, passwordType)
+	if user.Password == oldPassword && user.NtPasswordHash == oldNtPasswordHash {
+		return nil
+	}
+
+	_, err := ormer.Engine.ID(core.PK{user.Owner, user.Name}).Cols("password", "password_type", "nt_password_hash").Update(user)
+	if err != nil {
+		user.Password, user.PasswordType, user.NtPasswordHash = oldPassword, oldPasswordType, oldNtPasswordHash
+		return err
+	}
+
+	return nil
+}
+
+// GetPasswordTypeCounts returns how many users of the organization have their passwords hashed with each
+// password type, the users without a password type use the password type of the organization
+func GetPasswordTypeCounts(organization *Organization) ([]*PasswordTypeCount, error) {
+	rows := []*PasswordTypeCount{}
+	err := ormer.Engine.Table(&User{}).Select("password_type, count(*) as count").
+		Where("owner = ? and password <> ?", organization.Name, "").
+		GroupBy("password_type").Find(&rows)
+	if err != nil {
+		return nil, err
 	}
+
+	res := []*PasswordTypeCount{}
+	countMap := map[string]*PasswordTypeCount{}
+	for _, row := range rows {
+		passwordType := row.PasswordType
+		if passwordType == "" {
+			passwordType = organization.PasswordType
+		}
+
+		if count, ok := countMap[passwordType]; ok {
+			count.Count += row.Count
+			continue
+		}
+
+		count := &PasswordTypeCount{PasswordType: passwordType, Count: row.Count}
+		countMap[passwordType] = count
+		res = append(res, count)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PasswordType < res[j].PasswordType
+	})
+	return res, nil
 }
@@ -0,0 +1,75 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/casdoor/casdoor/cred"
+)
+
+func TestRehashUserPassword(t *testing.T) {
+	organization := &Organization{PasswordType: "md5-salt", PasswordSalt: "org-salt", TargetPasswordType: "bcrypt"}
+	legacyHash := cred.GetCredManager("md5-salt").GetHashedPassword("123456", "user-salt", organization.PasswordSalt)
+	user := &User{Password: legacyHash, PasswordSalt: "user-salt"}
+
+	// the password of the user without a password type is hashed with the password type of the organization
+	if !rehashUserPassword(user, organization, "123456", "md5-salt") {
+		t.Fatalf("rehashUserPassword() should re-hash the md5-salt password")
+	}
+	if user.PasswordType != "bcrypt" || user.Password == legacyHash {
+		t.Errorf("rehashUserPassword() = %s, %s, expected a bcrypt hash", user.PasswordType, user.Password)
+	}
+	if !cred.GetCredManager("bcrypt").IsPasswordCorrect("123456", user.Password, user.PasswordSalt, organization.PasswordSalt) {
+		t.Errorf("the re-hashed password should be verified with bcrypt")
+	}
+
+	// the migrated password is not re-hashed again
+	bcryptHash := user.Password
+	if rehashUserPassword(user, organization, "123456", user.PasswordType) || user.Password != bcryptHash {
+		t.Errorf("rehashUserPassword() should not re-hash the password of the target password type")
+	}
+
+	// the passwords are not migrated without a target password type
+	organization.TargetPasswordType = ""
+	user = &User{Password: legacyHash, PasswordSalt: "user-salt"}
+	if rehashUserPassword(user, organization, "123456", "md5-salt") || user.Password != legacyHash {
+		t.Errorf("rehashUserPassword() should not re-hash the password without a target password type")
+	}
+}
+
+func TestUpdateUserPasswordWithNtPasswordHash(t *testing.T) {
+	organization := &Organization{PasswordType: "plain", TargetPasswordType: "bcrypt", EnableNtPasswordHash: true}
+	user := &User{Password: "clientPass"}
+
+	// the NT hash of "clientPass" from RFC 2759 section 9.2
+	user.UpdateUserPassword(organization)
+	if user.PasswordType != "bcrypt" || user.NtPasswordHash != "44ebba8d5312b8d611474411f56989ae" {
+		t.Errorf("UpdateUserPassword() = %s, %s, expected a bcrypt hash with the NT hash", user.PasswordType, user.NtPasswordHash)
+	}
+
+	// the plain passwords don't need the NT hash
+	if ntPasswordHash := organization.getNtPasswordHash("clientPass", "plain"); ntPasswordHash != "" {
+		t.Errorf("getNtPasswordHash() = %s, expected empty for the plain password", ntPasswordHash)
+	}
+
+	// the NT hash is not kept unless the organization enables it
+	organization.EnableNtPasswordHash = false
+	user = &User{Password: "clientPass"}
+	user.UpdateUserPassword(organization)
+	if user.NtPasswordHash != "" {
+		t.Errorf("UpdateUserPassword() = %s, expected no NT hash", user.NtPasswordHash)
+	}
+}
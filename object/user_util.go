@@ -91,9 +91,19 @@ func SetUserField(user *User, field string, value string) (bool, error) {
 			return false, err
 		}
 
+		oldUser, err := getUser(user.Owner, user.Name)
+		if err != nil {
+			return false, err
+		}
+		if oldUser != nil {
+			bean["password_history"] = util.StructToJson(getPasswordHistory(oldUser, organization))
+		}
+
 		user.UpdateUserPassword(organization)
 		bean[strings.ToLower(field)] = user.Password
 		bean["password_type"] = user.PasswordType
+		bean["nt_password_hash"] = user.NtPasswordHash
+		bean["password_changed_time"] = util.GetCurrentTime()
 	} else {
 		bean[strings.ToLower(field)] = value
 	}
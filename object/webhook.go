@@ -39,7 +39,13 @@ type Webhook struct {
 	Headers        []*Header `xorm:"mediumtext" json:"headers"`
 	Events         []string  `xorm:"varchar(1000)" json:"events"`
 	IsUserExtended bool      `json:"isUserExtended"`
+	IsCloudEvents  bool      `json:"isCloudEvents"`
 	IsEnabled      bool      `json:"isEnabled"`
+
+	Secret              string `xorm:"varchar(100)" json:"secret"`
+	MaxRetries          int    `json:"maxRetries"`
+	NotificationEmail   string `xorm:"varchar(100)" json:"notificationEmail"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
 }
 
 func GetWebhookCount(owner, organization, field, value string) (int64, error) {
@@ -101,15 +107,46 @@ func GetWebhook(id string) (*Webhook, error) {
 	return getWebhook(owner, name)
 }
 
+func GetMaskedWebhook(webhook *Webhook) *Webhook {
+	if webhook == nil {
+		return nil
+	}
+
+	if webhook.Secret != "" {
+		webhook.Secret = "***"
+	}
+	return webhook
+}
+
+func GetMaskedWebhooks(webhooks []*Webhook) []*Webhook {
+	for i, webhook := range webhooks {
+		webhooks[i] = GetMaskedWebhook(webhook)
+	}
+	return webhooks
+}
+
 func UpdateWebhook(id string, webhook *Webhook) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if w, err := getWebhook(owner, name); err != nil {
+	oldWebhook, err := getWebhook(owner, name)
+	if err != nil {
 		return false, err
-	} else if w == nil {
+	} else if oldWebhook == nil {
 		return false, nil
 	}
 
-	affected, err := ormer.Engine.ID(core.PK{owner, name}).AllCols().Update(webhook)
+	session := ormer.Engine.ID(core.PK{owner, name}).AllCols()
+	if webhook.Secret == "***" {
+		session.Omit("secret")
+	}
+
+	// the webhook disabled for the failures starts counting again when it is re-enabled,
+	// otherwise the failures are only counted by the deliveries
+	if !oldWebhook.IsEnabled && webhook.IsEnabled {
+		webhook.ConsecutiveFailures = 0
+	} else {
+		session.Omit("consecutive_failures")
+	}
+	affected, err := session.Update(webhook)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,385 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/xorm-io/core"
+)
+
+const (
+	WebhookDeliveryPending   = "Pending"
+	WebhookDeliverySucceeded = "Succeeded"
+	WebhookDeliveryFailed    = "Failed"
+
+	webhookDefaultMaxRetries       = 5
+	webhookRetryBaseSeconds        = 30
+	webhookRetryMaxSeconds         = 6 * 3600
+	webhookMaxConsecutiveFailures  = 10
+	webhookDeliveryTimeoutSeconds  = 10
+	webhookDeliveryMaxResponseSize = 10000
+)
+
+type WebhookDelivery struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100) index" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	Organization string `xorm:"varchar(100) index" json:"organization"`
+	Webhook      string `xorm:"varchar(100) index" json:"webhook"`
+	Event        string `xorm:"varchar(100)" json:"event"`
+	Url          string `xorm:"varchar(200)" json:"url"`
+	Payload      string `xorm:"mediumtext" json:"payload"`
+
+	State           string `xorm:"varchar(100) index" json:"state"`
+	Attempts        int    `json:"attempts"`
+	NextAttemptTime string `xorm:"varchar(100) index" json:"nextAttemptTime"`
+	StatusCode      int    `json:"statusCode"`
+	Response        string `xorm:"mediumtext" json:"response"`
+	Error           string `xorm:"varchar(1000)" json:"error"`
+	ClaimedBy       string `xorm:"varchar(100)" json:"claimedBy"`
+}
+
+func GetWebhookDeliveryCount(owner, webhook, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&WebhookDelivery{Webhook: webhook})
+}
+
+func GetPaginationWebhookDeliveries(owner, webhook string, offset, limit int, field, value, sortField, sortOrder string) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&deliveries, &WebhookDelivery{Webhook: webhook})
+	if err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+func getWebhookDelivery(owner string, name string) (*WebhookDelivery, error) {
+	if owner == "" || name == "" {
+		return nil, nil
+	}
+
+	delivery := WebhookDelivery{Owner: owner, Name: name}
+	existed, err := ormer.Engine.Get(&delivery)
+	if err != nil {
+		return &delivery, err
+	}
+
+	if existed {
+		return &delivery, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetWebhookDelivery(id string) (*WebhookDelivery, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getWebhookDelivery(owner, name)
+}
+
+func updateWebhookDelivery(delivery *WebhookDelivery) error {
+	delivery.UpdatedTime = util.GetCurrentTime()
+	delivery.ClaimedBy = ""
+	_, err := ormer.Engine.ID(core.PK{delivery.Owner, delivery.Name}).AllCols().Update(delivery)
+	return err
+}
+
+func (delivery *WebhookDelivery) GetId() string {
+	return fmt.Sprintf("%s/%s", delivery.Owner, delivery.Name)
+}
+
+func (webhook *Webhook) getMaxRetries() int {
+	if webhook.MaxRetries <= 0 {
+		return webhookDefaultMaxRetries
+	}
+	return webhook.MaxRetries
+}
+
+// getWebhookRetryTime returns the time of the next attempt with exponential backoff
+func getWebhookRetryTime(attempts int) string {
+	seconds := webhookRetryBaseSeconds
+	for i := 1; i < attempts && seconds < webhookRetryMaxSeconds; i++ {
+		seconds *= 2
+	}
+	if seconds > webhookRetryMaxSeconds {
+		seconds = webhookRetryMaxSeconds
+	}
+
+	return time.Now().Add(time.Duration(seconds) * time.Second).Format(time.RFC3339)
+}
+
+// getWebhookClaimExpireTime returns the time after which a claimed delivery is claimed again by the delivery job,
+// in case the replica that claimed it has stopped before recording the attempt
+func getWebhookClaimExpireTime() string {
+	return time.Now().Add(webhookDeliveryTimeoutSeconds * 2 * time.Second).Format(time.RFC3339)
+}
+
+// claimWebhookDelivery takes the due delivery for this replica, the update is conditioned on the delivery
+// being due, so only one of the replicas that read the delivery at the same time can claim it
+func claimWebhookDelivery(delivery *WebhookDelivery) (bool, error) {
+	now := util.GetCurrentTime()
+	claim := &WebhookDelivery{ClaimedBy: jobLockHolder, NextAttemptTime: getWebhookClaimExpireTime()}
+	affected, err := ormer.Engine.Where("owner = ? and name = ? and state = ? and next_attempt_time <= ?", delivery.Owner, delivery.Name, WebhookDeliveryPending, now).
+		Cols("claimed_by", "next_attempt_time").Update(claim)
+	if err != nil {
+		return false, err
+	}
+
+	if affected == 0 {
+		return false, nil
+	}
+
+	delivery.ClaimedBy = claim.ClaimedBy
+	delivery.NextAttemptTime = claim.NextAttemptTime
+	return true, nil
+}
+
+// enqueueWebhookDelivery stores the delivery before the first attempt, so that it is retried by
+// the delivery job even if the attempt is interrupted
+func enqueueWebhookDelivery(webhook *Webhook, event string, payload string) (*WebhookDelivery, error) {
+	delivery := &WebhookDelivery{
+		Owner:           webhook.Owner,
+		Name:            util.GenerateId(),
+		CreatedTime:     util.GetCurrentTime(),
+		UpdatedTime:     util.GetCurrentTime(),
+		Organization:    webhook.Organization,
+		Webhook:         webhook.Name,
+		Event:           event,
+		Url:             webhook.Url,
+		Payload:         payload,
+		State:           WebhookDeliveryPending,
+		NextAttemptTime: getWebhookClaimExpireTime(),
+		ClaimedBy:       jobLockHolder,
+	}
+
+	_, err := ormer.Engine.Insert(delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// attemptWebhookDelivery sends the delivery once, and schedules the next attempt or gives up when it fails
+func attemptWebhookDelivery(webhook *Webhook, delivery *WebhookDelivery) error {
+	delivery.Attempts += 1
+	statusCode, response, err := sendWebhook(webhook, delivery)
+	if len(response) > webhookDeliveryMaxResponseSize {
+		response = response[:webhookDeliveryMaxResponseSize]
+	}
+
+	delivery.StatusCode = statusCode
+	delivery.Response = response
+	delivery.Error = ""
+	if err == nil && (statusCode < 200 || statusCode >= 300) {
+		err = fmt.Errorf("the webhook: %s responded with status code: %d", webhook.GetId(), statusCode)
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		if len(delivery.Error) > 1000 {
+			delivery.Error = delivery.Error[:1000]
+		}
+	}
+
+	if err == nil {
+		delivery.State = WebhookDeliverySucceeded
+		delivery.NextAttemptTime = ""
+	} else if delivery.Attempts > webhook.getMaxRetries() {
+		delivery.State = WebhookDeliveryFailed
+		delivery.NextAttemptTime = ""
+	} else {
+		delivery.NextAttemptTime = getWebhookRetryTime(delivery.Attempts)
+	}
+
+	updateErr := updateWebhookDelivery(delivery)
+	if updateErr != nil {
+		return updateErr
+	}
+
+	if delivery.State == WebhookDeliverySucceeded {
+		updateErr = resetWebhookFailures(webhook)
+	} else if delivery.State == WebhookDeliveryFailed {
+		updateErr = addWebhookFailure(webhook)
+	}
+	if updateErr != nil {
+		return updateErr
+	}
+
+	return err
+}
+
+func resetWebhookFailures(webhook *Webhook) error {
+	if webhook.ConsecutiveFailures == 0 {
+		return nil
+	}
+
+	webhook.ConsecutiveFailures = 0
+	_, err := ormer.Engine.ID(core.PK{webhook.Owner, webhook.Name}).Cols("consecutive_failures").Update(webhook)
+	return err
+}
+
+// addWebhookFailure counts the deliveries that have failed after all the retries,
+// and disables the webhook that keeps failing
+func addWebhookFailure(webhook *Webhook) error {
+	webhook.ConsecutiveFailures += 1
+	if webhook.ConsecutiveFailures >= webhookMaxConsecutiveFailures {
+		webhook.IsEnabled = false
+	}
+
+	_, err := ormer.Engine.ID(core.PK{webhook.Owner, webhook.Name}).Cols("consecutive_failures", "is_enabled").Update(webhook)
+	if err != nil {
+		return err
+	}
+
+	if !webhook.IsEnabled {
+		return notifyWebhookDisabled(webhook)
+	}
+	return nil
+}
+
+func notifyWebhookDisabled(webhook *Webhook) error {
+	fmt.Printf("The webhook: %s has been disabled after %d failed deliveries\n", webhook.GetId(), webhook.ConsecutiveFailures)
+
+	if webhook.NotificationEmail == "" {
+		return nil
+	}
+
+	application, err := GetDefaultApplication(util.GetId("admin", webhook.Organization))
+	if err != nil {
+		return err
+	}
+
+	provider, err := application.GetEmailProvider()
+	if err != nil {
+		return err
+	}
+
+	if provider == nil {
+		return fmt.Errorf("the email provider of the application: %s doesn't exist", application.GetId())
+	}
+
+	title := fmt.Sprintf("Webhook %s has been disabled", webhook.Name)
+	content := fmt.Sprintf("The webhook: %s (%s) of the organization: %s has been disabled automatically after %d consecutive failed deliveries. Please check the endpoint and enable the webhook again.",
+		webhook.Name, webhook.Url, webhook.Organization, webhook.ConsecutiveFailures)
+	return SendEmail(provider, title, content, webhook.NotificationEmail, conf.GetConfigString("appname"))
+}
+
+// RedeliverWebhookDelivery sends the payload of the delivery again as a new delivery
+func RedeliverWebhookDelivery(delivery *WebhookDelivery) (*WebhookDelivery, error) {
+	webhook, err := getWebhook(delivery.Owner, delivery.Webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook == nil {
+		return nil, fmt.Errorf("the webhook: %s doesn't exist", util.GetId(delivery.Owner, delivery.Webhook))
+	}
+
+	newDelivery, err := enqueueWebhookDelivery(webhook, delivery.Event, delivery.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// the result of the attempt is recorded in the delivery
+	_ = attemptWebhookDelivery(webhook, newDelivery)
+	return newDelivery, nil
+}
+
+func retryWebhookDeliveries() error {
+	deliveries := []*WebhookDelivery{}
+	err := ormer.Engine.Where("state=? and next_attempt_time<=?", WebhookDeliveryPending, util.GetCurrentTime()).
+		Asc("next_attempt_time").Limit(conf.GetConfigBatchSize()).Find(&deliveries)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		claimed, err := claimWebhookDelivery(delivery)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		webhook, err := getWebhook(delivery.Owner, delivery.Webhook)
+		if err != nil {
+			return err
+		}
+
+		if webhook == nil || !webhook.IsEnabled {
+			delivery.State = WebhookDeliveryFailed
+			delivery.NextAttemptTime = ""
+			delivery.Error = "the webhook has been deleted or disabled"
+			err = updateWebhookDelivery(delivery)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = attemptWebhookDelivery(webhook, delivery)
+		if err != nil {
+			fmt.Printf("retryWebhookDeliveries() error for delivery: %s, %s\n", delivery.GetId(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// deleteExpiredWebhookDeliveries deletes the finished deliveries older than "webhookDeliveryRetentionDays",
+// the deliveries are kept forever if it is not configured
+func deleteExpiredWebhookDeliveries() (int64, error) {
+	retentionDays, err := conf.GetConfigInt64("webhookDeliveryRetentionDays")
+	if err != nil || retentionDays <= 0 {
+		return 0, nil
+	}
+
+	expireTime := time.Now().AddDate(0, 0, -int(retentionDays)).Format(time.RFC3339)
+	return ormer.Engine.Where("state != ? and created_time < ?", WebhookDeliveryPending, expireTime).Delete(&WebhookDelivery{})
+}
+
+func RunWebhookDeliveryJob() {
+	lastPruneTime := time.Time{}
+	ticker := time.NewTicker(10 * time.Second)
+	for ; true; <-ticker.C {
+		err := retryWebhookDeliveries()
+		if err != nil {
+			fmt.Printf("RunWebhookDeliveryJob() error: %s\n", err.Error())
+		}
+
+		if time.Since(lastPruneTime) < time.Hour {
+			continue
+		}
+		lastPruneTime = time.Now()
+
+		affected, err := deleteExpiredWebhookDeliveries()
+		if err != nil {
+			fmt.Printf("RunWebhookDeliveryJob() error: %s\n", err.Error())
+			continue
+		}
+
+		if affected != 0 {
+			fmt.Printf("RunWebhookDeliveryJob(): %d expired webhook deliveries are deleted\n", affected)
+		}
+	}
+}
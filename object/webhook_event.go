@@ -0,0 +1,224 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const cloudEventsSpecVersion = "1.0"
+
+// WebhookEvent is the payload of the lifecycle events of the entities, which is a CloudEvents 1.0
+// event in the structured content mode
+type WebhookEvent struct {
+	SpecVersion     string            `json:"specversion"`
+	Id              string            `json:"id"`
+	Source          string            `json:"source"`
+	Type            string            `json:"type"`
+	Subject         string            `json:"subject"`
+	Time            string            `json:"time"`
+	DataContentType string            `json:"datacontenttype"`
+	Data            *WebhookEventData `json:"data"`
+}
+
+type WebhookEventData struct {
+	Organization  string                 `json:"organization"`
+	Before        map[string]interface{} `json:"before"`
+	After         map[string]interface{} `json:"after"`
+	ChangedFields []string               `json:"changedFields"`
+}
+
+// the fields that are masked in the payloads
+var webhookEventMaskedFields = []string{"password", "passwordSalt", "accessSecret", "totpSecret", "recoveryCodes", "managedAccounts", "clientSecret", "scimToken", "masterPassword", "defaultPassword", "masterVerificationCode"}
+
+// the bookkeeping fields updated by the sign-ins, whose changes alone are not sent as events
+var webhookEventIgnoredFields = []string{"signinWrongTimes", "lastSigninWrongTime"}
+
+// entityEventTypes maps the webhook events of the entities to the CloudEvents types, the events are
+// named after the APIs, so the existing webhooks subscribing to them keep working
+var entityEventTypes = map[string]string{}
+
+func init() {
+	for _, entity := range []string{"user", "group", "role", "permission", "application"} {
+		entityEventTypes[fmt.Sprintf("add-%s", entity)] = fmt.Sprintf("org.casdoor.%s.created", entity)
+		entityEventTypes[fmt.Sprintf("update-%s", entity)] = fmt.Sprintf("org.casdoor.%s.updated", entity)
+		entityEventTypes[fmt.Sprintf("delete-%s", entity)] = fmt.Sprintf("org.casdoor.%s.deleted", entity)
+	}
+}
+
+func isEntityEvent(action string) bool {
+	_, ok := entityEventTypes[action]
+	return ok
+}
+
+// getEntityMap returns the stored fields of the entity, the objects expanded into the entity when it is read,
+// like the organization and the providers of the application, are left out of the payloads
+func getEntityMap(entity interface{}) map[string]interface{} {
+	if entity == nil || reflect.ValueOf(entity).IsNil() {
+		return nil
+	}
+
+	res := map[string]interface{}{}
+	err := json.Unmarshal([]byte(util.StructToJson(entity)), &res)
+	if err != nil {
+		return nil
+	}
+
+	entityType := reflect.TypeOf(entity)
+	if entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	if entityType.Kind() == reflect.Struct {
+		for i := 0; i < entityType.NumField(); i++ {
+			field := entityType.Field(i)
+			if field.Tag.Get("xorm") == "-" {
+				delete(res, strings.Split(field.Tag.Get("json"), ",")[0])
+			}
+		}
+	}
+
+	// the provider items of the application are stored without the providers
+	if providerItems, ok := res["providers"].([]interface{}); ok {
+		for _, providerItem := range providerItems {
+			if providerItemMap, ok := providerItem.(map[string]interface{}); ok {
+				delete(providerItemMap, "provider")
+			}
+		}
+	}
+	return res
+}
+
+func getMaskedEntityValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return getMaskedEntityMap(v)
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, item := range v {
+			res[i] = getMaskedEntityValue(item)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// getMaskedEntityMap masks the secrets of the entity, including the ones of the nested objects
+func getMaskedEntityMap(entityMap map[string]interface{}) map[string]interface{} {
+	if entityMap == nil {
+		return nil
+	}
+
+	res := map[string]interface{}{}
+	for key, value := range entityMap {
+		if util.InSlice(webhookEventMaskedFields, key) && value != nil && value != "" {
+			res[key] = "***"
+		} else {
+			res[key] = getMaskedEntityValue(value)
+		}
+	}
+	return res
+}
+
+// getChangedFields returns the sorted names of the fields whose values differ between the two entities,
+// except the bookkeeping fields
+func getChangedFields(before map[string]interface{}, after map[string]interface{}) []string {
+	res := []string{}
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) && !util.InSlice(webhookEventIgnoredFields, key) {
+			res = append(res, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok && !util.InSlice(webhookEventIgnoredFields, key) {
+			res = append(res, key)
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+func newWebhookEvent(organization string, action string, subject string, before interface{}, after interface{}) *WebhookEvent {
+	beforeMap := getEntityMap(before)
+	afterMap := getEntityMap(after)
+
+	var changedFields []string
+	if beforeMap != nil && afterMap != nil {
+		changedFields = getChangedFields(beforeMap, afterMap)
+	}
+
+	return &WebhookEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		Id:              util.GenerateId(),
+		Source:          fmt.Sprintf("/organizations/%s", organization),
+		Type:            entityEventTypes[action],
+		Subject:         subject,
+		Time:            util.GetCurrentTime(),
+		DataContentType: "application/json",
+		Data: &WebhookEventData{
+			Organization:  organization,
+			Before:        getMaskedEntityMap(beforeMap),
+			After:         getMaskedEntityMap(afterMap),
+			ChangedFields: changedFields,
+		},
+	}
+}
+
+// sendWebhookEvent sends the lifecycle event of the entity to the webhooks of the organization
+// subscribing to the action with CloudEvents, the before or after entity is nil when it is created or deleted.
+// The other webhooks keep receiving the records of the APIs by SendWebhooks.
+func sendWebhookEvent(organization string, action string, subject string, before interface{}, after interface{}) {
+	// the payload is built before returning, so that the entities can be modified by the caller afterwards
+	event := newWebhookEvent(organization, action, subject, before, after)
+	provisionEntityEvent(organization, action, event.Data.ChangedFields, before, after)
+
+	if event.Data.ChangedFields != nil && len(event.Data.ChangedFields) == 0 {
+		return
+	}
+
+	payload := util.StructToJson(event)
+	util.SafeGoroutine(func() {
+		webhooks, err := getWebhooksByOrganization(organization)
+		if err != nil {
+			fmt.Printf("sendWebhookEvent() error: %s\n", err.Error())
+			return
+		}
+
+		for _, webhook := range getFilteredWebhooks(webhooks, action) {
+			if !webhook.IsCloudEvents {
+				continue
+			}
+
+			delivery, err := enqueueWebhookDelivery(webhook, action, payload)
+			if err != nil {
+				fmt.Printf("sendWebhookEvent() error: %s\n", err.Error())
+				continue
+			}
+
+			// the failed delivery is retried by the delivery job
+			err = attemptWebhookDelivery(webhook, delivery)
+			if err != nil {
+				fmt.Printf("sendWebhookEvent() error: %s\n", err.Error())
+			}
+		}
+	})
+}
@@ -15,18 +15,18 @@
 package object
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/casdoor/casdoor/util"
-	"github.com/casvisor/casvisor-go-sdk/casvisorsdk"
 )
 
-func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *User) error {
-	client := &http.Client{}
-
+func getWebhookPayload(record *Record, extendedUser *User) string {
 	type RecordEx struct {
-		casvisorsdk.Record
+		Record
 		ExtendedUser *User `xorm:"-" json:"extendedUser"`
 	}
 	recordEx := &RecordEx{
@@ -34,11 +34,24 @@ func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *Use
 		ExtendedUser: extendedUser,
 	}
 
-	body := strings.NewReader(util.StructToJson(recordEx))
+	return util.StructToJson(recordEx)
+}
+
+// getWebhookSignature signs the timestamp and the payload with the secret of the webhook, so that the
+// receiver can verify the sender and reject the replayed requests
+func getWebhookSignature(secret string, timestamp string, payload string) string {
+	return util.GetHmacSha256(secret, fmt.Sprintf("%s.%s", timestamp, payload))
+}
 
-	req, err := http.NewRequest(webhook.Method, webhook.Url, body)
+// sendWebhook sends the delivery and returns the status code and the body of the response
+func sendWebhook(webhook *Webhook, delivery *WebhookDelivery) (int, string, error) {
+	client := &http.Client{
+		Timeout: webhookDeliveryTimeoutSeconds * time.Second,
+	}
+
+	req, err := http.NewRequest(webhook.Method, webhook.Url, strings.NewReader(delivery.Payload))
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 
 	req.Header.Set("Content-Type", webhook.ContentType)
@@ -47,6 +60,23 @@ func sendWebhook(webhook *Webhook, record *casvisorsdk.Record, extendedUser *Use
 		req.Header.Set(header.Name, header.Value)
 	}
 
-	_, err = client.Do(req)
-	return err
+	req.Header.Set("Casdoor-Delivery", delivery.Name)
+	req.Header.Set("Casdoor-Event", delivery.Event)
+	if webhook.Secret != "" {
+		timestamp := fmt.Sprintf("%d", time.Now().Unix())
+		req.Header.Set("Casdoor-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, getWebhookSignature(webhook.Secret, timestamp, delivery.Payload)))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhookDeliveryMaxResponseSize))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+
+	return resp.StatusCode, string(body), nil
 }
@@ -0,0 +1,48 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioning
+
+const (
+	UserResourceType  = "User"
+	GroupResourceType = "Group"
+)
+
+// Resource is a resource of the downstream application in the SCIM representation
+type Resource map[string]interface{}
+
+func (resource Resource) GetId() string {
+	id, _ := resource["id"].(string)
+	return id
+}
+
+// ProvisioningProvider manages the users and groups in a downstream application, a resource
+// that doesn't exist is returned as nil without error
+type ProvisioningProvider interface {
+	GetResource(resourceType string, id string) (Resource, error)
+	FindResource(resourceType string, attribute string, value string) (Resource, error)
+	GetResources(resourceType string) ([]Resource, error)
+	AddResource(resourceType string, resource Resource) (Resource, error)
+	UpdateResource(resourceType string, id string, resource Resource) (Resource, error)
+	DeleteResource(resourceType string, id string) error
+}
+
+func GetProvisioningProvider(providerType string, endpoint string, token string) ProvisioningProvider {
+	switch providerType {
+	case "SCIM 2.0":
+		return NewScimProvisioningProvider(endpoint, token)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7644 SCIM 2.0 protocol
+
+const (
+	scimContentType     = "application/scim+json"
+	scimPageSize        = 100
+	scimTimeoutSeconds  = 10
+	scimMaxResponseSize = 10 * 1024 * 1024
+)
+
+var scimEndpoints = map[string]string{
+	UserResourceType:  "/Users",
+	GroupResourceType: "/Groups",
+}
+
+type ScimProvisioningProvider struct {
+	Endpoint string
+	Token    string
+	client   *http.Client
+}
+
+type ScimError struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *ScimError) Error() string {
+	return fmt.Sprintf("the SCIM request failed with status code: %d, %s", e.StatusCode, e.Detail)
+}
+
+type scimListResponse struct {
+	TotalResults int        `json:"totalResults"`
+	Resources    []Resource `json:"Resources"`
+}
+
+func NewScimProvisioningProvider(endpoint string, token string) *ScimProvisioningProvider {
+	return &ScimProvisioningProvider{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Token:    token,
+		client: &http.Client{
+			Timeout: scimTimeoutSeconds * time.Second,
+		},
+	}
+}
+
+func isNotFound(err error) bool {
+	scimErr, ok := err.(*ScimError)
+	return ok && scimErr.StatusCode == http.StatusNotFound
+}
+
+func getScimEndpoint(resourceType string) (string, error) {
+	endpoint, ok := scimEndpoints[resourceType]
+	if !ok {
+		return "", fmt.Errorf("invalid resource type: %s", resourceType)
+	}
+	return endpoint, nil
+}
+
+func (p *ScimProvisioningProvider) doRequest(method string, path string, query url.Values, body interface{}, v interface{}) error {
+	requestUrl := p.Endpoint + path
+	if query != nil {
+		requestUrl = fmt.Sprintf("%s?%s", requestUrl, query.Encode())
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, requestUrl, reader)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", scimContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, scimMaxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		scimErr := &ScimError{StatusCode: resp.StatusCode}
+		errorResponse := struct {
+			Detail string `json:"detail"`
+		}{}
+		if json.Unmarshal(data, &errorResponse) == nil && errorResponse.Detail != "" {
+			scimErr.Detail = errorResponse.Detail
+		} else if len(data) > 200 {
+			scimErr.Detail = string(data[:200])
+		} else {
+			scimErr.Detail = string(data)
+		}
+		return scimErr
+	}
+
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (p *ScimProvisioningProvider) GetResource(resourceType string, id string) (Resource, error) {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := Resource{}
+	err = p.doRequest(http.MethodGet, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(id)), nil, nil, &resource)
+	if isNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (p *ScimProvisioningProvider) FindResource(resourceType string, attribute string, value string) (Resource, error) {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf("%s eq \"%s\"", attribute, strings.ReplaceAll(value, "\"", "\\\"")))
+	query.Set("startIndex", "1")
+	query.Set("count", "1")
+
+	var listResponse scimListResponse
+	err = p.doRequest(http.MethodGet, endpoint, query, nil, &listResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(listResponse.Resources) == 0 {
+		return nil, nil
+	}
+	return listResponse.Resources[0], nil
+}
+
+func (p *ScimProvisioningProvider) GetResources(resourceType string) ([]Resource, error) {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []Resource{}
+	for startIndex := 1; ; startIndex += scimPageSize {
+		query := url.Values{}
+		query.Set("startIndex", fmt.Sprintf("%d", startIndex))
+		query.Set("count", fmt.Sprintf("%d", scimPageSize))
+
+		var listResponse scimListResponse
+		err = p.doRequest(http.MethodGet, endpoint, query, nil, &listResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, listResponse.Resources...)
+		if len(listResponse.Resources) == 0 || len(resources) >= listResponse.TotalResults {
+			break
+		}
+	}
+	return resources, nil
+}
+
+func (p *ScimProvisioningProvider) AddResource(resourceType string, resource Resource) (Resource, error) {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	res := Resource{}
+	err = p.doRequest(http.MethodPost, endpoint, nil, resource, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *ScimProvisioningProvider) UpdateResource(resourceType string, id string, resource Resource) (Resource, error) {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	res := Resource{}
+	err = p.doRequest(http.MethodPut, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(id)), nil, resource, &res)
+	if isNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *ScimProvisioningProvider) DeleteResource(resourceType string, id string) error {
+	endpoint, err := getScimEndpoint(resourceType)
+	if err != nil {
+		return err
+	}
+
+	err = p.doRequest(http.MethodDelete, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(id)), nil, nil, nil)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
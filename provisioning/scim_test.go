@@ -0,0 +1,95 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScimProvisioningProvider(t *testing.T) {
+	users := []Resource{}
+	for i := 0; i < 150; i++ {
+		users = append(users, Resource{"id": fmt.Sprintf("%d", i), "userName": fmt.Sprintf("user%d", i)})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/scim/v2/Users":
+			resources := users
+			if r.URL.Query().Get("filter") == `userName eq "user42"` {
+				resources = users[42:43]
+			}
+
+			startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+			count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+			end := startIndex - 1 + count
+			if end > len(resources) {
+				end = len(resources)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"totalResults": len(resources),
+				"Resources":    resources[startIndex-1 : end],
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/scim/v2/Users":
+			resource := Resource{}
+			_ = json.NewDecoder(r.Body).Decode(&resource)
+			resource["id"] = "new"
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(resource)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail": "Resource not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := GetProvisioningProvider("SCIM 2.0", server.URL+"/scim/v2/", "token")
+
+	resources, err := provider.GetResources(UserResourceType)
+	assert.Nil(t, err)
+	assert.Equal(t, 150, len(resources))
+
+	resource, err := provider.FindResource(UserResourceType, "userName", "user42")
+	assert.Nil(t, err)
+	assert.Equal(t, "42", resource.GetId())
+
+	resource, err = provider.AddResource(UserResourceType, Resource{"userName": "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, "new", resource.GetId())
+
+	resource, err = provider.GetResource(UserResourceType, "missing")
+	assert.Nil(t, err)
+	assert.Nil(t, resource)
+
+	resource, err = provider.UpdateResource(UserResourceType, "missing", Resource{"userName": "alice"})
+	assert.Nil(t, err)
+	assert.Nil(t, resource)
+
+	err = provider.DeleteResource(UserResourceType, "missing")
+	assert.Nil(t, err)
+
+	_, err = provider.GetResources(GroupResourceType)
+	assert.Error(t, err)
+	assert.Equal(t, "Resource not found", err.(*ScimError).Detail)
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+)
+
+// secretSource returns the shared secret of the RADIUS client the request is sent from, and falls back
+// to the global "radiusSecret" for the unknown clients
+type secretSource struct {
+	secret []byte
+}
+
+func (s *secretSource) RADIUSSecret(ctx context.Context, remoteAddr net.Addr) ([]byte, error) {
+	client, err := getRadiusClient(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if client != nil {
+		return []byte(client.Secret), nil
+	}
+	return s.secret, nil
+}
+
+func getRadiusClient(remoteAddr net.Addr) (*object.RadiusClient, error) {
+	udpAddr, ok := remoteAddr.(*net.UDPAddr)
+	if !ok {
+		return nil, nil
+	}
+
+	return object.GetRadiusClientByIp(udpAddr.IP)
+}
+
+// getOrganization returns the organization and the name of the user, the organization is specified by the
+// "org/name" user name or the "Class" attribute, or defaults to the organization of the RADIUS client. A RADIUS
+// client can only authenticate the users of its own organization.
+func getOrganization(r *radius.Request, username string) (string, string, error) {
+	client, err := getRadiusClient(r.RemoteAddr)
+	if err != nil {
+		return "", "", err
+	}
+
+	organization := rfc2865.Class_GetString(r.Packet)
+	if index := strings.Index(username, "/"); index != -1 {
+		organization, username = username[:index], username[index+1:]
+	}
+
+	if client != nil {
+		if organization == "" {
+			organization = client.Owner
+		} else if organization != client.Owner {
+			return "", "", fmt.Errorf("the RADIUS client: %s doesn't belong to the organization: %s", client.GetId(), organization)
+		}
+	}
+
+	if organization == "" {
+		return "", "", fmt.Errorf("the organization of the user: %s is not specified", username)
+	}
+	return organization, username, nil
+}
@@ -0,0 +1,489 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/object"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2548"
+	"layeh.com/radius/rfc2865"
+	"layeh.com/radius/rfc2869"
+)
+
+// EAP is implemented by RFC 3748 and RFC 3579, and EAP-TTLS with the inner PAP by RFC 5281
+const (
+	eapCodeRequest  = 1
+	eapCodeResponse = 2
+	eapCodeSuccess  = 3
+	eapCodeFailure  = 4
+
+	eapTypeIdentity = 1
+	eapTypeTtls     = 21
+
+	ttlsFlagLength = 0x80
+	ttlsFlagMore   = 0x40
+	ttlsFlagStart  = 0x20
+
+	// the maximum size of the TLS records in an EAP-TTLS message, so that the RADIUS packet fits in the usual MTU
+	ttlsFragmentSize = 1000
+
+	diameterAvpUserName     = 1
+	diameterAvpUserPassword = 2
+	diameterAvpFlagVendor   = 0x80
+)
+
+type eapPacket struct {
+	code       byte
+	identifier byte
+	typ        byte
+	data       []byte
+}
+
+func parseEapPacket(b []byte) (*eapPacket, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("the EAP packet is too short")
+	}
+
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if length < 4 || length > len(b) {
+		return nil, fmt.Errorf("the length of the EAP packet: %d is invalid", length)
+	}
+
+	packet := &eapPacket{code: b[0], identifier: b[1]}
+	if length > 4 {
+		packet.typ = b[4]
+		packet.data = b[5:length]
+	}
+	return packet, nil
+}
+
+func (p *eapPacket) encode() []byte {
+	length := 4
+	if p.code == eapCodeRequest || p.code == eapCodeResponse {
+		length += 1 + len(p.data)
+	}
+
+	b := make([]byte, length)
+	b[0] = p.code
+	b[1] = p.identifier
+	binary.BigEndian.PutUint16(b[2:4], uint16(length))
+	if length > 4 {
+		b[4] = p.typ
+		copy(b[5:], p.data)
+	}
+	return b
+}
+
+// parseDiameterAvps returns the values of the non-vendor AVPs tunneled in EAP-TTLS, see RFC 5281 section 10.1
+func parseDiameterAvps(data []byte) (map[uint32][]byte, error) {
+	res := map[uint32][]byte{}
+	for len(data) >= 8 {
+		code := binary.BigEndian.Uint32(data[0:4])
+		flags := data[4]
+		length := int(data[5])<<16 | int(data[6])<<8 | int(data[7])
+
+		headerLength := 8
+		if flags&diameterAvpFlagVendor != 0 {
+			headerLength = 12
+		}
+		if length < headerLength || length > len(data) {
+			return nil, fmt.Errorf("the length of the AVP: %d is invalid", length)
+		}
+
+		if flags&diameterAvpFlagVendor == 0 {
+			res[code] = data[headerLength:length]
+		}
+
+		// the AVPs are padded to 4 bytes
+		length = (length + 3) &^ 3
+		if length > len(data) {
+			length = len(data)
+		}
+		data = data[length:]
+	}
+	return res, nil
+}
+
+type ttlsResult struct {
+	username string
+	password string
+	msk      []byte
+	err      error
+}
+
+// eapSession is an EAP-TTLS session across the Access-Challenge round trips, which is keyed by the State attribute
+type eapSession struct {
+	tlsConfig  *tls.Config
+	identifier byte
+	conn       *eapConn
+	started    bool
+	done       chan *ttlsResult
+	mutex      sync.Mutex
+
+	inBuf    []byte
+	outBuf   []byte
+	outFirst bool
+}
+
+func newEapSession(tlsConfig *tls.Config) *eapSession {
+	return &eapSession{
+		tlsConfig: tlsConfig,
+		conn:      newEapConn(),
+		done:      make(chan *ttlsResult, 1),
+	}
+}
+
+func (s *eapSession) Close() error {
+	return s.conn.Close()
+}
+
+// run establishes the TLS tunnel and reads the credentials of the inner PAP
+func (s *eapSession) run() {
+	result := &ttlsResult{}
+	defer func() {
+		s.done <- result
+	}()
+
+	tlsConn := tls.Server(s.conn, s.tlsConfig)
+	err := tlsConn.Handshake()
+	if err != nil {
+		result.err = err
+		return
+	}
+
+	state := tlsConn.ConnectionState()
+	keyingMaterial, err := state.ExportKeyingMaterial("ttls keying material", nil, 128)
+	if err != nil {
+		result.err = err
+		return
+	}
+	result.msk = keyingMaterial[:64]
+
+	buf := make([]byte, 16384)
+	n, err := tlsConn.Read(buf)
+	if err != nil {
+		result.err = err
+		return
+	}
+
+	avps, err := parseDiameterAvps(buf[:n])
+	if err != nil {
+		result.err = err
+		return
+	}
+
+	password, ok := avps[diameterAvpUserPassword]
+	if !ok {
+		result.err = fmt.Errorf("only PAP is supported in the EAP-TTLS tunnel")
+		return
+	}
+
+	// the password may be padded with nulls
+	for len(password) > 0 && password[len(password)-1] == 0 {
+		password = password[:len(password)-1]
+	}
+
+	result.username = string(avps[diameterAvpUserName])
+	result.password = string(password)
+}
+
+// step feeds the TLS records of the client to the TLS server, and returns the TLS records of the server,
+// or the result when the credentials are read
+func (s *eapSession) step(data []byte) ([]byte, *ttlsResult, error) {
+	if !s.started {
+		s.started = true
+		go s.run()
+	}
+
+	s.conn.in <- data
+
+	select {
+	case <-s.conn.waiting:
+		return s.conn.takeOutput(), nil, nil
+	case result := <-s.done:
+		return s.conn.takeOutput(), result, nil
+	case <-time.After(tlsStepTimeout):
+		return nil, nil, fmt.Errorf("the EAP-TTLS session is timed out")
+	}
+}
+
+func (s *eapSession) setOutput(data []byte) {
+	s.outBuf = data
+	s.outFirst = true
+}
+
+// nextFragment returns the EAP-TTLS data of the next fragment of the TLS records, see RFC 5281 section 9.2.2
+func (s *eapSession) nextFragment() []byte {
+	size := len(s.outBuf)
+	more := size > ttlsFragmentSize
+	if more {
+		size = ttlsFragmentSize
+	}
+
+	res := []byte{0}
+	if more && s.outFirst {
+		res[0] |= ttlsFlagLength
+		res = append(res, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(res[1:5], uint32(len(s.outBuf)))
+	}
+	if more {
+		res[0] |= ttlsFlagMore
+	}
+
+	res = append(res, s.outBuf[:size]...)
+	s.outBuf = s.outBuf[size:]
+	s.outFirst = false
+	return res
+}
+
+// isMessageAuthenticatorValid verifies the Message-Authenticator of the Access-Request, see RFC 3579 section 3.2
+func isMessageAuthenticatorValid(p *radius.Packet) bool {
+	value := rfc2869.MessageAuthenticator_Get(p)
+	if len(value) != md5.Size {
+		return false
+	}
+
+	raw, err := p.Encode()
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(value, getMessageAuthenticator(raw, p.Secret))
+}
+
+// setMessageAuthenticator adds the Message-Authenticator to the response, which is calculated with the
+// authenticator of the request, so it must be the last attribute added
+func setMessageAuthenticator(p *radius.Packet) error {
+	err := rfc2869.MessageAuthenticator_Set(p, make([]byte, md5.Size))
+	if err != nil {
+		return err
+	}
+
+	raw, err := p.Encode()
+	if err != nil {
+		return err
+	}
+
+	// the authenticator of the response packet is still the one of the request before it's encoded
+	copy(raw[4:20], p.Authenticator[:])
+	return rfc2869.MessageAuthenticator_Set(p, getMessageAuthenticator(raw, p.Secret))
+}
+
+func getMessageAuthenticator(raw []byte, secret []byte) []byte {
+	b := make([]byte, len(raw))
+	copy(b, raw)
+
+	for i := 20; i+2 <= len(b); {
+		length := int(b[i+1])
+		if length < 2 || i+length > len(b) {
+			break
+		}
+		if b[i] == byte(rfc2869.MessageAuthenticator_Type) {
+			for j := i + 2; j < i+length; j++ {
+				b[j] = 0
+			}
+		}
+		i += length
+	}
+
+	hash := hmac.New(md5.New, secret)
+	hash.Write(b)
+	return hash.Sum(nil)
+}
+
+func writeEapMessage(w radius.ResponseWriter, res *radius.Packet, eapMessage []byte) {
+	err := rfc2869.EAPMessage_Set(res, eapMessage)
+	if err == nil {
+		err = setMessageAuthenticator(res)
+	}
+	if err != nil {
+		log.Printf("writeEapMessage() error: %s", err.Error())
+		return
+	}
+
+	w.Write(res)
+}
+
+func writeEapRequest(w radius.ResponseWriter, r *radius.Request, state string, session *eapSession, data []byte) {
+	res := r.Response(radius.CodeAccessChallenge)
+	rfc2865.State_SetString(res, state)
+
+	packet := &eapPacket{code: eapCodeRequest, identifier: session.identifier, typ: eapTypeTtls, data: data}
+	writeEapMessage(w, res, packet.encode())
+}
+
+func writeEapFailure(w radius.ResponseWriter, r *radius.Request, identifier byte, message string) {
+	res := r.Response(radius.CodeAccessReject)
+	if message != "" {
+		rfc2865.ReplyMessage_SetString(res, message)
+	}
+
+	packet := &eapPacket{code: eapCodeFailure, identifier: identifier}
+	writeEapMessage(w, res, packet.encode())
+}
+
+func handleEapRequest(w radius.ResponseWriter, r *radius.Request, eapMessage []byte) {
+	// the Access-Request with EAP-Message but without a valid Message-Authenticator is silently discarded
+	if !isMessageAuthenticatorValid(r.Packet) {
+		log.Printf("handleEapRequest() error: the Message-Authenticator is invalid")
+		return
+	}
+
+	request, err := parseEapPacket(eapMessage)
+	if err != nil || request.code != eapCodeResponse {
+		writeEapFailure(w, r, 0, "")
+		return
+	}
+
+	state := rfc2865.State_GetString(r.Packet)
+	session, ok := getState(state).(*eapSession)
+	if !ok {
+		if request.typ != eapTypeIdentity || tlsConfig == nil {
+			writeEapFailure(w, r, request.identifier, "EAP-TTLS is not enabled")
+			return
+		}
+
+		session = newEapSession(tlsConfig)
+		session.identifier = request.identifier + 1
+		writeEapRequest(w, r, newState(session), session, []byte{ttlsFlagStart})
+		return
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	// the retransmitted responses are ignored
+	if request.identifier != session.identifier {
+		return
+	}
+
+	if request.typ != eapTypeTtls || len(request.data) == 0 {
+		deleteState(state)
+		writeEapFailure(w, r, request.identifier, "only EAP-TTLS is supported")
+		return
+	}
+
+	flags := request.data[0]
+	payload := request.data[1:]
+	if flags&ttlsFlagLength != 0 {
+		if len(payload) < 4 {
+			deleteState(state)
+			writeEapFailure(w, r, request.identifier, "")
+			return
+		}
+		payload = payload[4:]
+	}
+
+	session.identifier = request.identifier + 1
+	session.inBuf = append(session.inBuf, payload...)
+
+	// acknowledge the fragment of the client
+	if flags&ttlsFlagMore != 0 {
+		writeEapRequest(w, r, state, session, []byte{0})
+		return
+	}
+
+	// the client acknowledges the fragment of the server
+	if len(session.inBuf) == 0 {
+		if len(session.outBuf) == 0 {
+			deleteState(state)
+			writeEapFailure(w, r, request.identifier, "")
+			return
+		}
+
+		writeEapRequest(w, r, state, session, session.nextFragment())
+		return
+	}
+
+	data := session.inBuf
+	session.inBuf = nil
+	output, result, err := session.step(data)
+	if err != nil {
+		log.Printf("handleEapRequest() error: %s", err.Error())
+		deleteState(state)
+		writeEapFailure(w, r, request.identifier, "")
+		return
+	}
+
+	if result == nil {
+		session.setOutput(output)
+		writeEapRequest(w, r, state, session, session.nextFragment())
+		return
+	}
+
+	deleteState(state)
+	if result.err != nil {
+		log.Printf("handleEapRequest() error: %s", result.err.Error())
+		writeEapFailure(w, r, request.identifier, "")
+		return
+	}
+
+	handleTtlsResult(w, r, request.identifier, result)
+}
+
+func handleTtlsResult(w radius.ResponseWriter, r *radius.Request, identifier byte, result *ttlsResult) {
+	organization, username, err := getOrganization(r, result.username)
+	if err != nil {
+		writeEapFailure(w, r, identifier, err.Error())
+		return
+	}
+
+	clientIp := getClientIp(r)
+	err = object.CheckSigninThrottle(clientIp, organization, username, "en")
+	if err != nil {
+		writeEapFailure(w, r, identifier, err.Error())
+		return
+	}
+
+	user, err := object.CheckUserPassword(organization, username, result.password, "en")
+	object.RecordSigninThrottle(clientIp, organization, username, err == nil)
+	if err != nil {
+		writeEapFailure(w, r, identifier, err.Error())
+		return
+	}
+
+	if user.IsMfaEnabled() {
+		writeEapFailure(w, r, identifier, mfaNotSupportedMessage)
+		return
+	}
+
+	res, err := newAccessAccept(r, user)
+	if err != nil {
+		writeEapFailure(w, r, identifier, err.Error())
+		return
+	}
+
+	err = rfc2548.MSMPPERecvKey_Add(res, result.msk[:32])
+	if err == nil {
+		err = rfc2548.MSMPPESendKey_Add(res, result.msk[32:64])
+	}
+	if err != nil {
+		log.Printf("handleTtlsResult() error: %s", err.Error())
+		writeEapFailure(w, r, identifier, "")
+		return
+	}
+
+	packet := &eapPacket{code: eapCodeSuccess, identifier: identifier}
+	writeEapMessage(w, res, packet.encode())
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"crypto/des"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/md4"
+)
+
+// MS-CHAPv2 is implemented by RFC 2759 and the MPPE keys are derived by RFC 3079
+var (
+	msChapMagic1 = []byte("Magic server to client signing constant")
+	msChapMagic2 = []byte("Pad to make it do more than one iteration")
+
+	mppeMasterKeyMagic = []byte("This is the MPPE Master Key")
+	mppeRecvKeyMagic   = []byte("On the client side, this is the send key; on the server side, it is the receive key.")
+	mppeSendKeyMagic   = []byte("On the client side, this is the receive key; on the server side, it is the send key.")
+)
+
+// msChapV2Response is the value of the MS-CHAP2-Response attribute, see RFC 2548 section 2.3.2
+type msChapV2Response struct {
+	ident         byte
+	peerChallenge []byte
+	ntResponse    []byte
+}
+
+func parseMsChapV2Response(value []byte) (*msChapV2Response, error) {
+	if len(value) != 50 {
+		return nil, fmt.Errorf("the length of MS-CHAP2-Response: %d is invalid", len(value))
+	}
+
+	return &msChapV2Response{
+		ident:         value[0],
+		peerChallenge: value[2:18],
+		ntResponse:    value[26:50],
+	}, nil
+}
+
+// getMsChapUsername returns the user name used in the challenge hash, the Windows domain is stripped by the peer
+func getMsChapUsername(username string) string {
+	if index := strings.LastIndex(username, "\\"); index != -1 {
+		return username[index+1:]
+	}
+	return username
+}
+
+func md4Sum(data []byte) []byte {
+	hash := md4.New()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func getChallengeHash(peerChallenge []byte, authenticatorChallenge []byte, username string) []byte {
+	hash := sha1.New()
+	hash.Write(peerChallenge)
+	hash.Write(authenticatorChallenge)
+	hash.Write([]byte(username))
+	return hash.Sum(nil)[:8]
+}
+
+// getDesKey expands the 7-byte key to the 8-byte DES key, the parity bits are ignored by DES
+func getDesKey(b []byte) []byte {
+	return []byte{
+		b[0] & 0xfe,
+		b[0]<<7 | b[1]>>1,
+		b[1]<<6 | b[2]>>2,
+		b[2]<<5 | b[3]>>3,
+		b[3]<<4 | b[4]>>4,
+		b[4]<<3 | b[5]>>5,
+		b[5]<<2 | b[6]>>6,
+		b[6] << 1,
+	}
+}
+
+func getNtResponse(authenticatorChallenge []byte, response *msChapV2Response, username string, passwordHash []byte) []byte {
+	challengeHash := getChallengeHash(response.peerChallenge, authenticatorChallenge, username)
+
+	zPasswordHash := make([]byte, 21)
+	copy(zPasswordHash, passwordHash)
+
+	res := make([]byte, 24)
+	for i := 0; i < 3; i++ {
+		// the key is always 8 bytes, so the cipher can't fail to be created
+		block, _ := des.NewCipher(getDesKey(zPasswordHash[i*7 : i*7+7]))
+		block.Encrypt(res[i*8:i*8+8], challengeHash)
+	}
+	return res
+}
+
+// getAuthenticatorResponse returns the "S=<auth_string>" sent in MS-CHAP2-Success, so that the peer can
+// authenticate the server
+func getAuthenticatorResponse(authenticatorChallenge []byte, response *msChapV2Response, username string, passwordHash []byte) string {
+	hash := sha1.New()
+	hash.Write(md4Sum(passwordHash))
+	hash.Write(response.ntResponse)
+	hash.Write(msChapMagic1)
+	digest := hash.Sum(nil)
+
+	hash = sha1.New()
+	hash.Write(digest)
+	hash.Write(getChallengeHash(response.peerChallenge, authenticatorChallenge, username))
+	hash.Write(msChapMagic2)
+	digest = hash.Sum(nil)
+
+	return fmt.Sprintf("S=%X", digest)
+}
+
+// getMppeKeys returns the send key and the receive key of the server
+func getMppeKeys(response *msChapV2Response, passwordHash []byte) ([]byte, []byte) {
+	hash := sha1.New()
+	hash.Write(md4Sum(passwordHash))
+	hash.Write(response.ntResponse)
+	hash.Write(mppeMasterKeyMagic)
+	masterKey := hash.Sum(nil)[:16]
+
+	getKey := func(magic []byte) []byte {
+		hash := sha1.New()
+		hash.Write(masterKey)
+		hash.Write(make([]byte, 40))
+		hash.Write(magic)
+		for i := 0; i < 40; i++ {
+			hash.Write([]byte{0xf2})
+		}
+		return hash.Sum(nil)[:16]
+	}
+
+	return getKey(mppeSendKeyMagic), getKey(mppeRecvKeyMagic)
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// the test vectors are from RFC 2759 section 9.2 and RFC 3079 section 3.5.3
+func TestMsChapV2(t *testing.T) {
+	authenticatorChallenge := decodeHex(t, "5B5D7C7D7B3F2F3E3C2C602132262628")
+	response := &msChapV2Response{
+		peerChallenge: decodeHex(t, "21402324255E262A28295F2B3A337C7E"),
+		ntResponse:    decodeHex(t, "82309ECD8D708B5EA08FAA3981CD83544233114A3D85D6DF"),
+	}
+
+	passwordHash := util.GetNtPasswordHash("clientPass")
+	assert.Equal(t, decodeHex(t, "44EBBA8D5312B8D611474411F56989AE"), passwordHash)
+	assert.Equal(t, decodeHex(t, "D02E4386BCE91226"), getChallengeHash(response.peerChallenge, authenticatorChallenge, "User"))
+	assert.Equal(t, response.ntResponse, getNtResponse(authenticatorChallenge, response, "User", passwordHash))
+	assert.Equal(t, "S=407A5589115FD0D6209F510FE9C04566932CDA56", getAuthenticatorResponse(authenticatorChallenge, response, "User", passwordHash))
+
+	sendKey, _ := getMppeKeys(response, passwordHash)
+	assert.Equal(t, decodeHex(t, "8B7CDC149B993A1BA118CB153F56DCCB"), sendKey)
+}
+
+func TestGetMsChapUsername(t *testing.T) {
+	assert.Equal(t, "alice", getMsChapUsername("EXAMPLE\\alice"))
+	assert.Equal(t, "built-in/alice", getMsChapUsername("built-in/alice"))
+}
@@ -15,6 +15,8 @@
 package radius
 
 import (
+	"crypto/subtle"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"strings"
@@ -23,16 +25,27 @@ import (
 	"github.com/casdoor/casdoor/object"
 	"github.com/casdoor/casdoor/util"
 	"layeh.com/radius"
+	"layeh.com/radius/rfc2548"
 	"layeh.com/radius/rfc2865"
 	"layeh.com/radius/rfc2866"
+	"layeh.com/radius/rfc2869"
 )
 
+// tlsConfig is the TLS config of EAP-TTLS, EAP is disabled if it's nil
+var tlsConfig *tls.Config
+
 func StartRadiusServer() {
+	var err error
+	tlsConfig, err = getTlsConfig()
+	if err != nil {
+		log.Printf("StartRadiusServer() failed to load the TLS certificate, EAP is disabled, err = %v", err)
+	}
+
 	secret := conf.GetConfigString("radiusSecret")
 	server := radius.PacketServer{
 		Addr:         "0.0.0.0:" + conf.GetConfigString("radiusServerPort"),
 		Handler:      radius.HandlerFunc(handlerRadius),
-		SecretSource: radius.StaticSecretSource([]byte(secret)),
+		SecretSource: &secretSource{secret: []byte(secret)},
 	}
 	log.Printf("Starting Radius server on %s", server.Addr)
 	if err := server.ListenAndServe(); err != nil {
@@ -51,24 +64,199 @@ func handlerRadius(w radius.ResponseWriter, r *radius.Request) {
 	}
 }
 
+const mfaNotSupportedMessage = "multi-factor authentication is only supported by PAP"
+
+func writeAccessReject(w radius.ResponseWriter, r *radius.Request, message string) {
+	res := r.Response(radius.CodeAccessReject)
+	if message != "" {
+		rfc2865.ReplyMessage_SetString(res, message)
+	}
+	w.Write(res)
+}
+
+// newAccessAccept returns the Access-Accept with the reply attributes of the RADIUS policies matched by the user,
+// the user who matches none of the policies of the organization is rejected
+func newAccessAccept(r *radius.Request, user *object.User) (*radius.Packet, error) {
+	attributes, err := object.GetRadiusReplyAttributes(user)
+	if err != nil {
+		return nil, err
+	}
+
+	res := r.Response(radius.CodeAccessAccept)
+	for _, attribute := range attributes {
+		typ, value, err := attribute.Encode()
+		if err != nil {
+			return nil, err
+		}
+		res.Add(radius.Type(typ), radius.Attribute(value))
+	}
+	return res, nil
+}
+
+func writeAccessAccept(w radius.ResponseWriter, r *radius.Request, user *object.User) {
+	res, err := newAccessAccept(r, user)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+	w.Write(res)
+}
+
 func handleAccessRequest(w radius.ResponseWriter, r *radius.Request) {
+	eapMessage, err := rfc2869.EAPMessage_Lookup(r.Packet)
+	if err == nil {
+		handleEapRequest(w, r, eapMessage)
+		return
+	}
+
+	// the Message-Authenticator is optional for PAP and MS-CHAPv2, but must be valid if it's present
+	if rfc2869.MessageAuthenticator_Get(r.Packet) != nil && !isMessageAuthenticatorValid(r.Packet) {
+		log.Printf("handleAccessRequest() error: the Message-Authenticator is invalid")
+		return
+	}
+
 	username := rfc2865.UserName_GetString(r.Packet)
+	organization, name, err := getOrganization(r, username)
+	log.Printf("handleAccessRequest() username=%v, org=%v", name, organization)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	if rfc2548.MSCHAP2Response_Get(r.Packet) != nil {
+		handleMsChapV2Request(w, r, organization, name, username)
+		return
+	}
+
+	handlePapRequest(w, r, organization, name)
+}
+
+// mfaChallenge is the pending Access-Challenge of a user who has passed the password check
+type mfaChallenge struct {
+	userId string
+}
+
+func handlePapRequest(w radius.ResponseWriter, r *radius.Request, organization string, username string) {
 	password := rfc2865.UserPassword_GetString(r.Packet)
-	organization := rfc2865.Class_GetString(r.Packet)
-	log.Printf("handleAccessRequest() username=%v, org=%v, password=%v", username, organization, password)
 
-	if organization == "" {
-		w.Write(r.Response(radius.CodeAccessReject))
+	state := rfc2865.State_GetString(r.Packet)
+	if challenge, ok := getState(state).(*mfaChallenge); ok {
+		// the passcode is sent in User-Password in response to the Access-Challenge
+		deleteState(state)
+		handleMfaResponse(w, r, challenge, password)
+		return
+	}
+
+	clientIp := getClientIp(r)
+	err := object.CheckSigninThrottle(clientIp, organization, username, "en")
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	user, err := object.CheckUserPassword(organization, username, password, "en")
+	object.RecordSigninThrottle(clientIp, organization, username, err == nil)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	if !user.IsMfaEnabled() {
+		writeAccessAccept(w, r, user)
+		return
+	}
+
+	if user.TotpSecret == "" {
+		writeAccessReject(w, r, "only the TOTP authenticator app is supported for multi-factor authentication")
 		return
 	}
 
-	_, err := object.CheckUserPassword(organization, username, password, "en")
+	res := r.Response(radius.CodeAccessChallenge)
+	rfc2865.State_SetString(res, newState(&mfaChallenge{userId: user.GetId()}))
+	rfc2865.ReplyMessage_SetString(res, "Please enter the passcode of your authenticator app")
+	w.Write(res)
+}
+
+func handleMfaResponse(w radius.ResponseWriter, r *radius.Request, challenge *mfaChallenge, passcode string) {
+	user, err := object.GetUser(challenge.userId)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	if user == nil || user.IsForbidden || user.TotpSecret == "" {
+		writeAccessReject(w, r, "")
+		return
+	}
+
+	mfaUtil := object.GetMfaUtil(object.TotpType, user.GetMfaProps(object.TotpType, false))
+	err = mfaUtil.Verify(passcode)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	writeAccessAccept(w, r, user)
+}
+
+func handleMsChapV2Request(w radius.ResponseWriter, r *radius.Request, organization string, name string, username string) {
+	authenticatorChallenge := rfc2548.MSCHAPChallenge_Get(r.Packet)
+	response, err := parseMsChapV2Response(rfc2548.MSCHAP2Response_Get(r.Packet))
+	if err != nil || len(authenticatorChallenge) != 16 {
+		writeAccessReject(w, r, "")
+		return
+	}
+
+	// the challenge hash uses the user name sent by the peer, including the organization if any
+	msChapUsername := getMsChapUsername(username)
+	clientIp := getClientIp(r)
+	var passwordHash []byte
+	var user *object.User
+	err = object.CheckSigninThrottle(clientIp, organization, name, "en")
+	if err == nil {
+		user, err = object.CheckUserPasswordByVerifier(organization, name, "en", func(ntPasswordHash []byte) bool {
+			passwordHash = ntPasswordHash
+			ntResponse := getNtResponse(authenticatorChallenge, response, msChapUsername, passwordHash)
+			return subtle.ConstantTimeCompare(ntResponse, response.ntResponse) == 1
+		})
+		object.RecordSigninThrottle(clientIp, organization, name, err == nil)
+	}
+	if err != nil {
+		res := r.Response(radius.CodeAccessReject)
+		// E=691 is ERROR_AUTHENTICATION_FAILURE, and R=0 disables the retry
+		msChapError := fmt.Sprintf("E=691 R=0 C=%X V=3 M=%s", authenticatorChallenge, err.Error())
+		rfc2548.MSCHAPError_Add(res, append([]byte{response.ident}, msChapError...))
+		w.Write(res)
+		return
+	}
+
+	if user.IsMfaEnabled() {
+		writeAccessReject(w, r, mfaNotSupportedMessage)
+		return
+	}
+
+	res, err := newAccessAccept(r, user)
+	if err != nil {
+		writeAccessReject(w, r, err.Error())
+		return
+	}
+
+	authenticatorResponse := getAuthenticatorResponse(authenticatorChallenge, response, msChapUsername, passwordHash)
+	sendKey, recvKey := getMppeKeys(response, passwordHash)
+	err = rfc2548.MSCHAP2Success_Add(res, append([]byte{response.ident}, authenticatorResponse...))
+	if err == nil {
+		err = rfc2548.MSMPPERecvKey_Add(res, recvKey)
+	}
+	if err == nil {
+		err = rfc2548.MSMPPESendKey_Add(res, sendKey)
+	}
 	if err != nil {
-		w.Write(r.Response(radius.CodeAccessReject))
+		log.Printf("handleMsChapV2Request() error: %s", err.Error())
+		writeAccessReject(w, r, "")
 		return
 	}
 
-	w.Write(r.Response(radius.CodeAccessAccept))
+	w.Write(res)
 }
 
 func handleAccountingRequest(w radius.ResponseWriter, r *radius.Request) {
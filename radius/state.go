@@ -0,0 +1,85 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+)
+
+// the pending Access-Challenge round trips expire after the timeout
+const stateTimeout = 5 * time.Minute
+
+type stateEntry struct {
+	value      interface{}
+	expireTime time.Time
+}
+
+var (
+	stateMutex sync.Mutex
+	states     = map[string]*stateEntry{}
+)
+
+// newState stores the value of an Access-Challenge round trip and returns the value of the State attribute,
+// which is sent back by the client in the next Access-Request
+func newState(value interface{}) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	state := hex.EncodeToString(b)
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	now := time.Now()
+	for key, entry := range states {
+		if now.After(entry.expireTime) {
+			closeStateValue(entry.value)
+			delete(states, key)
+		}
+	}
+
+	states[state] = &stateEntry{value: value, expireTime: now.Add(stateTimeout)}
+	return state
+}
+
+func getState(state string) interface{} {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	entry, ok := states[state]
+	if !ok || time.Now().After(entry.expireTime) {
+		return nil
+	}
+	return entry.value
+}
+
+func deleteState(state string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	if entry, ok := states[state]; ok {
+		closeStateValue(entry.value)
+		delete(states, state)
+	}
+}
+
+func closeStateValue(value interface{}) {
+	if closer, ok := value.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package radius
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/object"
+)
+
+// getTlsConfig returns the TLS config of the EAP-TTLS tunnel, the certificate is read from the cert
+// "radiusCertId" in the cert table, or from the files "radiusCertFile" and "radiusKeyFile". It returns
+// nil if no certificate is configured, and EAP is disabled then.
+func getTlsConfig() (*tls.Config, error) {
+	certId := conf.GetConfigString("radiusCertId")
+	certFile := conf.GetConfigString("radiusCertFile")
+	keyFile := conf.GetConfigString("radiusKeyFile")
+
+	// the keying material of EAP-TTLS is only defined for TLS 1.2 and below
+	if certId != "" {
+		// the cert is read in each handshake, so that the rotated cert takes effect without restarting
+		return &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			MaxVersion: tls.VersionTLS12,
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				cert, err := object.GetActiveCert(certId)
+				if err != nil {
+					return nil, err
+				}
+				if cert == nil {
+					return nil, fmt.Errorf("the cert: %s does not exist", certId)
+				}
+
+				certificate, err := tls.X509KeyPair([]byte(cert.Certificate), []byte(cert.PrivateKey))
+				if err != nil {
+					return nil, err
+				}
+				return &certificate, nil
+			},
+		}, nil
+	}
+
+	if certFile != "" && keyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			MaxVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{certificate},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// the TLS server must respond to each EAP message within the timeout
+const tlsStepTimeout = 10 * time.Second
+
+type eapAddr struct{}
+
+func (eapAddr) Network() string {
+	return "eap"
+}
+
+func (eapAddr) String() string {
+	return "eap"
+}
+
+// eapConn carries the TLS records of an EAP session. The TLS server runs in its own goroutine, the records
+// received in EAP messages are fed to it, and the records it writes are collected until it waits for the
+// next records of the client.
+type eapConn struct {
+	in      chan []byte
+	waiting chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+
+	inBuf  bytes.Buffer
+	outBuf bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func newEapConn() *eapConn {
+	return &eapConn{
+		in:      make(chan []byte, 1),
+		waiting: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (c *eapConn) Read(b []byte) (int, error) {
+	for c.inBuf.Len() == 0 {
+		select {
+		case data := <-c.in:
+			c.inBuf.Write(data)
+			continue
+		default:
+		}
+
+		// tell the EAP session that all the records of the client are consumed
+		select {
+		case c.waiting <- struct{}{}:
+		case <-c.closed:
+			return 0, io.EOF
+		}
+
+		select {
+		case data := <-c.in:
+			c.inBuf.Write(data)
+		case <-c.closed:
+			return 0, io.EOF
+		}
+	}
+
+	return c.inBuf.Read(b)
+}
+
+func (c *eapConn) Write(b []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.outBuf.Write(b)
+}
+
+func (c *eapConn) takeOutput() []byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := make([]byte, c.outBuf.Len())
+	copy(res, c.outBuf.Bytes())
+	c.outBuf.Reset()
+	return res
+}
+
+func (c *eapConn) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *eapConn) LocalAddr() net.Addr {
+	return eapAddr{}
+}
+
+func (c *eapConn) RemoteAddr() net.Addr {
+	return eapAddr{}
+}
+
+func (c *eapConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *eapConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *eapConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
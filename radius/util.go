@@ -16,6 +16,7 @@ package radius
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/casdoor/casdoor/object"
@@ -65,3 +66,17 @@ func GetAccountingFromRequest(r *radius.Request) *object.RadiusAccounting {
 	}
 	return ra
 }
+
+// getClientIp returns the client of the sign-in throttle, which is the Calling-Station-Id of the user's device
+// if the NAS sends it, otherwise the NAS itself
+func getClientIp(r *radius.Request) string {
+	if callingStationId := rfc2865.CallingStationID_GetString(r.Packet); callingStationId != "" {
+		return callingStationId
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr.String())
+	if err != nil {
+		return r.RemoteAddr.String()
+	}
+	return host
+}
@@ -16,6 +16,7 @@ package routers
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/beego/beego/context"
 	"github.com/casdoor/casdoor/object"
@@ -27,8 +28,14 @@ func AutoSigninFilter(ctx *context.Context) {
 	//	return
 	//}
 
+	// the bearer tokens of the SCIM requests may be the SCIM tokens of the applications rather than
+	// access tokens, they are checked by the SCIM handler
+	if strings.HasPrefix(ctx.Request.URL.Path, "/scim") {
+		return
+	}
+
 	// GET parameter like "/page?access_token=123" or
-	// HTTP Bearer token like "Authorization: Bearer 123"
+	// HTTP Bearer token like "Authorization: Bearer 123", or DPoP token like "Authorization: DPoP 123"
 	accessToken := ctx.Input.Query("accessToken")
 	if accessToken == "" {
 		accessToken = ctx.Input.Query("access_token")
@@ -49,6 +56,17 @@ func AutoSigninFilter(ctx *context.Context) {
 			return
 		}
 
+		if token.IsRevoked {
+			responseError(ctx, "Access token has been revoked")
+			return
+		}
+
+		err = object.CheckTokenConfirmation(token, ctx.Request)
+		if err != nil {
+			responseError(ctx, err.Error())
+			return
+		}
+
 		isExpired, expireTime := util.IsTokenExpired(token.CreatedTime, token.ExpiresIn)
 		if isExpired {
 			responseError(ctx, fmt.Sprintf("Access token has expired, expireTime = %s", expireTime))
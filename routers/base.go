@@ -152,8 +152,9 @@ func parseBearerToken(ctx *context.Context) string {
 		return ""
 	}
 
+	// the access token bound to a DPoP key is sent with the "DPoP" scheme, per rfc 9449 section 7.1
 	prefix := tokens[0]
-	if prefix != "Bearer" {
+	if prefix != "Bearer" && prefix != "DPoP" {
 		return ""
 	}
 
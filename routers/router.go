@@ -53,6 +53,7 @@ func initAPI() {
 	beego.Router("/api/get-app-login", &controllers.ApiController{}, "GET:GetApplicationLogin")
 	beego.Router("/api/get-dashboard", &controllers.ApiController{}, "GET:GetDashboard")
 	beego.Router("/api/logout", &controllers.ApiController{}, "GET,POST:Logout")
+	beego.Router("/api/frontchannel-logout", &controllers.ApiController{}, "GET:FrontchannelLogout")
 	beego.Router("/api/get-account", &controllers.ApiController{}, "GET:GetAccount")
 	beego.Router("/api/userinfo", &controllers.ApiController{}, "GET:GetUserinfo")
 	beego.Router("/api/user", &controllers.ApiController{}, "GET:GetUserinfo2")
@@ -63,6 +64,8 @@ func initAPI() {
 	beego.Router("/api/webhook", &controllers.ApiController{}, "POST:HandleOfficialAccountEvent")
 	beego.Router("/api/get-webhook-event", &controllers.ApiController{}, "GET:GetWebhookEventType")
 	beego.Router("/api/get-captcha-status", &controllers.ApiController{}, "GET:GetCaptchaStatus")
+	beego.Router("/api/get-signin-lockouts", &controllers.ApiController{}, "GET:GetSigninLockouts")
+	beego.Router("/api/clear-signin-lockout", &controllers.ApiController{}, "POST:ClearSigninLockout")
 	beego.Router("/api/callback", &controllers.ApiController{}, "POST:Callback")
 
 	beego.Router("/api/get-organizations", &controllers.ApiController{}, "GET:GetOrganizations")
@@ -72,6 +75,7 @@ func initAPI() {
 	beego.Router("/api/delete-organization", &controllers.ApiController{}, "POST:DeleteOrganization")
 	beego.Router("/api/get-default-application", &controllers.ApiController{}, "GET:GetDefaultApplication")
 	beego.Router("/api/get-organization-names", &controllers.ApiController{}, "GET:GetOrganizationNames")
+	beego.Router("/api/get-password-type-counts", &controllers.ApiController{}, "GET:GetPasswordTypeCounts")
 
 	beego.Router("/api/get-global-users", &controllers.ApiController{}, "GET:GetGlobalUsers")
 	beego.Router("/api/get-users", &controllers.ApiController{}, "GET:GetUsers")
@@ -158,6 +162,8 @@ func initAPI() {
 	beego.Router("/api/update-provider", &controllers.ApiController{}, "POST:UpdateProvider")
 	beego.Router("/api/add-provider", &controllers.ApiController{}, "POST:AddProvider")
 	beego.Router("/api/delete-provider", &controllers.ApiController{}, "POST:DeleteProvider")
+	beego.Router("/api/get-provisioning-records", &controllers.ApiController{}, "GET:GetProvisioningRecords")
+	beego.Router("/api/reconcile-provider", &controllers.ApiController{}, "POST:ReconcileProvider")
 
 	beego.Router("/api/get-applications", &controllers.ApiController{}, "GET:GetApplications")
 	beego.Router("/api/get-application", &controllers.ApiController{}, "GET:GetApplication")
@@ -182,6 +188,10 @@ func initAPI() {
 	beego.Router("/api/login/oauth/access_token", &controllers.ApiController{}, "POST:GetOAuthToken")
 	beego.Router("/api/login/oauth/refresh_token", &controllers.ApiController{}, "POST:RefreshToken")
 	beego.Router("/api/login/oauth/introspect", &controllers.ApiController{}, "POST:IntrospectToken")
+	beego.Router("/api/login/oauth/revoke", &controllers.ApiController{}, "POST:RevokeToken")
+	beego.Router("/api/login/oauth/device_authorization", &controllers.ApiController{}, "POST:DeviceAuthorization")
+	beego.Router("/api/login/oauth/par", &controllers.ApiController{}, "POST:PushAuthorizationRequest")
+	beego.Router("/api/login/oauth/device", &controllers.ApiController{}, "GET:GetDeviceAuthApplication;POST:VerifyDeviceCode")
 
 	beego.Router("/api/get-sessions", &controllers.ApiController{}, "GET:GetSessions")
 	beego.Router("/api/get-session", &controllers.ApiController{}, "GET:GetSingleSession")
@@ -190,11 +200,29 @@ func initAPI() {
 	beego.Router("/api/delete-session", &controllers.ApiController{}, "POST:DeleteSession")
 	beego.Router("/api/is-session-duplicated", &controllers.ApiController{}, "GET:IsSessionDuplicated")
 
+	beego.Router("/api/get-records", &controllers.ApiController{}, "GET:GetRecords")
+	beego.Router("/api/export-records", &controllers.ApiController{}, "GET:ExportRecords")
+	beego.Router("/api/verify-records", &controllers.ApiController{}, "GET:VerifyRecords")
+
 	beego.Router("/api/get-webhooks", &controllers.ApiController{}, "GET:GetWebhooks")
 	beego.Router("/api/get-webhook", &controllers.ApiController{}, "GET:GetWebhook")
 	beego.Router("/api/update-webhook", &controllers.ApiController{}, "POST:UpdateWebhook")
 	beego.Router("/api/add-webhook", &controllers.ApiController{}, "POST:AddWebhook")
 	beego.Router("/api/delete-webhook", &controllers.ApiController{}, "POST:DeleteWebhook")
+	beego.Router("/api/get-webhook-deliveries", &controllers.ApiController{}, "GET:GetWebhookDeliveries")
+	beego.Router("/api/redeliver-webhook-delivery", &controllers.ApiController{}, "POST:RedeliverWebhookDelivery")
+
+	beego.Router("/api/get-radius-clients", &controllers.ApiController{}, "GET:GetRadiusClients")
+	beego.Router("/api/get-radius-client", &controllers.ApiController{}, "GET:GetRadiusClient")
+	beego.Router("/api/update-radius-client", &controllers.ApiController{}, "POST:UpdateRadiusClient")
+	beego.Router("/api/add-radius-client", &controllers.ApiController{}, "POST:AddRadiusClient")
+	beego.Router("/api/delete-radius-client", &controllers.ApiController{}, "POST:DeleteRadiusClient")
+
+	beego.Router("/api/get-radius-policies", &controllers.ApiController{}, "GET:GetRadiusPolicies")
+	beego.Router("/api/get-radius-policy", &controllers.ApiController{}, "GET:GetRadiusPolicy")
+	beego.Router("/api/update-radius-policy", &controllers.ApiController{}, "POST:UpdateRadiusPolicy")
+	beego.Router("/api/add-radius-policy", &controllers.ApiController{}, "POST:AddRadiusPolicy")
+	beego.Router("/api/delete-radius-policy", &controllers.ApiController{}, "POST:DeleteRadiusPolicy")
 
 	beego.Router("/api/get-syncers", &controllers.ApiController{}, "GET:GetSyncers")
 	beego.Router("/api/get-syncer", &controllers.ApiController{}, "GET:GetSyncer")
@@ -209,6 +237,7 @@ func initAPI() {
 	beego.Router("/api/update-cert", &controllers.ApiController{}, "POST:UpdateCert")
 	beego.Router("/api/add-cert", &controllers.ApiController{}, "POST:AddCert")
 	beego.Router("/api/delete-cert", &controllers.ApiController{}, "POST:DeleteCert")
+	beego.Router("/api/rotate-cert", &controllers.ApiController{}, "POST:RotateCert")
 
 	beego.Router("/api/get-subscriptions", &controllers.ApiController{}, "GET:GetSubscriptions")
 	beego.Router("/api/get-subscription", &controllers.ApiController{}, "GET:GetSubscription")
@@ -60,6 +60,24 @@ func fastAutoSignin(ctx *context.Context) (string, error) {
 	state := ctx.Input.Query("state")
 	nonce := ""
 	codeChallenge := ""
+	requestUri := ctx.Input.Query("request_uri")
+	request := ctx.Input.Query("request")
+	if requestUri != "" || request != "" {
+		oAuthRequest, msg, err := object.GetOAuthRequest(clientId, requestUri, request, ctx.Request.Host, getAcceptLanguage(ctx))
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return "", nil
+		}
+
+		responseType = oAuthRequest.ResponseType
+		redirectUri = oAuthRequest.RedirectUri
+		scope = oAuthRequest.Scope
+		state = oAuthRequest.State
+		nonce = oAuthRequest.Nonce
+		codeChallenge = oAuthRequest.CodeChallenge
+	}
 	if clientId == "" || responseType != "code" || redirectUri == "" {
 		return "", nil
 	}
@@ -76,13 +94,17 @@ func fastAutoSignin(ctx *context.Context) (string, error) {
 		return "", nil
 	}
 
-	code, err := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, ctx.Request.Host, getAcceptLanguage(ctx))
+	code, err := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, requestUri, ctx.Request.Host, getAcceptLanguage(ctx))
 	if err != nil {
 		return "", err
 	} else if code.Message != "" {
 		return "", fmt.Errorf(code.Message)
 	}
 
+	if requestUri != "" {
+		object.DeleteOAuthRequest(requestUri)
+	}
+
 	sep := "?"
 	if strings.Contains(redirectUri, "?") {
 		sep = "&"
@@ -0,0 +1,279 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elimity-com/scim/errors"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.7 Bulk Operations
+
+const (
+	BulkResponseSchema = "urn:ietf:params:scim:api:messages:2.0:BulkResponse"
+
+	BulkMaxOperations  = 1000
+	BulkMaxPayloadSize = 1048576
+)
+
+var bulkIdRegex = regexp.MustCompile(`bulkId:([^"/]+)`)
+
+type BulkRequest struct {
+	Schemas      []string        `json:"schemas"`
+	FailOnErrors int             `json:"failOnErrors,omitempty"`
+	Operations   []BulkOperation `json:"Operations"`
+}
+
+type BulkOperation struct {
+	Method  string          `json:"method"`
+	BulkId  string          `json:"bulkId,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Path    string          `json:"path"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type BulkResponse struct {
+	Schemas    []string                `json:"schemas"`
+	Operations []BulkOperationResponse `json:"Operations"`
+}
+
+type BulkOperationResponse struct {
+	Method   string          `json:"method"`
+	BulkId   string          `json:"bulkId,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	Location string          `json:"location,omitempty"`
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+func writeScimError(w http.ResponseWriter, scimErr errors.ScimError) {
+	data, err := json.Marshal(scimErr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(scimErr.Status)
+	_, _ = w.Write(data)
+}
+
+func newBulkErrorResponse(operation BulkOperation, scimErr errors.ScimError) BulkOperationResponse {
+	data, _ := json.Marshal(scimErr)
+	return BulkOperationResponse{
+		Method:   operation.Method,
+		BulkId:   operation.BulkId,
+		Status:   strconv.Itoa(scimErr.Status),
+		Response: data,
+	}
+}
+
+func getUnresolvedBulkIds(operation BulkOperation, bulkIds map[string]string) []string {
+	res := []string{}
+	for _, text := range []string{operation.Path, string(operation.Data)} {
+		for _, match := range bulkIdRegex.FindAllStringSubmatch(text, -1) {
+			if _, ok := bulkIds[match[1]]; !ok {
+				res = append(res, match[1])
+			}
+		}
+	}
+	return res
+}
+
+// resolveBulkIds replaces the "bulkId:<bulk id>" references in the operation with the ids of the resources
+// created by the previous operations.
+func resolveBulkIds(operation BulkOperation, bulkIds map[string]string) BulkOperation {
+	replace := func(s string) string {
+		return bulkIdRegex.ReplaceAllStringFunc(s, func(match string) string {
+			return bulkIds[strings.TrimPrefix(match, "bulkId:")]
+		})
+	}
+	operation.Path = replace(operation.Path)
+	if len(operation.Data) != 0 {
+		operation.Data = json.RawMessage(replace(string(operation.Data)))
+	}
+	return operation
+}
+
+func isBulkPathValid(path string) bool {
+	for _, resourceType := range Server.ResourceTypes {
+		if path == resourceType.Endpoint || strings.HasPrefix(path, resourceType.Endpoint+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func getRequestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// doBulkOperation runs the operation against the SCIM server as a standalone request with the headers and the
+// context of the bulk request, so that it gets the same authentication and validation. It returns the id of
+// the created resource for POST operations.
+func doBulkOperation(r *http.Request, operation BulkOperation) (BulkOperationResponse, string) {
+	method := strings.ToUpper(operation.Method)
+	if method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch && method != http.MethodDelete {
+		return newBulkErrorResponse(operation, errors.ScimErrorBadRequest(fmt.Sprintf("invalid method: %s", operation.Method))), ""
+	}
+	if method == http.MethodPost && operation.BulkId == "" {
+		return newBulkErrorResponse(operation, errors.ScimErrorBadRequest("bulkId is required for POST operations")), ""
+	}
+	if !isBulkPathValid(operation.Path) {
+		return newBulkErrorResponse(operation, errors.ScimErrorBadRequest(fmt.Sprintf("invalid path: %s", operation.Path))), ""
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), method, operation.Path, bytes.NewReader(operation.Data))
+	if err != nil {
+		return newBulkErrorResponse(operation, errors.ScimErrorBadRequest(err.Error())), ""
+	}
+	req.Header = r.Header.Clone()
+	req.Host = r.Host
+	req.RemoteAddr = r.RemoteAddr
+	if operation.Version != "" {
+		req.Header.Set("If-Match", operation.Version)
+	}
+
+	recorder := httptest.NewRecorder()
+	Server.ServeHTTP(recorder, req)
+
+	res := BulkOperationResponse{
+		Method:  operation.Method,
+		BulkId:  operation.BulkId,
+		Version: recorder.Header().Get("Etag"),
+		Status:  strconv.Itoa(recorder.Code),
+	}
+	if recorder.Code >= http.StatusBadRequest {
+		res.Response = recorder.Body.Bytes()
+		return res, ""
+	}
+
+	switch method {
+	case http.MethodPost:
+		resource := struct {
+			Id string `json:"id"`
+		}{}
+		err = json.Unmarshal(recorder.Body.Bytes(), &resource)
+		if err != nil {
+			return newBulkErrorResponse(operation, errors.ScimErrorBadRequest(err.Error())), ""
+		}
+		res.Location = fmt.Sprintf("%s%s%s/%s", getRequestOrigin(r), ScimPathPrefix, operation.Path, resource.Id)
+		return res, resource.Id
+	case http.MethodPut, http.MethodPatch:
+		res.Location = fmt.Sprintf("%s%s%s", getRequestOrigin(r), ScimPathPrefix, operation.Path)
+	}
+	return res, ""
+}
+
+func handleBulk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeScimError(w, errors.ScimError{Status: http.StatusMethodNotAllowed, Detail: "Bulk requests must use POST."})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, BulkMaxPayloadSize+1))
+	if err != nil {
+		writeScimError(w, errors.ScimErrorBadRequest(err.Error()))
+		return
+	}
+	if len(body) > BulkMaxPayloadSize {
+		writeScimError(w, errors.ScimError{Status: http.StatusRequestEntityTooLarge, Detail: fmt.Sprintf("The size of the bulk operation exceeds the maxPayloadSize (%d).", BulkMaxPayloadSize)})
+		return
+	}
+
+	var bulkRequest BulkRequest
+	err = json.Unmarshal(body, &bulkRequest)
+	if err != nil {
+		writeScimError(w, errors.ScimErrorInvalidSyntax)
+		return
+	}
+	if len(bulkRequest.Operations) > BulkMaxOperations {
+		writeScimError(w, errors.ScimError{Status: http.StatusRequestEntityTooLarge, Detail: fmt.Sprintf("The number of operations exceeds the maxOperations (%d).", BulkMaxOperations)})
+		return
+	}
+
+	// The operations may reference the resources created by the later operations, so the operations whose
+	// bulkId references can't be resolved yet are postponed until no more operations can be processed.
+	responses := make([]*BulkOperationResponse, len(bulkRequest.Operations))
+	bulkIds := map[string]string{}
+	errorCount := 0
+	for processed := true; processed; {
+		processed = false
+		for i, operation := range bulkRequest.Operations {
+			if responses[i] != nil || len(getUnresolvedBulkIds(operation, bulkIds)) != 0 {
+				continue
+			}
+
+			res, id := doBulkOperation(r, resolveBulkIds(operation, bulkIds))
+			responses[i] = &res
+			processed = true
+
+			if res.Response != nil {
+				errorCount++
+				if bulkRequest.FailOnErrors > 0 && errorCount >= bulkRequest.FailOnErrors {
+					writeBulkResponse(w, responses)
+					return
+				}
+			} else if id != "" {
+				bulkIds[operation.BulkId] = id
+			}
+		}
+	}
+
+	for i, operation := range bulkRequest.Operations {
+		if responses[i] == nil {
+			unresolvedBulkIds := getUnresolvedBulkIds(operation, bulkIds)
+			res := newBulkErrorResponse(operation, errors.ScimError{
+				Status: http.StatusConflict,
+				Detail: fmt.Sprintf("The bulkId references can't be resolved: %s", strings.Join(unresolvedBulkIds, ", ")),
+			})
+			responses[i] = &res
+		}
+	}
+	writeBulkResponse(w, responses)
+}
+
+func writeBulkResponse(w http.ResponseWriter, responses []*BulkOperationResponse) {
+	bulkResponse := BulkResponse{
+		Schemas:    []string{BulkResponseSchema},
+		Operations: []BulkOperationResponse{},
+	}
+	for _, res := range responses {
+		if res != nil {
+			bulkResponse.Operations = append(bulkResponse.Operations, *res)
+		}
+	}
+
+	data, err := json.Marshal(bulkResponse)
+	if err != nil {
+		writeScimError(w, errors.ScimError{Status: http.StatusInternalServerError, Detail: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
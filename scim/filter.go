@@ -0,0 +1,205 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/elimity-com/scim/errors"
+	"github.com/scim2/filter-parser/v2"
+	"github.com/xorm-io/builder"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.2 Filtering
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.4.2.3 Sorting
+
+// userColumns maps the lowercase SCIM attribute paths of users to the columns of the user table
+var userColumns = map[string]string{
+	"id":                 "id",
+	"externalid":         "external_id",
+	"username":           "name",
+	"displayname":        "display_name",
+	"nickname":           "display_name",
+	"usertype":           "type",
+	"profileurl":         "homepage",
+	"name.givenname":     "first_name",
+	"name.familyname":    "last_name",
+	"emails":             "email",
+	"emails.value":       "email",
+	"phonenumbers":       "phone",
+	"phonenumbers.value": "phone",
+	"photos":             "avatar",
+	"photos.value":       "avatar",
+	"addresses.locality": "location",
+	"addresses.region":   "region",
+	"addresses.country":  "country_code",
+	"meta.created":       "created_time",
+	"meta.lastmodified":  "updated_time",
+
+	strings.ToLower(UserExtensionKey) + ":organization": "owner",
+}
+
+// groupColumns maps the lowercase SCIM attribute paths of groups to the columns of the group table
+var groupColumns = map[string]string{
+	"id":                "id",
+	"externalid":        "external_id",
+	"displayname":       "display_name",
+	"meta.created":      "created_time",
+	"meta.lastmodified": "updated_time",
+
+	strings.ToLower(GroupExtensionKey) + ":organization": "owner",
+}
+
+func getAttributeName(path filter.AttributePath, parent string) string {
+	name := path.AttributeName
+	if parent != "" {
+		name = fmt.Sprintf("%s.%s", parent, name)
+	}
+	if path.SubAttribute != nil {
+		name = fmt.Sprintf("%s.%s", name, *path.SubAttribute)
+	}
+	// The URI of the core schemas can be omitted, e.g. urn:ietf:params:scim:schemas:core:2.0:User:userName
+	uri := path.URI()
+	if uri != "" && !strings.HasPrefix(uri, "urn:ietf:params:scim:schemas:core:") {
+		name = fmt.Sprintf("%s:%s", uri, name)
+	}
+	return strings.ToLower(name)
+}
+
+func getFilterColumn(path filter.AttributePath, parent string, columns map[string]string) (string, error) {
+	column, ok := columns[getAttributeName(path, parent)]
+	if !ok {
+		return "", errors.ScimErrorBadRequest(fmt.Sprintf("the attribute: %s is not supported", path.String()))
+	}
+	return column, nil
+}
+
+// getFilterCond translates a SCIM filter expression into a SQL condition on the columns, e.g.
+// userName eq "alice" and not (emails co "@example.com") => name=? AND NOT email LIKE ? ESCAPE '!'
+func getFilterCond(expr filter.Expression, columns map[string]string) (builder.Cond, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	return buildFilterCond(expr, "", columns)
+}
+
+func buildFilterCond(expr filter.Expression, parent string, columns map[string]string) (builder.Cond, error) {
+	switch e := expr.(type) {
+	case *filter.LogicalExpression:
+		left, err := buildFilterCond(e.Left, parent, columns)
+		if err != nil {
+			return nil, err
+		}
+		right, err := buildFilterCond(e.Right, parent, columns)
+		if err != nil {
+			return nil, err
+		}
+		if e.Operator == filter.OR {
+			return builder.Or(left, right), nil
+		}
+		return builder.And(left, right), nil
+	case *filter.NotExpression:
+		cond, err := buildFilterCond(e.Expression, parent, columns)
+		if err != nil {
+			return nil, err
+		}
+		return builder.Not{cond}, nil
+	case *filter.ValuePath:
+		// e.g. emails[value ew "@example.com"], the attributes in the filter are sub-attributes of emails
+		return buildFilterCond(e.ValueFilter, getAttributeName(e.AttributePath, parent), columns)
+	case *filter.AttributeExpression:
+		column, err := getFilterColumn(e.AttributePath, parent, columns)
+		if err != nil {
+			return nil, err
+		}
+		return buildAttributeCond(column, e.Operator, e.CompareValue)
+	default:
+		return nil, errors.ScimErrorInvalidFilter
+	}
+}
+
+func buildAttributeCond(column string, operator filter.CompareOperator, compareValue interface{}) (builder.Cond, error) {
+	if operator == filter.PR {
+		return builder.And(builder.NotNull{column}, builder.Neq{column: ""}), nil
+	}
+
+	if compareValue == nil {
+		// e.g. externalId eq null
+		switch operator {
+		case filter.EQ:
+			return builder.Or(builder.IsNull{column}, builder.Eq{column: ""}), nil
+		case filter.NE:
+			return builder.And(builder.NotNull{column}, builder.Neq{column: ""}), nil
+		default:
+			return nil, errors.ScimErrorInvalidFilter
+		}
+	}
+
+	value, ok := compareValue.(string)
+	if !ok {
+		value = fmt.Sprintf("%v", compareValue)
+	}
+
+	switch operator {
+	case filter.EQ:
+		return builder.Eq{column: value}, nil
+	case filter.NE:
+		return builder.Neq{column: value}, nil
+	case filter.CO:
+		return builder.Expr(column+" LIKE ? ESCAPE '!'", "%"+util.EscapeLikePattern(value)+"%"), nil
+	case filter.SW:
+		return builder.Expr(column+" LIKE ? ESCAPE '!'", util.EscapeLikePattern(value)+"%"), nil
+	case filter.EW:
+		return builder.Expr(column+" LIKE ? ESCAPE '!'", "%"+util.EscapeLikePattern(value)), nil
+	case filter.GT:
+		return builder.Gt{column: value}, nil
+	case filter.GE:
+		return builder.Gte{column: value}, nil
+	case filter.LT:
+		return builder.Lt{column: value}, nil
+	case filter.LE:
+		return builder.Lte{column: value}, nil
+	default:
+		return nil, errors.ScimErrorInvalidFilter
+	}
+}
+
+// getSortParams returns the column and the order ("ascend" or "descend") from the sortBy and sortOrder
+// query parameters, the column is empty when the request doesn't specify sortBy.
+func getSortParams(r *http.Request, columns map[string]string) (string, string, error) {
+	sortBy := r.URL.Query().Get("sortBy")
+	if sortBy == "" {
+		return "", "", nil
+	}
+
+	path, err := filter.ParseAttrPath([]byte(sortBy))
+	if err != nil {
+		return "", "", errors.ScimErrorBadRequest(fmt.Sprintf("invalid sortBy: %s", sortBy))
+	}
+	column, err := getFilterColumn(path, "", columns)
+	if err != nil {
+		return "", "", err
+	}
+
+	// "ascending" is the default sort order
+	sortOrder := "ascend"
+	if strings.EqualFold(r.URL.Query().Get("sortOrder"), "descending") {
+		sortOrder = "descend"
+	}
+	return column, sortOrder, nil
+}
@@ -0,0 +1,84 @@
+package scim
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scim2/filter-parser/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+)
+
+func args(exp ...interface{}) []interface{} {
+	return exp
+}
+
+func TestScimFilterAsQuery(t *testing.T) {
+	scenarios := []struct {
+		description  string
+		input        string
+		expectedExpr string
+		expectedArgs []interface{}
+	}{
+		{"Should be SQL for eq", `userName eq "alice"`, "name=?", args("alice")},
+		{"Should be SQL for ne", `userType ne "normal-user"`, "type<>?", args("normal-user")},
+		{"Should be SQL for co", `emails co "@example.com"`, "email LIKE ? ESCAPE '!'", args("%@example.com%")},
+		{"Should escape the wildcards for co", `userName co "50%_off!"`, "name LIKE ? ESCAPE '!'", args("%50!%!_off!!%")},
+		{"Should be SQL for sw", `name.givenName sw "Al"`, "first_name LIKE ? ESCAPE '!'", args("Al%")},
+		{"Should be SQL for ew", `emails.value ew "@example.com"`, "email LIKE ? ESCAPE '!'", args("%@example.com")},
+		{"Should be SQL for pr", `externalId pr`, "external_id IS NOT NULL AND external_id<>?", args("")},
+		{"Should be SQL for ge", `meta.lastModified ge "2023-01-01T00:00:00Z"`, "updated_time>=?", args("2023-01-01T00:00:00Z")},
+		{"Should be SQL for and", `userName eq "alice" and externalId eq "1"`, "name=? AND external_id=?", args("alice", "1")},
+		{"Should be SQL for or", `userName eq "alice" or externalId eq "1"`, "name=? OR external_id=?", args("alice", "1")},
+		{"Should be SQL for not", `not (userName eq "alice")`, "NOT name=?", args("alice")},
+		{"Should be SQL for value path", `emails[value ew "@example.com"]`, "email LIKE ? ESCAPE '!'", args("%@example.com")},
+		{"Should be SQL for schema URI", `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, "name=?", args("alice")},
+		{"Should be SQL for extension", `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:organization eq "built-in"`, "owner=?", args("built-in")},
+		{"Should be case-insensitive for attributes", `USERNAME EQ "alice"`, "name=?", args("alice")},
+	}
+
+	for _, scenery := range scenarios {
+		t.Run(scenery.description, func(t *testing.T) {
+			expr, err := filter.ParseFilter([]byte(scenery.input))
+			if err != nil {
+				assert.FailNow(t, "Unable to parse filter", err)
+			}
+
+			cond, err := getFilterCond(expr, userColumns)
+			if err != nil {
+				assert.FailNow(t, "Unable to build condition", err)
+			}
+			sql, args, err := builder.ToSQL(cond)
+			if err != nil {
+				assert.FailNow(t, "Unable to build sql", err)
+			}
+
+			assert.Equal(t, scenery.expectedExpr, sql)
+			assert.Equal(t, scenery.expectedArgs, args)
+		})
+	}
+}
+
+func TestScimFilterUnsupportedAttribute(t *testing.T) {
+	expr, err := filter.ParseFilter([]byte(`password eq "123"`))
+	if err != nil {
+		assert.FailNow(t, "Unable to parse filter", err)
+	}
+
+	_, err = getFilterCond(expr, userColumns)
+	assert.Error(t, err)
+}
+
+func TestScimSortParams(t *testing.T) {
+	r := httptest.NewRequest("GET", "/Users?sortBy=name.familyName&sortOrder=descending", nil)
+	sortField, sortOrder, err := getSortParams(r, userColumns)
+	assert.Nil(t, err)
+	assert.Equal(t, "last_name", sortField)
+	assert.Equal(t, "descend", sortOrder)
+
+	r = httptest.NewRequest("GET", "/Groups?sortBy=displayName", nil)
+	sortField, sortOrder, err = getSortParams(r, groupColumns)
+	assert.Nil(t, err)
+	assert.Equal(t, "display_name", sortField)
+	assert.Equal(t, "ascend", sortOrder)
+}
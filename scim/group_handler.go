@@ -0,0 +1,411 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+	"github.com/elimity-com/scim"
+	"github.com/elimity-com/scim/errors"
+	"github.com/scim2/filter-parser/v2"
+)
+
+type GroupResourceHandler struct{}
+
+// https://datatracker.ietf.org/doc/html/rfc7643#section-4.2 "Group" Resource Schema
+// The SCIM id of a group is its generated ID, as the names of the groups can be the same across organizations.
+
+func (h GroupResourceHandler) Create(r *http.Request, attrs scim.ResourceAttributes) (scim.Resource, error) {
+	resource := &scim.Resource{Attributes: attrs}
+	err := AddScimGroup(resource, getRequestOrganization(r))
+	return *resource, err
+}
+
+func (h GroupResourceHandler) Get(r *http.Request, id string) (scim.Resource, error) {
+	group, err := getRequestGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	resource, err := getScimGroupResource(group)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return *resource, nil
+}
+
+func (h GroupResourceHandler) Delete(r *http.Request, id string) error {
+	group, err := getRequestGroup(r, id)
+	if err != nil {
+		return err
+	}
+
+	// A group with users can't be deleted in Casdoor, so the members are removed first
+	err = setGroupMembers(group, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = object.DeleteGroup(group)
+	return err
+}
+
+func (h GroupResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
+	cond, err := getFilterCond(params.Filter, groupColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	cond = getOrganizationCond(r, cond)
+
+	sortField, sortOrder, err := getSortParams(r, groupColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+
+	groups, err := object.GetGlobalGroupsWithFilter(cond, sortField, sortOrder)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	if params.Count == 0 {
+		return scim.Page{TotalResults: len(groups)}, nil
+	}
+
+	resources := make([]scim.Resource, 0)
+	// startIndex is 1-based index
+	for i := params.StartIndex - 1; i < len(groups) && len(resources) < params.Count; i++ {
+		resource, err := getScimGroupResource(groups[i])
+		if err != nil {
+			return scim.Page{}, err
+		}
+		resources = append(resources, *resource)
+	}
+	return scim.Page{
+		TotalResults: len(groups),
+		Resources:    resources,
+	}, nil
+}
+
+func (h GroupResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
+	group, err := getRequestGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return UpdateScimGroupByPatchOperation(group, operations)
+}
+
+func (h GroupResourceHandler) Replace(r *http.Request, id string, attrs scim.ResourceAttributes) (scim.Resource, error) {
+	group, err := getRequestGroup(r, id)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	resource := &scim.Resource{Attributes: attrs}
+	err = UpdateScimGroup(group, resource, getRequestOrganization(r))
+	return *resource, err
+}
+
+func AddScimGroup(r *scim.Resource, organization string) error {
+	newGroup, memberIds, err := resource2group(r.Attributes, organization)
+	if err != nil {
+		return err
+	}
+
+	newGroup.Name = fmt.Sprintf("group_%s", util.GetRandomName())
+	newGroup.Type = "Virtual"
+	newGroup.ParentId = newGroup.Owner
+	newGroup.IsTopGroup = true
+	newGroup.IsEnabled = true
+
+	affect, err := object.AddGroup(newGroup)
+	if err != nil {
+		return err
+	}
+	if !affect {
+		return fmt.Errorf("add new group failed")
+	}
+
+	err = setGroupMembers(newGroup, memberIds)
+	if err != nil {
+		return err
+	}
+
+	resource, err := getScimGroupResource(newGroup)
+	if err != nil {
+		return err
+	}
+	*r = *resource
+	return nil
+}
+
+func UpdateScimGroup(group *object.Group, r *scim.Resource, organization string) error {
+	newGroup, memberIds, err := resource2group(r.Attributes, organization)
+	if err != nil {
+		return err
+	}
+	if newGroup.Owner != group.Owner {
+		return errors.ScimErrorMutability
+	}
+	err = checkGroupMembers(group, memberIds)
+	if err != nil {
+		return err
+	}
+
+	group.DisplayName = newGroup.DisplayName
+	group.ExternalId = newGroup.ExternalId
+	group.UpdatedTime = util.GetCurrentTime()
+	_, err = object.UpdateGroup(group.GetId(), group)
+	if err != nil {
+		return err
+	}
+
+	err = setGroupMembers(group, memberIds)
+	if err != nil {
+		return err
+	}
+
+	resource, err := getScimGroupResource(group)
+	if err != nil {
+		return err
+	}
+	*r = *resource
+	return nil
+}
+
+// https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2 Modifying with PATCH
+// The operations are applied to a copy of the group and its member IDs, and nothing is saved unless all of
+// them are valid, as the PATCH request must not be partially applied.
+func UpdateScimGroupByPatchOperation(group *object.Group, ops []scim.PatchOperation) (r scim.Resource, err error) {
+	users, err := object.GetGroupUsers(group.GetId())
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	oldMemberIds := []string{}
+	for _, user := range users {
+		oldMemberIds = append(oldMemberIds, user.Id)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid patch op value: %v", r)
+		}
+	}()
+	newGroup := *group
+	memberIds := oldMemberIds
+	for _, op := range ops {
+		if op.Path == nil {
+			// e.g. {"op": "replace", "value": {"displayName": "Group 1", "members": [{"value": "<user id>"}]}}
+			for key, value := range ToAnyMap(op.Value) {
+				memberIds, err = patchScimGroup(&newGroup, memberIds, op.Op, key, nil, value)
+				if err != nil {
+					return scim.Resource{}, err
+				}
+			}
+			continue
+		}
+
+		memberIds, err = patchScimGroup(&newGroup, memberIds, op.Op, op.Path.AttributePath.AttributeName, op.Path.ValueExpression, op.Value)
+		if err != nil {
+			return scim.Resource{}, err
+		}
+	}
+
+	err = checkGroupMembers(group, memberIds)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	newGroup.UpdatedTime = util.GetCurrentTime()
+	_, err = object.UpdateGroup(group.GetId(), &newGroup)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	err = setGroupMembers(&newGroup, memberIds)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+
+	resource, err := getScimGroupResource(&newGroup)
+	if err != nil {
+		return scim.Resource{}, err
+	}
+	return *resource, nil
+}
+
+// patchScimGroup applies the operation to the group and returns the member IDs after the operation,
+// the members are saved by the caller
+func patchScimGroup(group *object.Group, memberIds []string, op string, attr string, valueExpr filter.Expression, value interface{}) ([]string, error) {
+	if op == scim.PatchOperationRemove && attr != "members" {
+		value = nil
+	}
+
+	switch attr {
+	case "displayName":
+		group.DisplayName = ToString(value, "")
+	case "externalId":
+		group.ExternalId = ToString(value, "")
+	case "members":
+		if valueExpr != nil {
+			// e.g. {"op": "remove", "path": "members[value eq \"<user id>\"]"}
+			memberId, err := getMemberIdFromExpression(valueExpr)
+			if err != nil {
+				return nil, err
+			}
+			if op != scim.PatchOperationRemove {
+				return nil, errors.ScimErrorInvalidPath
+			}
+			return util.DeleteVal(memberIds, memberId), nil
+		}
+
+		switch op {
+		case scim.PatchOperationAdd:
+			res := append([]string{}, memberIds...)
+			for _, memberId := range getMemberIds(value) {
+				if !util.InSlice(res, memberId) {
+					res = append(res, memberId)
+				}
+			}
+			return res, nil
+		case scim.PatchOperationReplace:
+			return getMemberIds(value), nil
+		case scim.PatchOperationRemove:
+			if value == nil {
+				return []string{}, nil
+			}
+			res := memberIds
+			for _, memberId := range getMemberIds(value) {
+				res = util.DeleteVal(res, memberId)
+			}
+			return res, nil
+		}
+	}
+	return memberIds, nil
+}
+
+func getScimGroupResource(group *object.Group) (*scim.Resource, error) {
+	users, err := object.GetGroupUsers(group.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return group2resource(group, users), nil
+}
+
+func getMemberIdFromExpression(expr filter.Expression) (string, error) {
+	e, ok := expr.(*filter.AttributeExpression)
+	if !ok || e.Operator != filter.EQ || e.AttributePath.AttributeName != "value" {
+		return "", errors.ScimErrorInvalidFilter
+	}
+	memberId, ok := e.CompareValue.(string)
+	if !ok {
+		return "", errors.ScimErrorInvalidFilter
+	}
+	return memberId, nil
+}
+
+func getGroupMember(group *object.Group, memberId string) (*object.User, error) {
+	user, err := object.GetUserByUserIdOnly(memberId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ScimErrorBadRequest(fmt.Sprintf("the member: %s is not found", memberId))
+	}
+	if user.Owner != group.Owner {
+		return nil, errors.ScimErrorBadRequest(fmt.Sprintf("the member: %s doesn't belong to the organization: %s", memberId, group.Owner))
+	}
+	return user, nil
+}
+
+// checkGroupMembers checks that the members can be added to the group before the group is changed
+func checkGroupMembers(group *object.Group, memberIds []string) error {
+	for _, memberId := range memberIds {
+		_, err := getGroupMember(group, memberId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addGroupMembers(group *object.Group, memberIds []string) error {
+	groupId := group.GetId()
+	for _, memberId := range memberIds {
+		user, err := getGroupMember(group, memberId)
+		if err != nil {
+			return err
+		}
+		if util.InSlice(user.Groups, groupId) {
+			continue
+		}
+
+		user.Groups = append(user.Groups, groupId)
+		_, err = object.UpdateUser(user.GetId(), user, []string{"groups"}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeGroupMembers(group *object.Group, memberIds []string) error {
+	groupId := group.GetId()
+	for _, memberId := range memberIds {
+		user, err := object.GetUserByUserIdOnly(memberId)
+		if err != nil {
+			return err
+		}
+		if user == nil || !util.InSlice(user.Groups, groupId) {
+			continue
+		}
+
+		user.Groups = util.DeleteVal(user.Groups, groupId)
+		_, err = object.UpdateUser(user.GetId(), user, []string{"groups"}, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setGroupMembers(group *object.Group, memberIds []string) error {
+	users, err := object.GetGroupUsers(group.GetId())
+	if err != nil {
+		return err
+	}
+
+	oldMemberIds := []string{}
+	removedMemberIds := []string{}
+	for _, user := range users {
+		oldMemberIds = append(oldMemberIds, user.Id)
+		if !util.InSlice(memberIds, user.Id) {
+			removedMemberIds = append(removedMemberIds, user.Id)
+		}
+	}
+
+	addedMemberIds := []string{}
+	for _, memberId := range memberIds {
+		if !util.InSlice(oldMemberIds, memberId) {
+			addedMemberIds = append(addedMemberIds, memberId)
+		}
+	}
+
+	err = removeGroupMembers(group, removedMemberIds)
+	if err != nil {
+		return err
+	}
+	return addGroupMembers(group, addedMemberIds)
+}
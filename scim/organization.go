@@ -0,0 +1,99 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/elimity-com/scim/errors"
+	"github.com/xorm-io/builder"
+)
+
+type organizationContextKey struct{}
+
+// WithOrganization scopes the SCIM request to the organization inferred from its credentials, the
+// resources of the other organizations are not found and the organization in the payload isn't trusted.
+// The requests of the global admins aren't scoped, so the organization in the payload is used.
+func WithOrganization(r *http.Request, organization string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), organizationContextKey{}, organization))
+}
+
+func getRequestOrganization(r *http.Request) string {
+	organization, _ := r.Context().Value(organizationContextKey{}).(string)
+	return organization
+}
+
+func isOrganizationAllowed(r *http.Request, owner string) bool {
+	organization := getRequestOrganization(r)
+	return organization == "" || organization == owner
+}
+
+// getOrganizationCond adds the organization of the request to the filter condition
+func getOrganizationCond(r *http.Request, cond builder.Cond) builder.Cond {
+	organization := getRequestOrganization(r)
+	if organization == "" {
+		return cond
+	}
+	return builder.And(cond, builder.Eq{"owner": organization})
+}
+
+// checkOrganization returns the organization of the resource, which is the organization that the request
+// is scoped to, the resource can't be created in or moved to another organization
+func checkOrganization(organization string, owner string, extensionKey string) (string, error) {
+	if organization == "" {
+		if owner == "" {
+			return "", errors.ScimErrorBadRequest(fmt.Sprintf("organization in %s is required", extensionKey))
+		}
+		return owner, nil
+	}
+
+	if owner != "" && owner != organization {
+		return "", errors.ScimErrorBadRequest(fmt.Sprintf("the organization: %s doesn't match the organization of the token: %s", owner, organization))
+	}
+	return organization, nil
+}
+
+// getRequestUser returns the user with the SCIM id, the users of the other organizations are not found
+func getRequestUser(r *http.Request, id string) (*object.User, error) {
+	user, err := object.GetUserByUserIdOnly(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !isOrganizationAllowed(r, user.Owner) {
+		return nil, errors.ScimErrorResourceNotFound(id)
+	}
+	return user, nil
+}
+
+// getRequestGroup returns the group with the SCIM id, the groups of the other organizations are not found
+func getRequestGroup(r *http.Request, id string) (*object.Group, error) {
+	group, err := object.GetGroupById(getRequestOrganization(r), id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, errors.ScimErrorResourceNotFound(id)
+	}
+	return group, nil
+}
+
+// HandleUnauthorized rejects the SCIM request without valid credentials
+func HandleUnauthorized(w http.ResponseWriter, detail string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeScimError(w, errors.ScimError{Status: http.StatusUnauthorized, Detail: detail})
+}
@@ -0,0 +1,43 @@
+package scim
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+)
+
+func TestScimCheckOrganization(t *testing.T) {
+	owner, err := checkOrganization("org1", "", UserExtensionKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "org1", owner)
+
+	owner, err = checkOrganization("org1", "org1", UserExtensionKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "org1", owner)
+
+	_, err = checkOrganization("org1", "org2", UserExtensionKey)
+	assert.Error(t, err)
+
+	owner, err = checkOrganization("", "org2", UserExtensionKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "org2", owner)
+
+	_, err = checkOrganization("", "", UserExtensionKey)
+	assert.Error(t, err)
+}
+
+func TestScimOrganizationCond(t *testing.T) {
+	r := WithOrganization(httptest.NewRequest("GET", "/Users", nil), "org1")
+	assert.True(t, isOrganizationAllowed(r, "org1"))
+	assert.False(t, isOrganizationAllowed(r, "org2"))
+
+	sql, args, err := builder.ToSQL(getOrganizationCond(r, builder.Eq{"name": "alice"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "name=? AND owner=?", sql)
+	assert.Equal(t, []interface{}{"alice", "org1"}, args)
+
+	r = WithOrganization(httptest.NewRequest("GET", "/Users", nil), "")
+	assert.True(t, isOrganizationAllowed(r, "org2"))
+}
@@ -15,6 +15,10 @@
 package scim
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
 	"github.com/elimity-com/scim"
 	"github.com/elimity-com/scim/optional"
 	"github.com/elimity-com/scim/schema"
@@ -64,7 +68,10 @@ Example JSON user resource
 */
 
 const (
-	UserExtensionKey = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	ScimPathPrefix = "/scim"
+
+	UserExtensionKey  = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	GroupExtensionKey = "urn:ietf:params:scim:schemas:extension:casdoor:2.0:Group"
 )
 
 var (
@@ -102,7 +109,8 @@ var (
 func GetScimServer() scim.Server {
 	config := scim.ServiceProviderConfig{
 		// DocumentationURI: optional.NewString("www.example.com/scim"),
-		SupportPatch: true,
+		SupportFiltering: true,
+		SupportPatch:     true,
 	}
 
 	codeAttrs := make([]schema.CoreAttribute, 0, len(UserStringField)+len(UserComplexField))
@@ -132,6 +140,21 @@ func GetScimServer() scim.Server {
 		},
 	}
 
+	groupSchema := schema.CoreGroupSchema()
+	groupSchema.Attributes = append(groupSchema.Attributes, schema.SimpleCoreAttribute(newStringParams("externalId", false, true)))
+
+	groupExtension := schema.Schema{
+		ID:          GroupExtensionKey,
+		Name:        optional.NewString("CasdoorGroup"),
+		Description: optional.NewString("Casdoor Group"),
+		Attributes: []schema.CoreAttribute{
+			schema.SimpleCoreAttribute(schema.SimpleStringParams(schema.StringParams{
+				Name:     "organization",
+				Required: true,
+			})),
+		},
+	}
+
 	resourceTypes := []scim.ResourceType{
 		{
 			ID:          optional.NewString("User"),
@@ -144,6 +167,17 @@ func GetScimServer() scim.Server {
 			},
 			Handler: UserResourceHandler{},
 		},
+		{
+			ID:          optional.NewString("Group"),
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: optional.NewString("Group in Casdoor"),
+			Schema:      groupSchema,
+			SchemaExtensions: []scim.SchemaExtension{
+				{Schema: groupExtension},
+			},
+			Handler: GroupResourceHandler{},
+		},
 	}
 
 	server := scim.Server{
@@ -152,3 +186,48 @@ func GetScimServer() scim.Server {
 	}
 	return server
 }
+
+// HandleRequest serves the SCIM endpoints, the Bulk endpoint isn't implemented by the SCIM server so it is
+// handled here and advertised in the service provider config together with sorting.
+func HandleRequest(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/Bulk":
+		handleBulk(w, r)
+	case "/ServiceProviderConfig":
+		handleServiceProviderConfig(w, r)
+	default:
+		Server.ServeHTTP(w, r)
+	}
+}
+
+func handleServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	recorder := httptest.NewRecorder()
+	Server.ServeHTTP(recorder, r)
+
+	config := map[string]interface{}{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &config)
+	if err != nil || recorder.Code != http.StatusOK {
+		w.Header().Set("Content-Type", "application/scim+json")
+		w.WriteHeader(recorder.Code)
+		_, _ = w.Write(recorder.Body.Bytes())
+		return
+	}
+
+	config["bulk"] = map[string]interface{}{
+		"supported":      true,
+		"maxOperations":  BulkMaxOperations,
+		"maxPayloadSize": BulkMaxPayloadSize,
+	}
+	config["sort"] = map[string]interface{}{
+		"supported": true,
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
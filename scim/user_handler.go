@@ -30,45 +30,50 @@ type UserResourceHandler struct{}
 
 func (h UserResourceHandler) Create(r *http.Request, attrs scim.ResourceAttributes) (scim.Resource, error) {
 	resource := &scim.Resource{Attributes: attrs}
-	err := AddScimUser(resource)
+	err := AddScimUser(resource, getRequestOrganization(r))
 	return *resource, err
 }
 
 func (h UserResourceHandler) Get(r *http.Request, id string) (scim.Resource, error) {
-	resource, err := GetScimUser(id)
+	user, err := getRequestUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
-	if resource == nil {
-		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
-	}
-	return *resource, nil
+	return *user2resource(user), nil
 }
 
 func (h UserResourceHandler) Delete(r *http.Request, id string) error {
-	user, err := object.GetUserByUserIdOnly(id)
+	user, err := getRequestUser(r, id)
 	if err != nil {
 		return err
 	}
-	if user == nil {
-		return errors.ScimErrorResourceNotFound(id)
-	}
 	_, err = object.DeleteUser(user)
 	return err
 }
 
 func (h UserResourceHandler) GetAll(r *http.Request, params scim.ListRequestParams) (scim.Page, error) {
+	cond, err := getFilterCond(params.Filter, userColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+	cond = getOrganizationCond(r, cond)
+
+	count, err := object.GetGlobalUserCountWithFilter(cond)
+	if err != nil {
+		return scim.Page{}, err
+	}
 	if params.Count == 0 {
-		count, err := object.GetGlobalUserCount("", "")
-		if err != nil {
-			return scim.Page{}, err
-		}
 		return scim.Page{TotalResults: int(count)}, nil
 	}
 
+	sortField, sortOrder, err := getSortParams(r, userColumns)
+	if err != nil {
+		return scim.Page{}, err
+	}
+
 	resources := make([]scim.Resource, 0)
 	// startIndex is 1-based index
-	users, err := object.GetPaginationGlobalUsers(params.StartIndex-1, params.Count, "", "", "", "")
+	users, err := object.GetPaginationGlobalUsersWithFilter(params.StartIndex-1, params.Count, cond, sortField, sortOrder)
 	if err != nil {
 		return scim.Page{}, err
 	}
@@ -76,32 +81,26 @@ func (h UserResourceHandler) GetAll(r *http.Request, params scim.ListRequestPara
 		resources = append(resources, *user2resource(user))
 	}
 	return scim.Page{
-		TotalResults: len(resources),
+		TotalResults: int(count),
 		Resources:    resources,
 	}, nil
 }
 
 func (h UserResourceHandler) Patch(r *http.Request, id string, operations []scim.PatchOperation) (scim.Resource, error) {
-	user, err := object.GetUserByUserIdOnly(id)
+	_, err := getRequestUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
-	if user == nil {
-		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
-	}
-	return UpdateScimUserByPatchOperation(id, operations)
+	return UpdateScimUserByPatchOperation(id, operations, getRequestOrganization(r))
 }
 
 func (h UserResourceHandler) Replace(r *http.Request, id string, attrs scim.ResourceAttributes) (scim.Resource, error) {
-	user, err := object.GetUserByUserIdOnly(id)
+	_, err := getRequestUser(r, id)
 	if err != nil {
 		return scim.Resource{}, err
 	}
-	if user == nil {
-		return scim.Resource{}, errors.ScimErrorResourceNotFound(id)
-	}
 	resource := &scim.Resource{Attributes: attrs}
-	err = UpdateScimUser(id, resource)
+	err = UpdateScimUser(id, resource, getRequestOrganization(r))
 	return *resource, err
 }
 
@@ -117,8 +116,8 @@ func GetScimUser(id string) (*scim.Resource, error) {
 	return r, nil
 }
 
-func AddScimUser(r *scim.Resource) error {
-	newUser, err := resource2user(r.Attributes)
+func AddScimUser(r *scim.Resource, organization string) error {
+	newUser, err := resource2user(r.Attributes, organization)
 	if err != nil {
 		return err
 	}
@@ -147,7 +146,7 @@ func AddScimUser(r *scim.Resource) error {
 	return nil
 }
 
-func UpdateScimUser(id string, r *scim.Resource) error {
+func UpdateScimUser(id string, r *scim.Resource, organization string) error {
 	oldUser, err := object.GetUserByUserIdOnly(id)
 	if err != nil {
 		return err
@@ -155,10 +154,12 @@ func UpdateScimUser(id string, r *scim.Resource) error {
 	if oldUser == nil {
 		return errors.ScimErrorResourceNotFound(id)
 	}
-	newUser, err := resource2user(r.Attributes)
+	newUser, err := resource2user(r.Attributes, organization)
 	if err != nil {
 		return err
 	}
+	// Group memberships are managed by the Groups resource
+	newUser.Groups = oldUser.Groups
 	_, err = object.UpdateUser(oldUser.GetId(), newUser, nil, true)
 	if err != nil {
 		return err
@@ -171,7 +172,7 @@ func UpdateScimUser(id string, r *scim.Resource) error {
 }
 
 // https://datatracker.ietf.org/doc/html/rfc7644#section-3.5.2 Modifying with PATCH
-func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation) (r scim.Resource, err error) {
+func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation, organization string) (r scim.Resource, err error) {
 	user, err := object.GetUserByUserIdOnly(id)
 	if err != nil {
 		return scim.Resource{}, err
@@ -251,6 +252,10 @@ func UpdateScimUserByPatchOperation(id string, ops []scim.PatchOperation) (r sci
 			user.Owner = ToString(value, user.Owner)
 		}
 	}
+	user.Owner, err = checkOrganization(organization, user.Owner, UserExtensionKey)
+	if err != nil {
+		return scim.Resource{}, err
+	}
 	_, err = object.UpdateUser(old, user, nil, true)
 	if err != nil {
 		return scim.Resource{}, err
@@ -93,10 +93,26 @@ func buildExternalId(user *object.User) optional.String {
 	}
 }
 
+func buildGroupExternalId(group *object.Group) optional.String {
+	if group.ExternalId != "" {
+		return optional.NewString(group.ExternalId)
+	} else {
+		return optional.String{}
+	}
+}
+
 func buildMeta(user *object.User) scim.Meta {
-	createdTime := util.String2Time(user.CreatedTime)
-	updatedTime := util.String2Time(user.UpdatedTime)
-	if user.UpdatedTime == "" {
+	return newMeta(user.CreatedTime, user.UpdatedTime)
+}
+
+func buildGroupMeta(group *object.Group) scim.Meta {
+	return newMeta(group.CreatedTime, group.UpdatedTime)
+}
+
+func newMeta(createdTimeString string, updatedTimeString string) scim.Meta {
+	createdTime := util.String2Time(createdTimeString)
+	updatedTime := util.String2Time(updatedTimeString)
+	if updatedTimeString == "" {
 		updatedTime = createdTime
 	}
 	return scim.Meta{
@@ -202,7 +218,7 @@ func user2resource(user *object.User) *scim.Resource {
 	}
 }
 
-func resource2user(attrs scim.ResourceAttributes) (user *object.User, err error) {
+func resource2user(attrs scim.ResourceAttributes, organization string) (user *object.User, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("failed to parse attrs: %v", r)
@@ -231,8 +247,63 @@ func resource2user(attrs scim.ResourceAttributes) (user *object.User, err error)
 		UpdatedTime: util.GetCurrentTime(),
 	}
 
-	if user.Owner == "" {
-		err = fmt.Errorf("organization in %s is required", UserExtensionKey)
+	user.Owner, err = checkOrganization(organization, user.Owner, UserExtensionKey)
+	return
+}
+
+func group2resource(group *object.Group, users []*object.User) *scim.Resource {
+	members := []scim.ResourceAttributes{}
+	for _, user := range users {
+		members = append(members, scim.ResourceAttributes{
+			"value":   user.Id,
+			"display": user.Name,
+		})
+	}
+
+	attrs := make(map[string]interface{})
+	attrs["displayName"] = group.DisplayName
+	attrs["members"] = members
+	attrs[GroupExtensionKey] = scim.ResourceAttributes{
+		"organization": group.Owner,
+	}
+
+	return &scim.Resource{
+		ID:         group.Id,
+		ExternalID: buildGroupExternalId(group),
+		Attributes: attrs,
+		Meta:       buildGroupMeta(group),
 	}
+}
+
+func resource2group(attrs scim.ResourceAttributes, organization string) (group *object.Group, memberIds []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("failed to parse attrs: %v", r)
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	group = &object.Group{
+		Owner:       getAttrJsonValue(attrs, GroupExtensionKey, "organization"),
+		DisplayName: getAttrString(attrs, "displayName"),
+		ExternalId:  getAttrString(attrs, "externalId"),
+
+		CreatedTime: util.GetCurrentTime(),
+		UpdatedTime: util.GetCurrentTime(),
+	}
+	memberIds = getMemberIds(attrs["members"])
+
+	group.Owner, err = checkOrganization(organization, group.Owner, GroupExtensionKey)
 	return
 }
+
+// getMemberIds returns the user ids in a multi-valued "members" attribute, e.g. [{"value": "<user id>"}]
+func getMemberIds(v interface{}) []string {
+	memberIds := []string{}
+	if v == nil {
+		return memberIds
+	}
+	for _, member := range ToAnyArray(v) {
+		memberIds = append(memberIds, ToString(ToAnyMap(member)["value"]))
+	}
+	return memberIds
+}
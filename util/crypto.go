@@ -19,7 +19,11 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
 )
 
 func GetHmacSha1(keyStr, value string) string {
@@ -37,3 +41,21 @@ func GetHmacSha256(key string, data string) string {
 
 	return hex.EncodeToString(mac.Sum(nil))
 }
+
+func GetSha256Hash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetNtPasswordHash returns the NT hash of the password, which is the MD4 of the UTF-16LE password, see RFC 2759 section 8.3
+func GetNtPasswordHash(password string) []byte {
+	codes := utf16.Encode([]rune(password))
+	b := make([]byte, len(codes)*2)
+	for i, code := range codes {
+		binary.LittleEndian.PutUint16(b[i*2:], code)
+	}
+
+	hash := md4.New()
+	hash.Write(b)
+	return hash.Sum(nil)
+}
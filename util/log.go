@@ -16,6 +16,7 @@ package util
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
@@ -61,6 +62,38 @@ func GetIPFromRequest(req *http.Request) string {
 	return GetIPInfo(clientIP)
 }
 
+// GetClientIpFromRequest returns the IP of the client without the port. The x-forwarded-for header is only
+// honored if the request comes from a trusted proxy, and the rightmost hop that isn't a trusted proxy is the
+// client, as the hops on the left of it can be forged by the client.
+func GetClientIpFromRequest(req *http.Request, trustedProxies string) string {
+	clientIp, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		clientIp = req.RemoteAddr
+	}
+	if !IsIpInList(clientIp, trustedProxies) {
+		return clientIp
+	}
+
+	forwardedFor := req.Header.Get("x-forwarded-for")
+	if forwardedFor == "" {
+		return clientIp
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		clientIp = hop
+		if !IsIpInList(hop, trustedProxies) {
+			break
+		}
+	}
+	return clientIp
+}
+
 func LogInfo(ctx *context.Context, f string, v ...interface{}) {
 	ipString := fmt.Sprintf("(%s) ", GetIPFromRequest(ctx.Request))
 	logs.Info(ipString+f, v...)
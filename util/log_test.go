@@ -0,0 +1,48 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClientIpFromRequest(t *testing.T) {
+	trustedProxies := "10.0.0.0/8"
+
+	scenarios := []struct {
+		description  string
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{"Should ignore the header from an untrusted peer", "192.0.2.1:1234", "198.51.100.1", "192.0.2.1"},
+		{"Should use the peer without the header", "10.0.0.1:1234", "", "10.0.0.1"},
+		{"Should use the hop added by the trusted proxy", "10.0.0.1:1234", "198.51.100.1", "198.51.100.1"},
+		{"Should skip the trusted proxies from the right", "10.0.0.1:1234", "198.51.100.1, 10.0.0.2", "198.51.100.1"},
+		{"Should ignore the hops forged by the client", "10.0.0.1:1234", "203.0.113.7, 198.51.100.1", "198.51.100.1"},
+		{"Should stop at a malformed hop", "10.0.0.1:1234", "198.51.100.1, unknown, 10.0.0.2", "10.0.0.2"},
+	}
+
+	for _, scenario := range scenarios {
+		req := &http.Request{RemoteAddr: scenario.remoteAddr, Header: http.Header{}}
+		if scenario.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", scenario.forwardedFor)
+		}
+		assert.Equal(t, scenario.expected, GetClientIpFromRequest(req, trustedProxies), scenario.description)
+	}
+}
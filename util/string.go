@@ -271,6 +271,14 @@ func maskString(str string) string {
 	}
 }
 
+// EscapeLikePattern escapes the wildcards of the LIKE pattern with "!", which must be used with "ESCAPE '!'"
+// as the databases don't agree on the default escape character
+func EscapeLikePattern(value string) string {
+	return likePatternReplacer.Replace(value)
+}
+
+var likePatternReplacer = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 // GetEndPoint remove scheme from url
 func GetEndPoint(endpoint string) string {
 	for _, prefix := range []string{"https://", "http://"} {
@@ -16,8 +16,10 @@ package util
 
 import (
 	"fmt"
+	"net"
 	"net/mail"
 	"regexp"
+	"strings"
 
 	"github.com/nyaruka/phonenumbers"
 )
@@ -41,6 +43,31 @@ func IsEmailValid(email string) bool {
 	return err == nil
 }
 
+// IsIpInList returns whether the IP matches an IP or a CIDR of the comma-separated list
+func IsIpInList(ip string, list string) bool {
+	ipObj := net.ParseIP(ip)
+	if ipObj == nil {
+		return false
+	}
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if strings.Contains(item, "/") {
+			_, ipNet, err := net.ParseCIDR(item)
+			if err == nil && ipNet.Contains(ipObj) {
+				return true
+			}
+		} else if listedIp := net.ParseIP(item); listedIp != nil && listedIp.Equal(ipObj) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsPhoneValid(phone string, countryCode string) bool {
 	phoneNumber, err := phonenumbers.Parse(phone, countryCode)
 	if err != nil {
@@ -0,0 +1,41 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIpInList(t *testing.T) {
+	list := "203.0.113.7, 198.51.100.0/24,2001:db8::/32"
+
+	scenarios := []struct {
+		ip       string
+		expected bool
+	}{
+		{"203.0.113.7", true},
+		{"203.0.113.8", false},
+		{"198.51.100.42", true},
+		{"2001:db8::1", true},
+		{"192.0.2.1", false},
+		{"", false},
+	}
+
+	for _, scenario := range scenarios {
+		assert.Equal(t, scenario.expected, IsIpInList(scenario.ip, list), "The IP is %s", scenario.ip)
+	}
+}
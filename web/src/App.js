@@ -46,6 +46,10 @@ import TokenListPage from "./TokenListPage";
 import TokenEditPage from "./TokenEditPage";
 import WebhookListPage from "./WebhookListPage";
 import WebhookEditPage from "./WebhookEditPage";
+import RadiusClientListPage from "./RadiusClientListPage";
+import RadiusClientEditPage from "./RadiusClientEditPage";
+import RadiusPolicyListPage from "./RadiusPolicyListPage";
+import RadiusPolicyEditPage from "./RadiusPolicyEditPage";
 import SyncerListPage from "./SyncerListPage";
 import SyncerEditPage from "./SyncerEditPage";
 import CertListPage from "./CertListPage";
@@ -67,6 +71,7 @@ import ModelEditPage from "./ModelEditPage";
 import AdapterListPage from "./AdapterListPage";
 import AdapterEditPage from "./AdapterEditPage";
 import SessionListPage from "./SessionListPage";
+import RecordListPage from "./RecordListPage";
 import MfaSetupPage from "./auth/MfaSetupPage";
 import SystemInfo from "./SystemInfo";
 import AccountPage from "./account/AccountPage";
@@ -163,7 +168,7 @@ class App extends Component {
       this.setState({selectedMenuKey: "/logs"});
     } else if (uri.includes("/products") || uri.includes("/payments") || uri.includes("/plans") || uri.includes("/pricings") || uri.includes("/subscriptions")) {
       this.setState({selectedMenuKey: "/business"});
-    } else if (uri.includes("/sysinfo") || uri.includes("/syncers") || uri.includes("/webhooks")) {
+    } else if (uri.includes("/sysinfo") || uri.includes("/syncers") || uri.includes("/webhooks") || uri.includes("/radius-clients") || uri.includes("/radius-policies")) {
       this.setState({selectedMenuKey: "/admin"});
     } else if (uri.includes("/signup")) {
       this.setState({selectedMenuKey: "/signup"});
@@ -459,7 +464,7 @@ class App extends Component {
 
       res.push(Setting.getItem(<Link style={{color: "black"}} to="/sessions">{i18next.t("general:Logging & Auditing")}</Link>, "/logs", <WalletTwoTone />, [
         Setting.getItem(<Link to="/sessions">{i18next.t("general:Sessions")}</Link>, "/sessions"),
-        Setting.getItem(<a target="_blank" rel="noreferrer" href={Conf.CasvisorUrl}>{i18next.t("general:Records")}</a>, "/records"),
+        Setting.getItem(<Link to="/records">{i18next.t("general:Records")}</Link>, "/records"),
         Setting.getItem(<Link to="/tokens">{i18next.t("general:Tokens")}</Link>, "/tokens"),
       ]));
 
@@ -476,11 +481,15 @@ class App extends Component {
           Setting.getItem(<Link to="/sysinfo">{i18next.t("general:System Info")}</Link>, "/sysinfo"),
           Setting.getItem(<Link to="/syncers">{i18next.t("general:Syncers")}</Link>, "/syncers"),
           Setting.getItem(<Link to="/webhooks">{i18next.t("general:Webhooks")}</Link>, "/webhooks"),
+          Setting.getItem(<Link to="/radius-clients">{i18next.t("general:RADIUS Clients")}</Link>, "/radius-clients"),
+          Setting.getItem(<Link to="/radius-policies">{i18next.t("general:RADIUS Policies")}</Link>, "/radius-policies"),
           Setting.getItem(<a target="_blank" rel="noreferrer" href={Setting.isLocalhost() ? `${Setting.ServerUrl}/swagger` : "/swagger"}>{i18next.t("general:Swagger")}</a>, "/swagger")]));
       } else {
         res.push(Setting.getItem(<Link style={{color: "black"}} to="/syncers">{i18next.t("general:Admin")}</Link>, "/admin", <SettingTwoTone />, [
           Setting.getItem(<Link to="/syncers">{i18next.t("general:Syncers")}</Link>, "/syncers"),
-          Setting.getItem(<Link to="/webhooks">{i18next.t("general:Webhooks")}</Link>, "/webhooks")]));
+          Setting.getItem(<Link to="/webhooks">{i18next.t("general:Webhooks")}</Link>, "/webhooks"),
+          Setting.getItem(<Link to="/radius-clients">{i18next.t("general:RADIUS Clients")}</Link>, "/radius-clients"),
+          Setting.getItem(<Link to="/radius-policies">{i18next.t("general:RADIUS Policies")}</Link>, "/radius-policies")]));
       }
     }
 
@@ -534,9 +543,14 @@ class App extends Component {
         <Route exact path="/ldap/sync/:organizationName/:ldapId" render={(props) => this.renderLoginIfNotLoggedIn(<LdapSyncPage account={this.state.account} {...props} />)} />
         <Route exact path="/tokens" render={(props) => this.renderLoginIfNotLoggedIn(<TokenListPage account={this.state.account} {...props} />)} />
         <Route exact path="/sessions" render={(props) => this.renderLoginIfNotLoggedIn(<SessionListPage account={this.state.account} {...props} />)} />
+        <Route exact path="/records" render={(props) => this.renderLoginIfNotLoggedIn(<RecordListPage account={this.state.account} {...props} />)} />
         <Route exact path="/tokens/:tokenName" render={(props) => this.renderLoginIfNotLoggedIn(<TokenEditPage account={this.state.account} {...props} />)} />
         <Route exact path="/webhooks" render={(props) => this.renderLoginIfNotLoggedIn(<WebhookListPage account={this.state.account} {...props} />)} />
         <Route exact path="/webhooks/:webhookName" render={(props) => this.renderLoginIfNotLoggedIn(<WebhookEditPage account={this.state.account} {...props} />)} />
+        <Route exact path="/radius-clients" render={(props) => this.renderLoginIfNotLoggedIn(<RadiusClientListPage account={this.state.account} {...props} />)} />
+        <Route exact path="/radius-clients/:organizationName/:radiusClientName" render={(props) => this.renderLoginIfNotLoggedIn(<RadiusClientEditPage account={this.state.account} {...props} />)} />
+        <Route exact path="/radius-policies" render={(props) => this.renderLoginIfNotLoggedIn(<RadiusPolicyListPage account={this.state.account} {...props} />)} />
+        <Route exact path="/radius-policies/:organizationName/:radiusPolicyName" render={(props) => this.renderLoginIfNotLoggedIn(<RadiusPolicyEditPage account={this.state.account} {...props} />)} />
         <Route exact path="/syncers" render={(props) => this.renderLoginIfNotLoggedIn(<SyncerListPage account={this.state.account} {...props} />)} />
         <Route exact path="/syncers/:syncerName" render={(props) => this.renderLoginIfNotLoggedIn(<SyncerEditPage account={this.state.account} {...props} />)} />
         <Route exact path="/certs" render={(props) => this.renderLoginIfNotLoggedIn(<CertListPage account={this.state.account} {...props} />)} />
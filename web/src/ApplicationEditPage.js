@@ -635,11 +635,152 @@ class ApplicationEditPage extends React.Component {
                   {id: "token", name: "Token"},
                   {id: "id_token", name: "ID Token"},
                   {id: "refresh_token", name: "Refresh Token"},
+                  {id: "urn:ietf:params:oauth:grant-type:device_code", name: "Device Code"},
+                  {id: "urn:ietf:params:oauth:grant-type:token-exchange", name: "Token Exchange"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Token exchange audiences"), i18next.t("application:Token exchange audiences - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: "100%"}}
+              value={this.state.application.tokenExchangeAudiences}
+              onChange={(value => {
+                this.updateApplicationField("tokenExchangeAudiences", value);
+              })} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Front-channel logout URL"), i18next.t("application:Front-channel logout URL - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined />} value={this.state.application.frontchannelLogoutUri} onChange={e => {
+              this.updateApplicationField("frontchannelLogoutUri", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Back-channel logout URL"), i18next.t("application:Back-channel logout URL - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined />} value={this.state.application.backchannelLogoutUri} onChange={e => {
+              this.updateApplicationField("backchannelLogoutUri", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Require PAR"), i18next.t("application:Require PAR - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.requirePushedAuthorizationRequests} onChange={checked => {
+              this.updateApplicationField("requirePushedAuthorizationRequests", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Client JWKS URL"), i18next.t("application:Client JWKS URL - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined />} value={this.state.application.clientJwksUri} onChange={e => {
+              this.updateApplicationField("clientJwksUri", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Client JWKS"), i18next.t("application:Client JWKS - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input.TextArea autoSize={{minRows: 3, maxRows: 10}} value={this.state.application.clientJwks} onChange={e => {
+              this.updateApplicationField("clientJwks", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Token endpoint auth method"), i18next.t("application:Token endpoint auth method - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.application.tokenEndpointAuthMethod === "" ? "client_secret_basic" : this.state.application.tokenEndpointAuthMethod} onChange={(value => {
+              this.updateApplicationField("tokenEndpointAuthMethod", value);
+            })}>
+              {
+                [
+                  {id: "client_secret_basic", name: "client_secret_basic"},
+                  {id: "client_secret_post", name: "client_secret_post"},
+                  {id: "private_key_jwt", name: "private_key_jwt"},
+                  {id: "tls_client_auth", name: "tls_client_auth"},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        {
+          this.state.application.tokenEndpointAuthMethod !== "tls_client_auth" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("application:TLS client auth subject DN"), i18next.t("application:TLS client auth subject DN - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Input value={this.state.application.tlsClientAuthSubjectDn} onChange={e => {
+                  this.updateApplicationField("tlsClientAuthSubjectDn", e.target.value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Enable DPoP"), i18next.t("application:Enable DPoP - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.enableDpop} onChange={checked => {
+              this.updateApplicationField("enableDpop", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Require DPoP"), i18next.t("application:Require DPoP - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.requireDpop} onChange={checked => {
+              this.updateApplicationField("requireDpop", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Enable SCIM"), i18next.t("application:Enable SCIM - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.enableScim} onChange={checked => {
+              this.updateApplicationField("enableScim", checked);
+            }} />
+          </Col>
+        </Row>
+        {
+          !this.state.application.enableScim ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("application:SCIM token"), i18next.t("application:SCIM token - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Input value={this.state.application.scimToken} placeholder={i18next.t("application:Generated after saving")} onChange={e => {
+                  this.updateApplicationField("scimToken", e.target.value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:SAML reply URL"), i18next.t("application:Redirect URL (Assertion Consumer Service POST Binding URL) - Tooltip"))} :
@@ -13,7 +13,7 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, InputNumber, Row, Select} from "antd";
+import {Button, Card, Col, Input, InputNumber, Row, Select, Switch} from "antd";
 import * as CertBackend from "./backend/CertBackend";
 import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as Setting from "./Setting";
@@ -171,7 +171,7 @@ class CertEditPage extends React.Component {
           <Col span={22} >
             <Select virtual={false} style={{width: "100%"}} value={this.state.cert.cryptoAlgorithm} onChange={(value => {
               this.updateCertField("cryptoAlgorithm", value);
-              if (value === "RS256") {
+              if (value.startsWith("RS") || value.startsWith("PS")) {
                 this.updateCertField("bitSize", 2048);
               } else if (value === "HS256" || value === "ES256") {
                 this.updateCertField("bitSize", 256);
@@ -188,10 +188,16 @@ class CertEditPage extends React.Component {
               {
                 [
                   {id: "RS256", name: "RS256 (RSA + SHA256)"},
+                  {id: "RS384", name: "RS384 (RSA + SHA384)"},
+                  {id: "RS512", name: "RS512 (RSA + SHA512)"},
+                  {id: "PS256", name: "PS256 (RSASSA-PSS + SHA256)"},
+                  {id: "PS384", name: "PS384 (RSASSA-PSS + SHA384)"},
+                  {id: "PS512", name: "PS512 (RSASSA-PSS + SHA512)"},
                   {id: "HS256", name: "HS256 (HMAC + SHA256)"},
                   {id: "ES256", name: "ES256 (ECDSA using P-256 + SHA256)"},
-                  {id: "ES384", name: "ES384 (ECDSA using P-384 + SHA256)"},
-                  {id: "ES521", name: "ES521 (ECDSA using P-521 + SHA256)"},
+                  {id: "ES384", name: "ES384 (ECDSA using P-384 + SHA384)"},
+                  {id: "ES521", name: "ES521 (ECDSA using P-521 + SHA512)"},
+                  {id: "EdDSA", name: "EdDSA (Ed25519)"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
@@ -223,6 +229,45 @@ class CertEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:State"), i18next.t("cert:State - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input disabled={true} value={this.state.cert.state} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:Successor"), i18next.t("cert:Successor - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input disabled={true} value={this.state.cert.successor} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("cert:Enable auto rotation"), i18next.t("cert:Enable auto rotation - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.cert.enableAutoRotation} onChange={checked => {
+              this.updateCertField("enableAutoRotation", checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:Rotate in days"), i18next.t("cert:Rotate in days - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} value={this.state.cert.rotateInDays} onChange={value => {
+              this.updateCertField("rotateInDays", value);
+            }} />
+            <Button style={{marginLeft: "10px"}} disabled={this.state.mode === "add" || this.state.cert.successor !== ""} onClick={() => this.rotateCert()}>
+              {i18next.t("cert:Rotate now")}
+            </Button>
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("cert:Certificate"), i18next.t("cert:Certificate - Tooltip"))} :
@@ -300,6 +345,21 @@ class CertEditPage extends React.Component {
       });
   }
 
+  rotateCert() {
+    CertBackend.rotateCert(this.state.cert)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully added"));
+          this.getCert();
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to add")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
   deleteCert() {
     CertBackend.deleteCert(this.state.cert)
       .then((res) => {
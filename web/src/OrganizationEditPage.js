@@ -13,7 +13,7 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, InputNumber, Radio, Row, Select, Switch} from "antd";
+import {Button, Card, Col, Input, InputNumber, Radio, Row, Select, Switch, Tag} from "antd";
 import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as ApplicationBackend from "./backend/ApplicationBackend";
 import * as LdapBackend from "./backend/LdapBackend";
@@ -37,6 +37,7 @@ class OrganizationEditPage extends React.Component {
       organization: null,
       applications: [],
       ldaps: null,
+      passwordTypeCounts: [],
       mode: props.location.mode !== undefined ? props.location.mode : "edit",
     };
   }
@@ -45,6 +46,7 @@ class OrganizationEditPage extends React.Component {
     this.getOrganization();
     this.getApplications();
     this.getLdaps();
+    this.getPasswordTypeCounts();
   }
 
   getOrganization() {
@@ -95,6 +97,17 @@ class OrganizationEditPage extends React.Component {
       });
   }
 
+  getPasswordTypeCounts() {
+    OrganizationBackend.getPasswordTypeCounts(this.state.organizationName)
+      .then(res => {
+        if (res.status === "ok") {
+          this.setState({
+            passwordTypeCounts: res.data ?? [],
+          });
+        }
+      });
+  }
+
   parseOrganizationField(key, value) {
     // if ([].includes(key)) {
     //   value = Setting.myParseInt(value);
@@ -188,6 +201,44 @@ class OrganizationEditPage extends React.Component {
             />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("organization:Target password type"), i18next.t("organization:Target password type - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.organization.targetPasswordType} onChange={(value => {this.updateOrganizationField("targetPasswordType", value);})}
+              options={[Setting.getOption(i18next.t("general:None"), ""), ...["plain", "salt", "md5-salt", "bcrypt", "pbkdf2-salt", "argon2id"].map(item => Setting.getOption(item, item))]}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("organization:Keep NT password hash"), i18next.t("organization:Keep NT password hash - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.organization.enableNtPasswordHash} onChange={checked => {
+              this.updateOrganizationField("enableNtPasswordHash", checked);
+            }} />
+          </Col>
+        </Row>
+        {
+          this.state.mode === "add" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("organization:Password types in use"), i18next.t("organization:Password types in use - Tooltip"))} :
+              </Col>
+              <Col span={22} style={{marginTop: "5px"}} >
+                {
+                  this.state.passwordTypeCounts.map(item => (
+                    <Tag key={item.passwordType} color={item.passwordType === (this.state.organization.targetPasswordType || this.state.organization.passwordType) ? "success" : "warning"}>
+                      {`${item.passwordType}: ${item.count}`}
+                    </Tag>
+                  ))
+                }
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Password salt"), i18next.t("general:Password salt - Tooltip"))} :
@@ -217,10 +268,37 @@ class OrganizationEditPage extends React.Component {
                 {value: "Aa123", name: i18next.t("user:The password must contain at least one uppercase letter, one lowercase letter and one digit")},
                 {value: "SpecialChar", name: i18next.t("user:The password must contain at least one special character")},
                 {value: "NoRepeat", name: i18next.t("user:The password must not contain any repeated characters")},
+                {value: "NoBreached", name: i18next.t("user:The password must not have appeared in a data breach")},
+                {value: "NoReuse", name: i18next.t("user:The password must not be the same as the recent passwords")},
+                {value: "NoPersonalInfo", name: i18next.t("user:The password must not contain the username, email or display name")},
               ].map((item) => Setting.getOption(item.name, item.value))}
             />
           </Col>
         </Row>
+        {
+          !this.state.organization.passwordOptions?.includes("NoReuse") ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+                {Setting.getLabel(i18next.t("organization:Password history count"), i18next.t("organization:Password history count - Tooltip"))} :
+              </Col>
+              <Col span={4} >
+                <InputNumber min={0} value={this.state.organization.passwordHistoryCount} onChange={value => {
+                  this.updateOrganizationField("passwordHistoryCount", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("organization:Password expire days"), i18next.t("organization:Password expire days - Tooltip"))} :
+          </Col>
+          <Col span={4} >
+            <InputNumber min={0} value={this.state.organization.passwordExpireDays} onChange={value => {
+              this.updateOrganizationField("passwordExpireDays", value);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Supported country codes"), i18next.t("general:Supported country codes - Tooltip"))} :
@@ -27,6 +27,8 @@ import * as ProviderEditTestSms from "./common/TestSmsWidget";
 import copy from "copy-to-clipboard";
 import {CaptchaPreview} from "./common/CaptchaPreview";
 import {CountryCodeSelect} from "./common/select/CountryCodeSelect";
+import ScimUserMappingTable from "./table/ScimUserMappingTable";
+import ProvisioningRecordTable from "./table/ProvisioningRecordTable";
 import * as Web3Auth from "./auth/Web3Auth";
 
 import {Controlled as CodeMirror} from "react-codemirror2";
@@ -237,6 +239,8 @@ class ProviderEditPage extends React.Component {
       } else {
         return Setting.getLabel(i18next.t("provider:Secret key"), i18next.t("provider:Secret key - Tooltip"));
       }
+    case "SCIM":
+      return Setting.getLabel(i18next.t("provider:Bearer token"), i18next.t("provider:Bearer token - Tooltip"));
     case "Notification":
       if (provider.type === "Line" || provider.type === "Telegram" || provider.type === "Bark" || provider.type === "DingTalk" || provider.type === "Discord" || provider.type === "Slack" || provider.type === "Pushover" || provider.type === "Pushbullet") {
         return Setting.getLabel(i18next.t("provider:Secret key"), i18next.t("provider:Secret key - Tooltip"));
@@ -504,6 +508,8 @@ class ProviderEditPage extends React.Component {
                 this.updateProviderField("type", "MetaMask");
               } else if (value === "Notification") {
                 this.updateProviderField("type", "Telegram");
+              } else if (value === "SCIM") {
+                this.updateProviderField("type", "SCIM 2.0");
               }
             })}>
               {
@@ -514,6 +520,7 @@ class ProviderEditPage extends React.Component {
                   {id: "OAuth", name: "OAuth"},
                   {id: "Payment", name: "Payment"},
                   {id: "SAML", name: "SAML"},
+                  {id: "SCIM", name: "SCIM"},
                   {id: "SMS", name: "SMS"},
                   {id: "Storage", name: "Storage"},
                   {id: "Web3", name: "Web3"},
@@ -698,6 +705,7 @@ class ProviderEditPage extends React.Component {
                 {
                   (this.state.provider.category === "Storage" && this.state.provider.type === "Google Cloud Storage") ||
                   (this.state.provider.category === "Email" && this.state.provider.type === "Azure ACS") ||
+                  (this.state.provider.category === "SCIM") ||
                   (this.state.provider.category === "Notification" && (this.state.provider.type === "Line" || this.state.provider.type === "Telegram" || this.state.provider.type === "Bark" || this.state.provider.type === "Discord" || this.state.provider.type === "Slack" || this.state.provider.type === "Pushbullet" || this.state.provider.type === "Pushover" || this.state.provider.type === "Lark" || this.state.provider.type === "Microsoft Teams")) ? null : (
                       <Row style={{marginTop: "20px"}} >
                         <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
@@ -782,6 +790,49 @@ class ProviderEditPage extends React.Component {
             </Row>
           )
         }
+        {
+          this.state.provider.category !== "SCIM" ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:Endpoint"), i18next.t("provider:SCIM endpoint - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <Input prefix={<LinkOutlined />} value={this.state.provider.endpoint} placeholder="https://example.com/scim/v2" onChange={e => {
+                    this.updateProviderField("endpoint", e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("provider:User mapping"), i18next.t("provider:User mapping - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <ScimUserMappingTable
+                    title={i18next.t("provider:User mapping")}
+                    userMapping={this.state.provider.userMapping}
+                    onUpdateUserMapping={(value) => {this.updateProviderField("userMapping", value);}}
+                  />
+                </Col>
+              </Row>
+              {
+                this.state.mode === "add" ? null : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {Setting.getLabel(i18next.t("provider:Sync status"), i18next.t("provider:Sync status - Tooltip"))} :
+                    </Col>
+                    <Col span={22} >
+                      <ProvisioningRecordTable
+                        title={i18next.t("provider:Sync status")}
+                        provider={this.state.provider}
+                      />
+                    </Col>
+                  </Row>
+                )
+              }
+            </React.Fragment>
+          )
+        }
         {this.state.provider.category === "Storage" || ["Custom HTTP SMS", "Custom HTTP Email"].includes(this.state.provider.type) ? (
           <div>
             {["Local File System"].includes(this.state.provider.type) ? null : (
@@ -0,0 +1,200 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Col, Input, Row, Select} from "antd";
+import * as RadiusClientBackend from "./backend/RadiusClientBackend";
+import * as OrganizationBackend from "./backend/OrganizationBackend";
+import * as Setting from "./Setting";
+import i18next from "i18next";
+
+const {Option} = Select;
+
+class RadiusClientEditPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      organizationName: props.organizationName !== undefined ? props.organizationName : props.match.params.organizationName,
+      radiusClientName: props.match.params.radiusClientName,
+      radiusClient: null,
+      organizations: [],
+      mode: props.location.mode !== undefined ? props.location.mode : "edit",
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getRadiusClient();
+    this.getOrganizations();
+  }
+
+  getRadiusClient() {
+    RadiusClientBackend.getRadiusClient(this.state.organizationName, this.state.radiusClientName)
+      .then((res) => {
+        if (res.status === "ok") {
+          if (res.data === null) {
+            this.props.history.push("/404");
+            return;
+          }
+
+          this.setState({
+            radiusClient: res.data,
+          });
+        }
+      });
+  }
+
+  getOrganizations() {
+    OrganizationBackend.getOrganizations("admin")
+      .then((res) => {
+        this.setState({
+          organizations: res.data || [],
+        });
+      });
+  }
+
+  updateRadiusClientField(key, value) {
+    const radiusClient = this.state.radiusClient;
+    radiusClient[key] = value;
+    this.setState({
+      radiusClient: radiusClient,
+    });
+  }
+
+  renderRadiusClient() {
+    return (
+      <Card size="small" title={
+        <div>
+          {this.state.mode === "add" ? i18next.t("radiusClient:New RADIUS Client") : i18next.t("radiusClient:Edit RADIUS Client")}&nbsp;&nbsp;&nbsp;&nbsp;
+          <Button onClick={() => this.submitRadiusClientEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: "20px"}} type="primary" onClick={() => this.submitRadiusClientEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: "20px"}} onClick={() => this.deleteRadiusClient()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      } style={(Setting.isMobile()) ? {margin: "5px"} : {}} type="inner">
+        <Row style={{marginTop: "10px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Organization"), i18next.t("general:Organization - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} disabled={!Setting.isAdminUser(this.props.account)} value={this.state.radiusClient.owner} onChange={(value => {
+              this.updateRadiusClientField("owner", value);
+            })}>
+              {
+                this.state.organizations.map((organization, index) => <Option key={index} value={organization.name}>{organization.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Name"), i18next.t("general:Name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.radiusClient.name} onChange={e => {
+              this.updateRadiusClientField("name", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Display name"), i18next.t("general:Display name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.radiusClient.displayName} onChange={e => {
+              this.updateRadiusClientField("displayName", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("radiusClient:IP address"), i18next.t("radiusClient:IP address - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.radiusClient.ipAddress} placeholder={"192.168.0.0/24"} onChange={e => {
+              this.updateRadiusClientField("ipAddress", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("radiusClient:Secret"), i18next.t("radiusClient:Secret - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input.Password value={this.state.radiusClient.secret} onChange={e => {
+              this.updateRadiusClientField("secret", e.target.value);
+            }} />
+          </Col>
+        </Row>
+      </Card>
+    );
+  }
+
+  submitRadiusClientEdit(exitAfterSave) {
+    const radiusClient = Setting.deepCopy(this.state.radiusClient);
+    RadiusClientBackend.updateRadiusClient(this.state.organizationName, this.state.radiusClientName, radiusClient)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully saved"));
+          this.setState({
+            organizationName: this.state.radiusClient.owner,
+            radiusClientName: this.state.radiusClient.name,
+          });
+
+          if (exitAfterSave) {
+            this.props.history.push("/radius-clients");
+          } else {
+            this.props.history.push(`/radius-clients/${this.state.radiusClient.owner}/${this.state.radiusClient.name}`);
+          }
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to save")}: ${res.msg}`);
+          this.updateRadiusClientField("owner", this.state.organizationName);
+          this.updateRadiusClientField("name", this.state.radiusClientName);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  deleteRadiusClient() {
+    RadiusClientBackend.deleteRadiusClient(this.state.radiusClient)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.props.history.push("/radius-clients");
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  render() {
+    return (
+      <div>
+        {
+          this.state.radiusClient !== null ? this.renderRadiusClient() : null
+        }
+        <div style={{marginTop: "20px", marginLeft: "40px"}}>
+          <Button size="large" onClick={() => this.submitRadiusClientEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: "20px"}} type="primary" size="large" onClick={() => this.submitRadiusClientEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: "20px"}} size="large" onClick={() => this.deleteRadiusClient()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      </div>
+    );
+  }
+}
+
+export default RadiusClientEditPage;
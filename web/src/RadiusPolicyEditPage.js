@@ -0,0 +1,274 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Col, Input, InputNumber, Row, Select, Switch} from "antd";
+import * as RadiusPolicyBackend from "./backend/RadiusPolicyBackend";
+import * as OrganizationBackend from "./backend/OrganizationBackend";
+import * as GroupBackend from "./backend/GroupBackend";
+import * as RoleBackend from "./backend/RoleBackend";
+import * as Setting from "./Setting";
+import i18next from "i18next";
+import RadiusAttributeTable from "./table/RadiusAttributeTable";
+
+const {Option} = Select;
+
+class RadiusPolicyEditPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      organizationName: props.organizationName !== undefined ? props.organizationName : props.match.params.organizationName,
+      radiusPolicyName: props.match.params.radiusPolicyName,
+      radiusPolicy: null,
+      organizations: [],
+      groups: [],
+      roles: [],
+      mode: props.location.mode !== undefined ? props.location.mode : "edit",
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getRadiusPolicy();
+    this.getOrganizations();
+  }
+
+  getRadiusPolicy() {
+    RadiusPolicyBackend.getRadiusPolicy(this.state.organizationName, this.state.radiusPolicyName)
+      .then((res) => {
+        if (res.status === "ok") {
+          if (res.data === null) {
+            this.props.history.push("/404");
+            return;
+          }
+
+          this.setState({
+            radiusPolicy: res.data,
+          });
+
+          this.getGroups(res.data.owner);
+          this.getRoles(res.data.owner);
+        }
+      });
+  }
+
+  getGroups(organizationName) {
+    GroupBackend.getGroups(organizationName)
+      .then((res) => {
+        if (res.status === "error") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        this.setState({
+          groups: res.data,
+        });
+      });
+  }
+
+  getRoles(organizationName) {
+    RoleBackend.getRoles(organizationName)
+      .then((res) => {
+        if (res.status === "error") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        this.setState({
+          roles: res.data,
+        });
+      });
+  }
+
+  getOrganizations() {
+    OrganizationBackend.getOrganizations("admin")
+      .then((res) => {
+        this.setState({
+          organizations: res.data || [],
+        });
+      });
+  }
+
+  updateRadiusPolicyField(key, value) {
+    const radiusPolicy = this.state.radiusPolicy;
+    radiusPolicy[key] = value;
+    this.setState({
+      radiusPolicy: radiusPolicy,
+    });
+  }
+
+  renderRadiusPolicy() {
+    return (
+      <Card size="small" title={
+        <div>
+          {this.state.mode === "add" ? i18next.t("radiusPolicy:New RADIUS Policy") : i18next.t("radiusPolicy:Edit RADIUS Policy")}&nbsp;&nbsp;&nbsp;&nbsp;
+          <Button onClick={() => this.submitRadiusPolicyEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: "20px"}} type="primary" onClick={() => this.submitRadiusPolicyEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: "20px"}} onClick={() => this.deleteRadiusPolicy()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      } style={(Setting.isMobile()) ? {margin: "5px"} : {}} type="inner">
+        <Row style={{marginTop: "10px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Organization"), i18next.t("general:Organization - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} disabled={!Setting.isAdminUser(this.props.account)} value={this.state.radiusPolicy.owner} onChange={(value => {
+              this.updateRadiusPolicyField("owner", value);
+              this.updateRadiusPolicyField("roles", []);
+              this.updateRadiusPolicyField("groups", []);
+              this.getGroups(value);
+              this.getRoles(value);
+            })}>
+              {
+                this.state.organizations.map((organization, index) => <Option key={index} value={organization.name}>{organization.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Name"), i18next.t("general:Name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.radiusPolicy.name} onChange={e => {
+              this.updateRadiusPolicyField("name", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Display name"), i18next.t("general:Display name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.radiusPolicy.displayName} onChange={e => {
+              this.updateRadiusPolicyField("displayName", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("role:Sub roles"), i18next.t("radiusPolicy:Roles - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="multiple" style={{width: "100%"}} value={this.state.radiusPolicy.roles}
+              onChange={(value => {this.updateRadiusPolicyField("roles", value);})}
+              options={this.state.roles.map((role) => Setting.getOption(`${role.owner}/${role.name}`, `${role.owner}/${role.name}`))}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("role:Sub groups"), i18next.t("radiusPolicy:Groups - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="multiple" style={{width: "100%"}} value={this.state.radiusPolicy.groups}
+              onChange={(value => {this.updateRadiusPolicyField("groups", value);})}
+              options={this.state.groups.map((group) => Setting.getOption(`${group.owner}/${group.name}`, `${group.owner}/${group.name}`))}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("radiusPolicy:Priority"), i18next.t("radiusPolicy:Priority - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <InputNumber value={this.state.radiusPolicy.priority} onChange={value => {
+              this.updateRadiusPolicyField("priority", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("radiusPolicy:Attributes"), i18next.t("radiusPolicy:Attributes - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <RadiusAttributeTable
+              title={i18next.t("radiusPolicy:Attributes")}
+              table={this.state.radiusPolicy.attributes ?? []}
+              onUpdateTable={(value) => {this.updateRadiusPolicyField("attributes", value);}}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("general:Is enabled"), i18next.t("general:Is enabled - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.radiusPolicy.isEnabled} onChange={checked => {
+              this.updateRadiusPolicyField("isEnabled", checked);
+            }} />
+          </Col>
+        </Row>
+      </Card>
+    );
+  }
+
+  submitRadiusPolicyEdit(exitAfterSave) {
+    const radiusPolicy = Setting.deepCopy(this.state.radiusPolicy);
+    RadiusPolicyBackend.updateRadiusPolicy(this.state.organizationName, this.state.radiusPolicyName, radiusPolicy)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully saved"));
+          this.setState({
+            organizationName: this.state.radiusPolicy.owner,
+            radiusPolicyName: this.state.radiusPolicy.name,
+          });
+
+          if (exitAfterSave) {
+            this.props.history.push("/radius-policies");
+          } else {
+            this.props.history.push(`/radius-policies/${this.state.radiusPolicy.owner}/${this.state.radiusPolicy.name}`);
+          }
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to save")}: ${res.msg}`);
+          this.updateRadiusPolicyField("owner", this.state.organizationName);
+          this.updateRadiusPolicyField("name", this.state.radiusPolicyName);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  deleteRadiusPolicy() {
+    RadiusPolicyBackend.deleteRadiusPolicy(this.state.radiusPolicy)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.props.history.push("/radius-policies");
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  render() {
+    return (
+      <div>
+        {
+          this.state.radiusPolicy !== null ? this.renderRadiusPolicy() : null
+        }
+        <div style={{marginTop: "20px", marginLeft: "40px"}}>
+          <Button size="large" onClick={() => this.submitRadiusPolicyEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: "20px"}} type="primary" size="large" onClick={() => this.submitRadiusPolicyEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: "20px"}} size="large" onClick={() => this.deleteRadiusPolicy()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      </div>
+    );
+  }
+}
+
+export default RadiusPolicyEditPage;
@@ -280,6 +280,12 @@ export const OtherProviderInfo = {
       url: "https://platform.openai.com",
     },
   },
+  SCIM: {
+    "SCIM 2.0": {
+      logo: `${StaticBaseUrl}/img/email_default.png`,
+      url: "https://scim.cloud/",
+    },
+  },
   Web3: {
     "MetaMask": {
       logo: `${StaticBaseUrl}/img/social_metamask.svg`,
@@ -1050,6 +1056,10 @@ export function getProviderTypeOptions(category) {
       {id: "GEETEST", name: "GEETEST"},
       {id: "Cloudflare Turnstile", name: "Cloudflare Turnstile"},
     ]);
+  } else if (category === "SCIM") {
+    return ([
+      {id: "SCIM 2.0", name: "SCIM 2.0"},
+    ]);
   } else if (category === "Web3") {
     return ([
       {id: "MetaMask", name: "MetaMask"},
@@ -1082,7 +1092,7 @@ export function getProviderTypeOptions(category) {
 }
 
 export function getCryptoAlgorithmOptions(cryptoAlgorithm) {
-  if (cryptoAlgorithm === "RS256") {
+  if (cryptoAlgorithm.startsWith("RS") || cryptoAlgorithm.startsWith("PS")) {
     return (
       [
         {id: 1024, name: "1024"},
@@ -13,13 +13,14 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, Row, Select, Switch} from "antd";
+import {Button, Card, Col, Input, InputNumber, Row, Select, Switch} from "antd";
 import {LinkOutlined} from "@ant-design/icons";
 import * as WebhookBackend from "./backend/WebhookBackend";
 import * as OrganizationBackend from "./backend/OrganizationBackend";
 import * as Setting from "./Setting";
 import i18next from "i18next";
 import WebhookHeaderTable from "./table/WebhookHeaderTable";
+import WebhookDeliveryTable from "./table/WebhookDeliveryTable";
 
 import {Controlled as CodeMirror} from "react-codemirror2";
 import "codemirror/lib/codemirror.css";
@@ -102,6 +103,22 @@ const userTemplate = {
   },
 };
 
+const eventPreviewTemplate = {
+  "specversion": "1.0",
+  "id": "0c9b1b6a-3c4f-4b8e-9f0e-1f7d5d0b6a2e",
+  "source": "/organizations/built-in",
+  "type": "org.casdoor.user.updated",
+  "subject": "built-in/admin",
+  "time": "2022-01-01T01:03:42+08:00",
+  "datacontenttype": "application/json",
+  "data": {
+    "organization": "built-in",
+    "before": {...userTemplate, "email": "admin@old-example.com"},
+    "after": userTemplate,
+    "changedFields": ["email"],
+  },
+};
+
 class WebhookEditPage extends React.Component {
   constructor(props) {
     super(props);
@@ -171,9 +188,15 @@ class WebhookEditPage extends React.Component {
   }
 
   renderWebhook() {
-    const preview = Setting.deepCopy(previewTemplate);
-    if (this.state.webhook.isUserExtended) {
-      preview["extendedUser"] = userTemplate;
+    let preview;
+    if (this.state.webhook.isCloudEvents) {
+      // the lifecycle events of the entities are sent as CloudEvents with the before and after entities
+      preview = Setting.deepCopy(eventPreviewTemplate);
+    } else {
+      preview = Setting.deepCopy(previewTemplate);
+      if (this.state.webhook.isUserExtended) {
+        preview["extendedUser"] = userTemplate;
+      }
     }
     const previewText = JSON.stringify(preview, null, 2);
 
@@ -294,6 +317,16 @@ class WebhookEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("webhook:Is CloudEvents"), i18next.t("webhook:Is CloudEvents - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.webhook.isCloudEvents} onChange={checked => {
+              this.updateWebhookField("isCloudEvents", checked);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("general:Preview"), i18next.t("general:Preview - Tooltip"))} :
@@ -308,6 +341,44 @@ class WebhookEditPage extends React.Component {
             </div>
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("webhook:Secret"), i18next.t("webhook:Secret - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input.Password value={this.state.webhook.secret} onChange={e => {
+              this.updateWebhookField("secret", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("webhook:Max retries"), i18next.t("webhook:Max retries - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} value={this.state.webhook.maxRetries} onChange={value => {
+              this.updateWebhookField("maxRetries", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("webhook:Notification email"), i18next.t("webhook:Notification email - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.webhook.notificationEmail} onChange={e => {
+              this.updateWebhookField("notificationEmail", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("webhook:Consecutive failures"), i18next.t("webhook:Consecutive failures - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <InputNumber disabled={true} value={this.state.webhook.consecutiveFailures} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("general:Is enabled"), i18next.t("general:Is enabled - Tooltip"))} :
@@ -318,6 +389,22 @@ class WebhookEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        {
+          this.state.mode === "add" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("webhook:Deliveries"), i18next.t("webhook:Deliveries - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <WebhookDeliveryTable
+                  title={i18next.t("webhook:Deliveries")}
+                  owner={this.state.webhook.owner}
+                  webhook={this.state.webhook.name}
+                />
+              </Col>
+            </Row>
+          )
+        }
       </Card>
     );
   }
@@ -57,7 +57,7 @@ export function oAuthParamsToQuery(oAuthParams) {
   }
 
   // code
-  return `?clientId=${oAuthParams.clientId}&responseType=${oAuthParams.responseType}&redirectUri=${encodeURIComponent(oAuthParams.redirectUri)}&type=${oAuthParams.type}&scope=${oAuthParams.scope}&state=${oAuthParams.state}&nonce=${oAuthParams.nonce}&code_challenge_method=${oAuthParams.challengeMethod}&code_challenge=${oAuthParams.codeChallenge}`;
+  return `?clientId=${oAuthParams.clientId}&responseType=${oAuthParams.responseType}&redirectUri=${encodeURIComponent(oAuthParams.redirectUri)}&type=${oAuthParams.type}&scope=${oAuthParams.scope}&state=${oAuthParams.state}&nonce=${oAuthParams.nonce}&code_challenge_method=${oAuthParams.challengeMethod}&code_challenge=${oAuthParams.codeChallenge}&request_uri=${encodeURIComponent(oAuthParams.requestUri ?? "")}&request=${oAuthParams.request ?? ""}`;
 }
 
 export function getApplicationLogin(params) {
@@ -154,3 +154,23 @@ export function getCaptchaStatus(values) {
     },
   }).then(res => res.json());
 }
+
+export function getDeviceAuthApplication(userCode) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/device?user_code=${encodeURIComponent(userCode)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function verifyDeviceCode(userCode, approved) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/device?user_code=${encodeURIComponent(userCode)}&approved=${approved}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
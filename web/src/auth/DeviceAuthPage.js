@@ -0,0 +1,118 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Input, Result, Space} from "antd";
+import i18next from "i18next";
+import * as AuthBackend from "./AuthBackend";
+import * as Setting from "../Setting";
+
+class DeviceAuthPage extends React.Component {
+  constructor(props) {
+    super(props);
+    const params = new URLSearchParams(this.props.location.search);
+    this.state = {
+      classes: props,
+      userCode: params.get("user_code") ?? "",
+      application: null,
+      result: "",
+    };
+  }
+
+  componentDidMount() {
+    this.props.onUpdateApplication(null);
+    if (this.state.userCode !== "") {
+      this.getDeviceAuthApplication();
+    }
+  }
+
+  getDeviceAuthApplication() {
+    AuthBackend.getDeviceAuthApplication(this.state.userCode)
+      .then((res) => {
+        if (res.status === "error") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        this.props.onUpdateApplication(res.data);
+        this.setState({
+          application: res.data,
+        });
+      });
+  }
+
+  verifyDeviceCode(approved) {
+    AuthBackend.verifyDeviceCode(this.state.userCode, approved)
+      .then((res) => {
+        if (res.status === "error") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        this.setState({
+          result: approved ? "approved" : "denied",
+        });
+      });
+  }
+
+  renderContent() {
+    if (this.state.result !== "") {
+      return (
+        <Result
+          status={this.state.result === "approved" ? "success" : "warning"}
+          title={this.state.result === "approved" ? i18next.t("login:The device has been signed in") : i18next.t("login:The device sign-in request has been denied")}
+          subTitle={i18next.t("login:You can now close this page and return to your device")}
+        />
+      );
+    }
+
+    if (this.state.application === null) {
+      return (
+        <Space direction="vertical" style={{width: "100%"}}>
+          {i18next.t("login:Enter the code displayed on your device")}
+          <Input value={this.state.userCode} placeholder="XXXX-XXXX" onChange={e => {
+            this.setState({userCode: e.target.value});
+          }} onPressEnter={() => this.getDeviceAuthApplication()} />
+          <Button type="primary" block onClick={() => this.getDeviceAuthApplication()}>
+            {i18next.t("forget:Next Step")}
+          </Button>
+        </Space>
+      );
+    }
+
+    return (
+      <Space direction="vertical" style={{width: "100%"}}>
+        {`${i18next.t("login:Do you want to sign in to this device with application")}: ${this.state.application.displayName}`}
+        <Button type="primary" block onClick={() => this.verifyDeviceCode(true)}>
+          {i18next.t("general:Confirm")}
+        </Button>
+        <Button block onClick={() => this.verifyDeviceCode(false)}>
+          {i18next.t("general:Cancel")}
+        </Button>
+      </Space>
+    );
+  }
+
+  render() {
+    return (
+      <div style={{display: "flex", flex: "1", justifyContent: "center"}}>
+        <Card style={{width: "400px", marginTop: "40px"}} title={i18next.t("login:Device sign in")}>
+          {this.renderContent()}
+        </Card>
+      </div>
+    );
+  }
+}
+
+export default DeviceAuthPage;
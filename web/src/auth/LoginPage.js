@@ -36,6 +36,7 @@ import {CaptchaModal} from "../common/modal/CaptchaModal";
 import {CaptchaRule} from "../common/modal/CaptchaModal";
 import RedirectForm from "../common/RedirectForm";
 import {MfaAuthVerifyForm, NextMfa, RequiredMfa} from "./mfa/MfaAuthVerifyForm";
+import {UpdatePassword, UpdatePasswordForm} from "./UpdatePasswordForm";
 import {GoogleOneTapLoginVirtualButton} from "./GoogleLoginButton";
 class LoginPage extends React.Component {
   constructor(props) {
@@ -150,6 +151,10 @@ class LoginPage extends React.Component {
       .then((res) => {
         if (res.status === "ok") {
           const application = res.data;
+          if (res.data2) {
+            // the parameters are resolved from the pushed authorization request or the request object
+            this.updateOAuthGetParameters(res.data2);
+          }
           this.onUpdateApplication(application);
         } else {
           this.onUpdateApplication(null);
@@ -160,6 +165,14 @@ class LoginPage extends React.Component {
       });
   }
 
+  updateOAuthGetParameters(oAuthRequest) {
+    const params = new URLSearchParams(window.location.search);
+    Object.entries(oAuthRequest).forEach(([key, value]) => {
+      params.set(key, value);
+    });
+    window.history.replaceState(null, "", `${window.location.pathname}?${params.toString()}`);
+  }
+
   getApplication() {
     if (this.state.applicationName === null) {
       return null;
@@ -330,7 +343,26 @@ class LoginPage extends React.Component {
     this.login(values);
   }
 
+  showUpdatePasswordForm(userId, password) {
+    this.setState({
+      getVerifyTotp: () => {
+        return (
+          <UpdatePasswordForm
+            userId={userId}
+            oldPassword={password}
+            application={this.getApplicationObj()}
+            onSuccess={() => {
+              // sign in again with the new password
+              this.setState({getVerifyTotp: undefined});
+            }}
+          />);
+      },
+    });
+  }
+
   login(values) {
+    // the password is cleared from the values in the MFA step, but is needed to update the expired password
+    const password = values.password;
     // here we are supposed to determine whether Casdoor is working as an OAuth server or CAS server
     if (this.state.type === "cas") {
       // CAS
@@ -338,6 +370,11 @@ class LoginPage extends React.Component {
       values["type"] = this.state.type;
       AuthBackend.loginCas(values, casParams).then((res) => {
         const loginHandler = (res) => {
+          if (res.data === UpdatePassword) {
+            this.showUpdatePasswordForm(res.data2, password);
+            return;
+          }
+
           let msg = "Logged in successfully. ";
           if (casParams.service === "") {
             // If service was not specified, Casdoor must display a message notifying the client that it has successfully initiated a single sign-on session.
@@ -384,6 +421,11 @@ class LoginPage extends React.Component {
       AuthBackend.login(values, oAuthParams)
         .then((res) => {
           const loginHandler = (res) => {
+            if (res.data === UpdatePassword) {
+              this.showUpdatePasswordForm(res.data2, password);
+              return;
+            }
+
             const responseType = values["type"];
 
             if (responseType === "login") {
@@ -790,7 +832,7 @@ class LoginPage extends React.Component {
         const userHandle = assertion.response.userHandle;
         let finishUrl = `${Setting.ServerUrl}/api/webauthn/signin/finish?responseType=${values["type"]}`;
         if (values["type"] === "code") {
-          finishUrl = `${Setting.ServerUrl}/api/webauthn/signin/finish?responseType=${values["type"]}&clientId=${oAuthParams.clientId}&scope=${oAuthParams.scope}&redirectUri=${oAuthParams.redirectUri}&nonce=${oAuthParams.nonce}&state=${oAuthParams.state}&codeChallenge=${oAuthParams.codeChallenge}&challengeMethod=${oAuthParams.challengeMethod}`;
+          finishUrl = `${Setting.ServerUrl}/api/webauthn/signin/finish?responseType=${values["type"]}&clientId=${oAuthParams.clientId}&scope=${oAuthParams.scope}&redirectUri=${oAuthParams.redirectUri}&nonce=${oAuthParams.nonce}&state=${oAuthParams.state}&codeChallenge=${oAuthParams.codeChallenge}&challengeMethod=${oAuthParams.challengeMethod}&request_uri=${encodeURIComponent(oAuthParams.requestUri)}`;
         }
         return fetch(finishUrl, {
           method: "POST",
@@ -0,0 +1,112 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+import React, {useState} from "react";
+import i18next from "i18next";
+import {Button, Form, Input} from "antd";
+import {LockOutlined} from "@ant-design/icons";
+import * as UserBackend from "../backend/UserBackend";
+import * as PasswordChecker from "../common/PasswordChecker";
+import * as Setting from "../Setting";
+
+export const UpdatePassword = "UpdatePassword";
+
+export function UpdatePasswordForm({userId, oldPassword, application, onSuccess}) {
+  const [loading, setLoading] = useState(false);
+  const [owner, name] = userId.split("/");
+
+  const updatePassword = (values) => {
+    setLoading(true);
+    UserBackend.setPassword(owner, name, oldPassword ? oldPassword : values.oldPassword, values.newPassword)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("user:Password set successfully"));
+          onSuccess();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .finally(() => {
+        setLoading(false);
+      });
+  };
+
+  return (
+    <div style={{width: 320}}>
+      <div style={{marginBottom: 24, textAlign: "center", fontSize: "24px"}}>
+        {i18next.t("login:Your password has expired")}
+      </div>
+      <div style={{marginBottom: 24}}>
+        {i18next.t("login:Please set a new password to continue signing in")}
+      </div>
+      <Form onFinish={updatePassword}>
+        {oldPassword ? null : (
+          <Form.Item
+            name="oldPassword"
+            rules={[{required: true, message: i18next.t("login:Please input your password!")}]}
+          >
+            <Input.Password prefix={<LockOutlined />} placeholder={i18next.t("user:Old Password")} />
+          </Form.Item>
+        )}
+        <Form.Item
+          name="newPassword"
+          rules={[
+            {
+              required: true,
+              validateTrigger: "onChange",
+              validator: (rule, value) => {
+                const errorMsg = PasswordChecker.checkPasswordComplexity(value ?? "", application.organizationObj.passwordOptions);
+                if (errorMsg === "") {
+                  return Promise.resolve();
+                } else {
+                  return Promise.reject(errorMsg);
+                }
+              },
+            },
+          ]}
+          hasFeedback
+        >
+          <Input.Password prefix={<LockOutlined />} placeholder={i18next.t("user:New Password")} />
+        </Form.Item>
+        <Form.Item
+          name="confirm"
+          dependencies={["newPassword"]}
+          hasFeedback
+          rules={[
+            {
+              required: true,
+              message: i18next.t("signup:Please confirm your password!"),
+            },
+            ({getFieldValue}) => ({
+              validator(rule, value) {
+                if (!value || getFieldValue("newPassword") === value) {
+                  return Promise.resolve();
+                }
+                return Promise.reject(i18next.t("signup:Your confirmed password is inconsistent with the password!"));
+              },
+            }),
+          ]}
+        >
+          <Input.Password prefix={<LockOutlined />} placeholder={i18next.t("user:Re-enter New")} />
+        </Form.Item>
+        <Form.Item>
+          <Button style={{width: "100%"}} type="primary" htmlType="submit" loading={loading}>
+            {i18next.t("user:Set Password")}
+          </Button>
+        </Form.Item>
+      </Form>
+    </div>
+  );
+}
@@ -140,6 +140,8 @@ export function getOAuthGetParameters(params) {
   const samlRequest = getRefinedValue(queries.get("SAMLRequest"));
   const relayState = getRefinedValue(queries.get("RelayState"));
   const noRedirect = getRefinedValue(queries.get("noRedirect"));
+  const requestUri = getRefinedValue(queries.get("request_uri"));
+  const request = getRefinedValue(queries.get("request"));
 
   if (clientId === "" && samlRequest === "") {
     // login
@@ -158,6 +160,8 @@ export function getOAuthGetParameters(params) {
       samlRequest: samlRequest,
       relayState: relayState,
       noRedirect: noRedirect,
+      requestUri: requestUri,
+      request: request,
       type: "code",
     };
   }
@@ -79,3 +79,13 @@ export function deleteCert(cert) {
     },
   }).then(res => res.json());
 }
+
+export function rotateCert(cert, activate = false) {
+  return fetch(`${Setting.ServerUrl}/api/rotate-cert?id=${cert.owner}/${encodeURIComponent(cert.name)}&activate=${activate}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
@@ -89,3 +89,13 @@ export function getOrganizationNames(owner) {
     },
   }).then(res => res.json());
 }
+
+export function getPasswordTypeCounts(owner) {
+  return fetch(`${Setting.ServerUrl}/api/get-password-type-counts?owner=${owner}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
@@ -79,3 +79,25 @@ export function deleteProvider(provider) {
     },
   }).then(res => res.json());
 }
+
+export function getProvisioningRecords(owner, provider, page = "", pageSize = "") {
+  return fetch(`${Setting.ServerUrl}/api/get-provisioning-records?owner=${owner}&provider=${encodeURIComponent(provider)}&p=${page}&pageSize=${pageSize}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function reconcileProvider(provider) {
+  const newProvider = Setting.deepCopy(provider);
+  return fetch(`${Setting.ServerUrl}/api/reconcile-provider`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(newProvider),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
@@ -69,3 +69,25 @@ export function deleteWebhook(webhook) {
     },
   }).then(res => res.json());
 }
+
+export function getWebhookDeliveries(owner, webhook, page = "", pageSize = "") {
+  return fetch(`${Setting.ServerUrl}/api/get-webhook-deliveries?owner=${owner}&webhook=${encodeURIComponent(webhook)}&p=${page}&pageSize=${pageSize}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function redeliverWebhookDelivery(delivery) {
+  const newDelivery = Setting.deepCopy(delivery);
+  return fetch(`${Setting.ServerUrl}/api/redeliver-webhook-delivery`, {
+    method: "POST",
+    credentials: "include",
+    body: JSON.stringify(newDelivery),
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
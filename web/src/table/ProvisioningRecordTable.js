@@ -0,0 +1,156 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Table, Tag, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import * as ProviderBackend from "../backend/ProviderBackend";
+import i18next from "i18next";
+
+class ProvisioningRecordTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      records: [],
+      pagination: {
+        current: 1,
+        pageSize: 10,
+      },
+      loading: false,
+      reconciling: false,
+    };
+  }
+
+  componentDidMount() {
+    this.fetch(this.state.pagination);
+  }
+
+  fetch(pagination) {
+    this.setState({loading: true});
+    ProviderBackend.getProvisioningRecords(this.props.provider.owner, this.props.provider.name, pagination.current, pagination.pageSize)
+      .then((res) => {
+        this.setState({loading: false});
+        if (res.status === "ok") {
+          this.setState({
+            records: res.data,
+            pagination: {
+              ...pagination,
+              total: res.data2,
+            },
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  reconcile() {
+    this.setState({reconciling: true});
+    ProviderBackend.reconcileProvider(this.props.provider)
+      .then((res) => {
+        this.setState({reconciling: false});
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("provider:Reconciled"));
+          this.fetch(this.state.pagination);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        this.setState({reconciling: false});
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  getStateColor(state) {
+    if (state === "Synced") {
+      return "success";
+    } else if (state === "Failed") {
+      return "error";
+    } else {
+      return "processing";
+    }
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("provider:Resource type"),
+        dataIndex: "resourceType",
+        key: "resourceType",
+        width: "120px",
+      },
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "resourceName",
+        key: "resourceName",
+        width: "200px",
+      },
+      {
+        title: i18next.t("provider:Remote ID"),
+        dataIndex: "remoteId",
+        key: "remoteId",
+        width: "200px",
+        ellipsis: true,
+      },
+      {
+        title: i18next.t("general:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "110px",
+        render: (text, record, index) => {
+          return (
+            <Tooltip title={record.error}>
+              <Tag color={this.getStateColor(text)}>{text}</Tag>
+            </Tooltip>
+          );
+        },
+      },
+      {
+        title: i18next.t("provider:Synced time"),
+        dataIndex: "syncedTime",
+        key: "syncedTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return text === "" ? "" : Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("general:Updated time"),
+        dataIndex: "updatedTime",
+        key: "updatedTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+    ];
+
+    return (
+      <Table scroll={{x: "max-content"}} rowKey="name" columns={columns} dataSource={this.state.records} size="middle" bordered
+        pagination={this.state.pagination} loading={this.state.loading}
+        title={() => (
+          <div>
+            {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" loading={this.state.reconciling} onClick={() => this.reconcile()}>{i18next.t("provider:Reconcile")}</Button>
+          </div>
+        )}
+        onChange={(pagination) => this.fetch(pagination)}
+      />
+    );
+  }
+}
+
+export default ProvisioningRecordTable;
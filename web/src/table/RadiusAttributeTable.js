@@ -0,0 +1,200 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {DeleteOutlined, DownOutlined, UpOutlined} from "@ant-design/icons";
+import {Button, Col, Input, InputNumber, Row, Select, Table, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+const {Option} = Select;
+
+const attributeNames = [
+  "Tunnel-Private-Group-Id",
+  "Tunnel-Type",
+  "Tunnel-Medium-Type",
+  "Filter-Id",
+  "Session-Timeout",
+  "Idle-Timeout",
+  "Acct-Interim-Interval",
+  "Class",
+  "Reply-Message",
+  "Vendor-Specific",
+];
+
+class RadiusAttributeTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+    };
+  }
+
+  updateTable(table) {
+    this.props.onUpdateTable(table);
+  }
+
+  updateField(table, index, key, value) {
+    table[index][key] = value;
+    this.updateTable(table);
+  }
+
+  addRow(table) {
+    const row = {name: "Tunnel-Private-Group-Id", vendorId: 0, vendorType: 0, valueType: "string", value: ""};
+    if (table === undefined) {
+      table = [];
+    }
+    table = Setting.addRow(table, row);
+    this.updateTable(table);
+  }
+
+  deleteRow(table, i) {
+    table = Setting.deleteRow(table, i);
+    this.updateTable(table);
+  }
+
+  upRow(table, i) {
+    table = Setting.swapRow(table, i - 1, i);
+    this.updateTable(table);
+  }
+
+  downRow(table, i) {
+    table = Setting.swapRow(table, i, i + 1);
+    this.updateTable(table);
+  }
+
+  renderTable(table) {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "name",
+        key: "name",
+        width: "250px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: "100%"}} value={text} onChange={value => {
+              this.updateField(table, index, "name", value);
+            }}>
+              {
+                attributeNames.map((name, index) => <Option key={index} value={name}>{name}</Option>)
+              }
+            </Select>
+          );
+        },
+      },
+      {
+        title: i18next.t("radiusPolicy:Vendor ID"),
+        dataIndex: "vendorId",
+        key: "vendorId",
+        width: "120px",
+        render: (text, record, index) => {
+          return (
+            <InputNumber disabled={record.name !== "Vendor-Specific"} min={0} value={text} onChange={value => {
+              this.updateField(table, index, "vendorId", value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("radiusPolicy:Vendor type"),
+        dataIndex: "vendorType",
+        key: "vendorType",
+        width: "120px",
+        render: (text, record, index) => {
+          return (
+            <InputNumber disabled={record.name !== "Vendor-Specific"} min={0} max={255} value={text} onChange={value => {
+              this.updateField(table, index, "vendorType", value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("radiusPolicy:Value type"),
+        dataIndex: "valueType",
+        key: "valueType",
+        width: "120px",
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: "100%"}} disabled={record.name !== "Vendor-Specific"} value={text} onChange={value => {
+              this.updateField(table, index, "valueType", value);
+            }}>
+              {
+                ["string", "integer"].map((valueType, index) => <Option key={index} value={valueType}>{valueType}</Option>)
+              }
+            </Select>
+          );
+        },
+      },
+      {
+        title: i18next.t("webhook:Value"),
+        dataIndex: "value",
+        key: "value",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} onChange={e => {
+              this.updateField(table, index, "value", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "action",
+        width: "100px",
+        render: (text, record, index) => {
+          return (
+            <div>
+              <Tooltip placement="bottomLeft" title={i18next.t("general:Up")}>
+                <Button style={{marginRight: "5px"}} disabled={index === 0} icon={<UpOutlined />} size="small" onClick={() => this.upRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Down")}>
+                <Button style={{marginRight: "5px"}} disabled={index === table.length - 1} icon={<DownOutlined />} size="small" onClick={() => this.downRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Delete")}>
+                <Button icon={<DeleteOutlined />} size="small" onClick={() => this.deleteRow(table, index)} />
+              </Tooltip>
+            </div>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="index" columns={columns} dataSource={table} size="middle" bordered pagination={false}
+        title={() => (
+          <div>
+            {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.addRow(table)}>{i18next.t("general:Add")}</Button>
+          </div>
+        )}
+      />
+    );
+  }
+
+  render() {
+    return (
+      <div>
+        <Row style={{marginTop: "20px"}} >
+          <Col span={24}>
+            {
+              this.renderTable(this.props.table)
+            }
+          </Col>
+        </Row>
+      </div>
+    );
+  }
+}
+
+export default RadiusAttributeTable;
@@ -0,0 +1,128 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {DeleteOutlined} from "@ant-design/icons";
+import {Button, Col, Input, Row, Table, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+// the user mapping is stored as a map from the user fields to the SCIM attributes, it is
+// edited as rows so that the rows with the same or empty fields are kept while typing
+class ScimUserMappingTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      table: Object.entries(props.userMapping ?? {}).map(([field, attribute]) => ({field: field, attribute: attribute})),
+    };
+  }
+
+  updateTable(table) {
+    this.setState({table: table});
+
+    const userMapping = {};
+    table.forEach(row => {
+      if (row.field !== "" && row.attribute !== "") {
+        userMapping[row.field] = row.attribute;
+      }
+    });
+    this.props.onUpdateUserMapping(userMapping);
+  }
+
+  updateField(table, index, key, value) {
+    table[index][key] = value;
+    this.updateTable(table);
+  }
+
+  addRow(table) {
+    const row = {field: "", attribute: ""};
+    table = Setting.addRow(table, row);
+    this.updateTable(table);
+  }
+
+  deleteRow(table, i) {
+    table = Setting.deleteRow(table, i);
+    this.updateTable(table);
+  }
+
+  renderTable(table) {
+    const columns = [
+      {
+        title: i18next.t("provider:User field"),
+        dataIndex: "field",
+        key: "field",
+        width: "250px",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} placeholder="email" onChange={e => {
+              this.updateField(table, index, "field", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("provider:SCIM attribute"),
+        dataIndex: "attribute",
+        key: "attribute",
+        render: (text, record, index) => {
+          return (
+            <Input value={text} placeholder="emails.value" onChange={e => {
+              this.updateField(table, index, "attribute", e.target.value);
+            }} />
+          );
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "action",
+        width: "60px",
+        render: (text, record, index) => {
+          return (
+            <Tooltip placement="topLeft" title={i18next.t("general:Delete")}>
+              <Button icon={<DeleteOutlined />} size="small" onClick={() => this.deleteRow(table, index)} />
+            </Tooltip>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="index" columns={columns} dataSource={table} size="middle" bordered pagination={false}
+        title={() => (
+          <div>
+            {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.addRow(table)}>{i18next.t("general:Add")}</Button>
+          </div>
+        )}
+      />
+    );
+  }
+
+  render() {
+    return (
+      <div>
+        <Row style={{marginTop: "20px"}} >
+          <Col span={24}>
+            {
+              this.renderTable(this.state.table)
+            }
+          </Col>
+        </Row>
+      </div>
+    );
+  }
+}
+
+export default ScimUserMappingTable;
@@ -0,0 +1,159 @@
+// Copyright 2023 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Table, Tag, Tooltip} from "antd";
+import * as Setting from "../Setting";
+import * as WebhookBackend from "../backend/WebhookBackend";
+import i18next from "i18next";
+
+class WebhookDeliveryTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      deliveries: [],
+      pagination: {
+        current: 1,
+        pageSize: 10,
+      },
+      loading: false,
+    };
+  }
+
+  componentDidMount() {
+    this.fetch(this.state.pagination);
+  }
+
+  fetch(pagination) {
+    this.setState({loading: true});
+    WebhookBackend.getWebhookDeliveries(this.props.owner, this.props.webhook, pagination.current, pagination.pageSize)
+      .then((res) => {
+        this.setState({loading: false});
+        if (res.status === "ok") {
+          this.setState({
+            deliveries: res.data,
+            pagination: {
+              ...pagination,
+              total: res.data2,
+            },
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  redeliver(delivery) {
+    WebhookBackend.redeliverWebhookDelivery(delivery)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("webhook:Redelivered"));
+          this.fetch(this.state.pagination);
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to connect to server")}: ${error}`);
+      });
+  }
+
+  getStateColor(state) {
+    if (state === "Succeeded") {
+      return "success";
+    } else if (state === "Failed") {
+      return "error";
+    } else {
+      return "processing";
+    }
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: "name",
+        key: "name",
+        width: "200px",
+      },
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "180px",
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        },
+      },
+      {
+        title: i18next.t("webhook:Event"),
+        dataIndex: "event",
+        key: "event",
+        width: "150px",
+      },
+      {
+        title: i18next.t("general:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "110px",
+        render: (text, record, index) => {
+          return (
+            <Tooltip title={record.error}>
+              <Tag color={this.getStateColor(text)}>{text}</Tag>
+            </Tooltip>
+          );
+        },
+      },
+      {
+        title: i18next.t("webhook:Attempts"),
+        dataIndex: "attempts",
+        key: "attempts",
+        width: "90px",
+      },
+      {
+        title: i18next.t("webhook:Status code"),
+        dataIndex: "statusCode",
+        key: "statusCode",
+        width: "110px",
+      },
+      {
+        title: i18next.t("webhook:Response"),
+        dataIndex: "response",
+        key: "response",
+        ellipsis: true,
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: "op",
+        width: "120px",
+        render: (text, record, index) => {
+          return (
+            <Button size="small" onClick={() => this.redeliver(record)}>{i18next.t("webhook:Redeliver")}</Button>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table scroll={{x: "max-content"}} rowKey="name" columns={columns} dataSource={this.state.deliveries} size="middle" bordered
+        pagination={this.state.pagination} loading={this.state.loading}
+        title={() => this.props.title}
+        onChange={(pagination) => this.fetch(pagination)}
+      />
+    );
+  }
+}
+
+export default WebhookDeliveryTable;